@@ -2,24 +2,34 @@ package app
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 
 	"github.com/mike-keough/pipelinepal/internal/db"
 	"github.com/mike-keough/pipelinepal/internal/tui"
 )
 
+// DBFile is the database file name inside the data dir.
+const DBFile = "pipelinepal.sqlite"
+
 type App struct {
-	DB   *db.DB
-	Repo *db.Repo
+	DB      *db.DB
+	Repo    *db.Repo
+	DataDir string
 }
 
 func New(dbPath string) (*App, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return nil, err
+	}
 	d, err := db.Open(dbPath)
 	if err != nil {
 		return nil, err
 	}
 	return &App{
-		DB:   d,
-		Repo: db.NewRepo(d),
+		DB:      d,
+		Repo:    db.NewRepo(d),
+		DataDir: filepath.Dir(dbPath),
 	}, nil
 }
 
@@ -33,5 +43,18 @@ func (a *App) Bootstrap(ctx context.Context) error {
 }
 
 func (a *App) Model() tui.Model {
-	return tui.New(a.Repo)
+	return tui.New(a.Repo, a.DataDir)
+}
+
+// DefaultDataDir is where the database lives unless overridden.
+func DefaultDataDir() string {
+	// Simple + predictable: ./data if present, else user home
+	if _, err := os.Stat("data"); err == nil {
+		return "data"
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "data"
+	}
+	return filepath.Join(home, ".pipelinepal")
 }
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cadenceSteps      []string
+	cadenceAutoSource string
+	cadenceAutoType   string
+	cadenceName       string
+	cadenceStart      string
+)
+
+var cadenceCmd = &cobra.Command{
+	Use:   "cadence",
+	Short: "Follow-up cadences (reusable task sequences applied to leads)",
+	Long: "A cadence is a named list of follow-up tasks, each due some days after the cadence is applied.\n" +
+		"Steps are DAY:TYPE:TITLE or DAY:TITLE; types are " + strings.Join(db.TaskTypes, ", ") + ".\n\n" +
+		"  pipelinepal cadence add \"Zillow buyer\" --auto-source Zillow --auto-type buyer \\\n" +
+		"      --step \"0:call:Intro call\" --step \"1:text:Check in by text\" --step \"3:email:Send listings\" \\\n" +
+		"      --step \"7:call:Follow-up call\"\n" +
+		"  pipelinepal cadence apply \"Zillow buyer\" 42\n\n" +
+		"With --auto-source and/or --auto-type, the cadence is applied to every new lead that matches\n" +
+		"(from the TUI, `lead add` or an import).",
+}
+
+var cadenceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cadences and their steps",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		all, err := a.Repo.ListCadences(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(all) == 0 {
+			fmt.Println("No cadences yet.")
+			return nil
+		}
+		for _, c := range all {
+			printCadence(c)
+		}
+		return nil
+	},
+}
+
+var cadenceAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a cadence",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, err := parseCadenceSteps()
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		c := db.Cadence{Name: args[0], AutoSource: cadenceAutoSource, AutoType: cadenceAutoType, Steps: steps}
+		if _, err := a.Repo.CreateCadence(cmd.Context(), c); err != nil {
+			return err
+		}
+		if c, err = a.Repo.FindCadence(cmd.Context(), c.Name); err != nil {
+			return err
+		}
+		fmt.Print("✅ Added ")
+		printCadence(c)
+		return nil
+	},
+}
+
+var cadenceEditCmd = &cobra.Command{
+	Use:   "edit <cadence>",
+	Short: "Edit a cadence; only the flags given are changed (--step replaces all steps)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		c, err := a.Repo.FindCadence(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		f := cmd.Flags()
+		if f.Changed("name") {
+			c.Name = cadenceName
+		}
+		if f.Changed("auto-source") {
+			c.AutoSource = cadenceAutoSource
+		}
+		if f.Changed("auto-type") {
+			c.AutoType = cadenceAutoType
+		}
+		if f.Changed("step") {
+			if c.Steps, err = parseCadenceSteps(); err != nil {
+				return err
+			}
+		}
+		if err := a.Repo.UpdateCadence(cmd.Context(), c); err != nil {
+			return err
+		}
+		if c, err = a.Repo.FindCadence(cmd.Context(), fmt.Sprint(c.ID)); err != nil {
+			return err
+		}
+		fmt.Print("✅ Updated ")
+		printCadence(c)
+		return nil
+	},
+}
+
+var cadenceDeleteCmd = &cobra.Command{
+	Use:   "delete <cadence>",
+	Short: "Delete a cadence (tasks it created are kept)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		c, err := a.Repo.FindCadence(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.DeleteCadence(cmd.Context(), c.ID); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted cadence %s\n", c.Name)
+		return nil
+	},
+}
+
+var cadenceApplyCmd = &cobra.Command{
+	Use:   "apply <cadence> <lead-id>",
+	Short: "Create a cadence's tasks on a lead",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		leadID, err := parseID(args[1])
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		if cadenceStart != "" {
+			if start, err = time.Parse("2006-01-02", cadenceStart); err != nil {
+				return fmt.Errorf("bad --start date (use YYYY-MM-DD): %w", err)
+			}
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		c, err := a.Repo.FindCadence(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		l, err := a.Repo.GetLead(cmd.Context(), leadID)
+		if err != nil {
+			return fmt.Errorf("lead #%d not found", leadID)
+		}
+		n, err := a.Repo.ApplyCadence(cmd.Context(), c, l.ID, start)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Applied cadence %s to #%d %s: %d task(s) from %s\n", c.Name, l.ID, l.FullName, n, start.Format("2006-01-02"))
+		return nil
+	},
+}
+
+func parseCadenceSteps() ([]db.CadenceStep, error) {
+	steps := make([]db.CadenceStep, 0, len(cadenceSteps))
+	for _, s := range cadenceSteps {
+		step, err := db.ParseCadenceStep(s)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func printCadence(c db.Cadence) {
+	var auto []string
+	if c.AutoSource != "" {
+		auto = append(auto, "source "+c.AutoSource)
+	}
+	if c.AutoType != "" {
+		auto = append(auto, "type "+c.AutoType)
+	}
+	rule := ""
+	if len(auto) > 0 {
+		rule = " • auto: " + strings.Join(auto, " + ")
+	}
+	fmt.Printf("#%d %s (%d step(s))%s\n", c.ID, c.Name, len(c.Steps), rule)
+	for _, s := range c.Steps {
+		fmt.Printf("    day %-3d %-8s %s\n", s.Day, emptyDash(s.Type), s.Title)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(cadenceCmd)
+	cadenceCmd.AddCommand(cadenceListCmd)
+	cadenceCmd.AddCommand(cadenceAddCmd)
+	cadenceCmd.AddCommand(cadenceEditCmd)
+	cadenceCmd.AddCommand(cadenceDeleteCmd)
+	cadenceCmd.AddCommand(cadenceApplyCmd)
+
+	for _, c := range []*cobra.Command{cadenceAddCmd, cadenceEditCmd} {
+		c.Flags().StringArrayVar(&cadenceSteps, "step", nil, "a task: DAY:TYPE:TITLE or DAY:TITLE (repeatable, in any order)")
+		c.Flags().StringVar(&cadenceAutoSource, "auto-source", "", "apply to new leads from this source")
+		c.Flags().StringVar(&cadenceAutoType, "auto-type", "", "apply to new leads of this type: "+strings.Join(db.LeadTypes, "|"))
+	}
+	cadenceEditCmd.Flags().StringVar(&cadenceName, "name", "", "rename the cadence")
+	cadenceApplyCmd.Flags().StringVar(&cadenceStart, "start", "", "day 0 of the cadence, YYYY-MM-DD (default today)")
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show settings, or change one with `config set`",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		keys := make([]string, 0, len(db.SettingKeys))
+		for k := range db.SettingKeys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v, err := a.Repo.GetSetting(cmd.Context(), k, "")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%-14s %-6s %s\n", strings.ReplaceAll(k, "_", "-"), emptyDash(v), db.SettingKeys[k])
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting (e.g. config set phone-region GB)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		key := strings.ReplaceAll(strings.ToLower(args[0]), "-", "_")
+		if err := a.Repo.SetSetting(cmd.Context(), key, args[1]); err != nil {
+			return err
+		}
+		v, err := a.Repo.GetSetting(cmd.Context(), key, "")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s = %s\n", args[0], v)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSetCmd)
+}
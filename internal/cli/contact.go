@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	contactLabel   string
+	contactPrimary bool
+	unlinkKind     string
+)
+
+var leadContactCmd = &cobra.Command{
+	Use:   "contact",
+	Short: "Manage a lead's phone numbers and email addresses",
+}
+
+var leadContactListCmd = &cobra.Command{
+	Use:   "list <lead-id>",
+	Short: "List a lead's phones, emails and relationships",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		l, err := a.Repo.GetLead(cmd.Context(), id)
+		if err != nil {
+			return fmt.Errorf("lead #%d: %w", id, err)
+		}
+		contacts, err := a.Repo.ListLeadContacts(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		rels, err := a.Repo.ListLeadRelationships(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("#%d %s\n", l.ID, l.FullName)
+		if len(contacts) == 0 {
+			fmt.Println("  No phones or emails.")
+		}
+		for _, c := range contacts {
+			printContact(c)
+		}
+		for _, r := range rels {
+			fmt.Printf("  %-12s #%d %s\n", r.Label(), r.RelatedID, r.RelatedName)
+		}
+		return nil
+	},
+}
+
+var leadContactAddCmd = &cobra.Command{
+	Use:   "add <lead-id> phone|email <value>",
+	Short: "Add a phone or email to a lead",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		cid, err := a.Repo.AddContact(cmd.Context(), db.ContactMethod{
+			LeadID:  id,
+			Kind:    args[1],
+			Label:   contactLabel,
+			Value:   args[2],
+			Primary: contactPrimary,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Added contact #%d to lead #%d\n", cid, id)
+		return nil
+	},
+}
+
+var leadContactPrimaryCmd = &cobra.Command{
+	Use:   "primary <contact-id>",
+	Short: "Make a phone or email the lead's primary one",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.SetPrimaryContact(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Contact #%d is now primary\n", id)
+		return nil
+	},
+}
+
+var leadContactDeleteCmd = &cobra.Command{
+	Use:   "delete <contact-id>",
+	Short: "Remove a phone or email",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.DeleteContact(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted contact #%d\n", id)
+		return nil
+	},
+}
+
+var leadLinkCmd = &cobra.Command{
+	Use:   "link <lead-id> " + strings.Join(db.RelationshipKinds, "|") + " <other-lead-id>",
+	Short: "Link two leads, read as \"lead's <kind> is other\" (e.g. link 5 referred-by 2)",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		other, err := parseID(args[2])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.LinkLeads(cmd.Context(), id, args[1], other); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Lead #%d %s #%d\n", id, strings.ToLower(args[1]), other)
+		return nil
+	},
+}
+
+var leadUnlinkCmd = &cobra.Command{
+	Use:   "unlink <lead-id> <other-lead-id>",
+	Short: "Remove the links between two leads",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		other, err := parseID(args[1])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.UnlinkLeads(cmd.Context(), id, other, unlinkKind); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Unlinked lead #%d and #%d\n", id, other)
+		return nil
+	},
+}
+
+func printContact(c db.ContactMethod) {
+	primary := ""
+	if c.Primary {
+		primary = " (primary)"
+	}
+	fmt.Printf("  #%-4d %-6s %-10s %s%s\n", c.ID, c.Kind, emptyDash(c.Label), c.Value, primary)
+}
+
+func init() {
+	leadCmd.AddCommand(leadContactCmd)
+	leadContactCmd.AddCommand(leadContactListCmd)
+	leadContactCmd.AddCommand(leadContactAddCmd)
+	leadContactCmd.AddCommand(leadContactPrimaryCmd)
+	leadContactCmd.AddCommand(leadContactDeleteCmd)
+	leadCmd.AddCommand(leadLinkCmd)
+	leadCmd.AddCommand(leadUnlinkCmd)
+
+	leadContactAddCmd.Flags().StringVar(&contactLabel, "label", "", "label such as cell, work or home")
+	leadContactAddCmd.Flags().BoolVar(&contactPrimary, "primary", false, "make this the lead's primary phone/email")
+	leadUnlinkCmd.Flags().StringVar(&unlinkKind, "kind", "", "only remove links of this kind")
+}
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dealPrice    string
+	dealRate     float64
+	dealSide     string
+	dealStatus   string
+	dealContract string
+	dealClose    string
+	dealClosed   string
+	dealProperty int64
+	dealReferral float64
+	dealPlan     string
+)
+
+var dealCmd = &cobra.Command{
+	Use:   "deal",
+	Short: "Track deals: price, commission and closing dates",
+}
+
+var dealListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deals, soonest expected close first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		deals, err := a.Repo.ListDeals(cmd.Context(), strings.ToLower(dealStatus))
+		if err != nil {
+			return err
+		}
+		if len(deals) == 0 {
+			fmt.Println("No deals found.")
+			return nil
+		}
+
+		var gci float64
+		for _, d := range deals {
+			printDeal(d)
+			gci += d.GrossCommission()
+		}
+		fmt.Printf("\n%d deal(s), GCI %s\n", len(deals), db.FormatMoney(gci))
+		return nil
+	},
+}
+
+var dealAddCmd = &cobra.Command{
+	Use:   "add <lead-id>",
+	Short: "Create a deal for a lead",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		leadID, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		d := db.Deal{
+			LeadID:         leadID,
+			PropertyID:     dealProperty,
+			CommissionRate: dealRate,
+			ReferralPct:    dealReferral,
+			Side:           dealSide,
+			Status:         dealStatus,
+		}
+		if err := applyDealFlags(cmd, a.Repo, &d); err != nil {
+			return err
+		}
+
+		id, err := a.Repo.CreateDeal(cmd.Context(), d)
+		if err != nil {
+			return err
+		}
+		if d, err = a.Repo.GetDeal(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Print("✅ Added ")
+		printDeal(d)
+		return nil
+	},
+}
+
+var dealEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a deal; only the flags given are changed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		d, err := a.Repo.GetDeal(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		f := cmd.Flags()
+		if f.Changed("rate") {
+			d.CommissionRate = dealRate
+		}
+		if f.Changed("side") {
+			d.Side = dealSide
+		}
+		if f.Changed("status") {
+			d.Status = dealStatus
+			if d.Status != "closed" {
+				d.ClosedDate = nil
+			}
+		}
+		if f.Changed("property") {
+			d.PropertyID = dealProperty
+		}
+		if f.Changed("referral") {
+			d.ReferralPct = dealReferral
+		}
+		if err := applyDealFlags(cmd, a.Repo, &d); err != nil {
+			return err
+		}
+
+		if err := a.Repo.UpdateDeal(cmd.Context(), d); err != nil {
+			return err
+		}
+		if d, err = a.Repo.GetDeal(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Print("✅ Updated ")
+		printDeal(d)
+		return nil
+	},
+}
+
+var dealDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a deal",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.DeleteDeal(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted deal #%d\n", id)
+		return nil
+	},
+}
+
+// applyDealFlags copies the price, plan and date flags that were given onto d.
+func applyDealFlags(cmd *cobra.Command, r *db.Repo, d *db.Deal) error {
+	f := cmd.Flags()
+	var err error
+	if f.Changed("price") {
+		if d.Price, err = db.ParseAmount(dealPrice); err != nil {
+			return fmt.Errorf("--price: %w", err)
+		}
+	}
+	if f.Changed("plan") {
+		d.SplitPlanID = 0
+		if dealPlan != "" {
+			p, err := r.FindSplitPlan(cmd.Context(), dealPlan)
+			if err != nil {
+				return err
+			}
+			d.SplitPlanID = p.ID
+		}
+	}
+	dates := []struct {
+		flag string
+		val  string
+		dst  **time.Time
+	}{
+		{"contract", dealContract, &d.ContractDate},
+		{"close", dealClose, &d.ExpectedClose},
+		{"closed", dealClosed, &d.ClosedDate},
+	}
+	for _, dt := range dates {
+		if !f.Changed(dt.flag) {
+			continue
+		}
+		if *dt.dst, err = parseFollowUp(dt.val); err != nil {
+			return fmt.Errorf("--%s: %w", dt.flag, err)
+		}
+	}
+	return nil
+}
+
+func printDeal(d db.Deal) {
+	closeOn := "close:" + fmtDate(d.ExpectedClose)
+	if d.ClosedDate != nil {
+		closeOn = "closed:" + fmtDate(d.ClosedDate)
+	}
+	fmt.Printf("#%d %-12s lead #%d %-20s %-8s %12s @ %.2f%% GCI %-10s %s\n",
+		d.ID, d.Status, d.LeadID, d.LeadName, d.Side, db.FormatMoney(d.Price), d.CommissionRate,
+		db.FormatMoney(d.GrossCommission()), closeOn)
+}
+
+func fmtDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+func init() {
+	rootCmd.AddCommand(dealCmd)
+	dealCmd.AddCommand(dealListCmd)
+	dealCmd.AddCommand(dealAddCmd)
+	dealCmd.AddCommand(dealEditCmd)
+	dealCmd.AddCommand(dealDeleteCmd)
+
+	dealListCmd.Flags().StringVar(&dealStatus, "status", "", "filter by status: "+strings.Join(db.DealStatuses, "|"))
+
+	for _, c := range []*cobra.Command{dealAddCmd, dealEditCmd} {
+		c.Flags().StringVar(&dealPrice, "price", "", "sale price (e.g. 450000, $450k)")
+		c.Flags().Float64Var(&dealRate, "rate", 3, "commission rate in percent")
+		c.Flags().StringVar(&dealSide, "side", "buyer", strings.Join(db.DealSides, "|"))
+		c.Flags().StringVar(&dealContract, "contract", "", "contract date (YYYY-MM-DD)")
+		c.Flags().StringVar(&dealClose, "close", "", "expected closing date (YYYY-MM-DD)")
+		c.Flags().StringVar(&dealClosed, "closed", "", "actual closing date (YYYY-MM-DD)")
+		c.Flags().Int64Var(&dealProperty, "property", 0, "property id the deal is for (0 = none)")
+		c.Flags().Float64Var(&dealReferral, "referral", 0, "referral fee in percent of GCI")
+		c.Flags().StringVar(&dealPlan, "plan", "", "split plan name (empty = default plan)")
+	}
+	dealAddCmd.Flags().StringVar(&dealStatus, "status", "open", strings.Join(db.DealStatuses, "|"))
+	dealEditCmd.Flags().StringVar(&dealStatus, "status", "", strings.Join(db.DealStatuses, "|"))
+}
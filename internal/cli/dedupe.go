@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Report leads that look like the same person (phone, email or name)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		pairs, err := a.Repo.DuplicateReport(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(pairs) == 0 {
+			fmt.Println("No duplicates found.")
+			return nil
+		}
+		for _, p := range pairs {
+			fmt.Printf("same %s:\n  ", strings.Join(p.Reasons, ", "))
+			printLead(p.A)
+			fmt.Print("  ")
+			printLead(p.B)
+		}
+		fmt.Printf("\n%d possible duplicate(s). Fold one into another with `pipelinepal lead merge <keep-id> <drop-id>`.\n", len(pairs))
+		return nil
+	},
+}
+
+var leadMergeCmd = &cobra.Command{
+	Use:   "merge <keep-id> <drop-id>",
+	Short: "Fold one lead's notes, tasks, tags and history into another, then delete it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keep, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		drop, err := parseID(args[1])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.MergeLeads(cmd.Context(), keep, drop); err != nil {
+			return err
+		}
+		l, err := a.Repo.GetLead(cmd.Context(), keep)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Merged #%d into ", drop)
+		printLead(l)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+	leadCmd.AddCommand(leadMergeCmd)
+}
@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/mike-keough/pipelinepal/internal/ical"
+	"github.com/mike-keough/pipelinepal/internal/vcard"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportOut      string
+	exportStage    string
+	exportType     string
+	exportSource   string
+	exportArchived bool
+	exportVCardVer string
+	exportEvents   bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [leads|notes|tasks|vcf|ics]",
+	Short: "Export leads, notes or tasks as CSV or JSON, leads as vCards or tasks as a calendar",
+	Long: "Writes to stdout, or to --out (the format then defaults to the file's extension).\n\n" +
+		"CSV lead exports have one column per custom field and read back in with `pipelinepal import csv`.\n" +
+		"JSON lead exports nest each lead's contacts, custom fields, notes, tasks, deals, properties and\n" +
+		"relationships. `export vcf` (or --format vcf) writes leads as vCards for phones and address books;\n" +
+		"`export ics` writes open tasks as an iCalendar file (see also `pipelinepal serve ics`).\n\n" +
+		"  pipelinepal export --out leads.csv\n" +
+		"  pipelinepal export leads --format json --type seller --stage \"Listed\" > listings.json\n" +
+		"  pipelinepal export tasks --source Zillow --out zillow-tasks.csv\n" +
+		"  pipelinepal export vcf --type buyer --out buyers.vcf\n" +
+		"  pipelinepal export ics --events --out follow-ups.ics",
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"leads", "notes", "tasks", "vcf", "ics"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		what := "leads"
+		if len(args) == 1 {
+			what = strings.ToLower(args[0])
+		}
+		format := strings.ToLower(exportFormat)
+		switch what {
+		case "vcf":
+			what, format = "leads", "vcf"
+		case "ics":
+			what, format = "tasks", "ics"
+		}
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(exportOut)), ".")
+		}
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "json" && format != "vcf" && format != "ics" {
+			return fmt.Errorf("unknown format %q (use csv|json|vcf|ics)", format)
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		out := io.Writer(os.Stdout)
+		var file *os.File
+		if exportOut != "" {
+			if file, err = os.Create(exportOut); err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+		w := bufio.NewWriter(out)
+
+		f := db.ExportFilter{Stage: exportStage, Type: exportType, Source: exportSource, Archived: exportArchived}
+		ex := exporter{cmd: cmd, r: a.Repo, f: f, w: w}
+		var n int
+		switch what + "/" + format {
+		case "leads/csv":
+			n, err = ex.leadsCSV()
+		case "leads/json":
+			n, err = ex.leadsJSON()
+		case "notes/csv":
+			n, err = ex.notesCSV()
+		case "notes/json":
+			n, err = ex.notesJSON()
+		case "tasks/csv":
+			n, err = ex.tasksCSV()
+		case "tasks/json":
+			n, err = ex.tasksJSON()
+		case "leads/vcf":
+			n, err = ex.leadsVCF(exportVCardVer)
+		case "tasks/ics":
+			n, err = writeTaskCalendar(cmd.Context(), a.Repo, f, exportEvents, w)
+		case "notes/vcf", "tasks/vcf":
+			return fmt.Errorf("only leads export as vCards")
+		case "leads/ics", "notes/ics":
+			return fmt.Errorf("only tasks export as a calendar")
+		default:
+			return fmt.Errorf("unknown export %q (use leads|notes|tasks)", what)
+		}
+		if err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if file != nil {
+			if err := file.Close(); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Exported %d %s(s) to %s\n", n, strings.TrimSuffix(what, "s"), exportOut)
+		}
+		return nil
+	},
+}
+
+// exporter streams one export to w as rows come out of the database.
+type exporter struct {
+	cmd *cobra.Command
+	r   *db.Repo
+	f   db.ExportFilter
+	w   io.Writer
+}
+
+func (ex exporter) leadsCSV() (int, error) {
+	fields, err := ex.r.ListCustomFields(ex.cmd.Context())
+	if err != nil {
+		return 0, err
+	}
+	cw := csv.NewWriter(ex.w)
+	header := []string{"id", "name", "phone", "email", "type", "pipeline", "stage", "status", "source",
+		"follow_up", "last_contacted", "created_at", "updated_at", "tags", "other_phones", "other_emails", "notes"}
+	for _, fd := range fields {
+		header = append(header, fd.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err = ex.r.ExportLeads(ex.cmd.Context(), ex.f, false, func(e db.ExportLead) error {
+		var phones, emails []string
+		for _, c := range e.Contacts {
+			switch {
+			case c.Primary:
+			case c.Kind == "phone":
+				phones = append(phones, c.Value)
+			default:
+				emails = append(emails, c.Value)
+			}
+		}
+		values := map[int64]string{}
+		for _, fv := range e.Fields {
+			values[fv.Field.ID] = fv.Value
+		}
+
+		l := e.Lead
+		rec := []string{strconv.FormatInt(l.ID, 10), l.FullName, l.Phone, l.Email, l.LeadType,
+			l.PipelineName, l.StageName, l.Status, l.Source,
+			exportDate(l.NextFollowUp), exportTime(l.LastContacted), exportTime(&l.CreatedAt), exportTime(&l.UpdatedAt),
+			strings.Join(l.Tags, ", "), strings.Join(phones, ", "), strings.Join(emails, ", "), l.Notes}
+		for _, fd := range fields {
+			rec = append(rec, values[fd.ID])
+		}
+		n++
+		return cw.Write(rec)
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	return n, err
+}
+
+func (ex exporter) notesCSV() (int, error) {
+	cw := csv.NewWriter(ex.w)
+	if err := cw.Write([]string{"id", "lead_id", "lead_name", "created_at", "body"}); err != nil {
+		return 0, err
+	}
+	n := 0
+	err := ex.r.ExportNotes(ex.cmd.Context(), ex.f, func(note db.Note, leadName string) error {
+		n++
+		return cw.Write([]string{strconv.FormatInt(note.ID, 10), strconv.FormatInt(note.LeadID, 10), leadName,
+			exportTime(&note.CreatedAt), note.Body})
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	return n, err
+}
+
+func (ex exporter) tasksCSV() (int, error) {
+	cw := csv.NewWriter(ex.w)
+	if err := cw.Write([]string{"id", "lead_id", "lead_name", "title", "due", "due_time", "type", "priority", "status", "repeat", "created_at", "completed_at"}); err != nil {
+		return 0, err
+	}
+	n := 0
+	err := ex.r.ExportTasks(ex.cmd.Context(), ex.f, func(t db.Task) error {
+		n++
+		return cw.Write([]string{strconv.FormatInt(t.ID, 10), strconv.FormatInt(t.LeadID, 10), t.LeadName,
+			t.Title, exportDate(t.DueDate), t.DueTime, t.Type, db.PriorityName(t.Priority), t.Status, t.Recurrence,
+			exportTime(&t.CreatedAt), exportTime(t.CompletedAt)})
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	return n, err
+}
+
+// JSON shapes. Dates are YYYY-MM-DD and timestamps RFC 3339; empty
+// optional values are left out.
+
+type jsonLead struct {
+	ID            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Phone         string            `json:"phone,omitempty"`
+	Email         string            `json:"email,omitempty"`
+	Type          string            `json:"type"`
+	Pipeline      string            `json:"pipeline"`
+	Stage         string            `json:"stage"`
+	Status        string            `json:"status"`
+	Source        string            `json:"source,omitempty"`
+	FollowUp      string            `json:"follow_up,omitempty"`
+	LastContacted string            `json:"last_contacted,omitempty"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+	ArchivedAt    string            `json:"archived_at,omitempty"`
+	Background    string            `json:"background,omitempty"`
+	Tags          []string          `json:"tags"`
+	Fields        map[string]string `json:"fields"`
+	Contacts      []jsonContact     `json:"contacts"`
+	Notes         []jsonNote        `json:"notes"`
+	Tasks         []jsonTask        `json:"tasks"`
+	Deals         []jsonDeal        `json:"deals"`
+	Properties    []jsonProperty    `json:"properties"`
+	Relationships []jsonRelation    `json:"relationships"`
+}
+
+type jsonContact struct {
+	Kind    string `json:"kind"`
+	Label   string `json:"label,omitempty"`
+	Value   string `json:"value"`
+	Primary bool   `json:"primary"`
+}
+
+type jsonNote struct {
+	ID        int64  `json:"id"`
+	LeadID    int64  `json:"lead_id,omitempty"`
+	LeadName  string `json:"lead_name,omitempty"`
+	CreatedAt string `json:"created_at"`
+	Body      string `json:"body"`
+}
+
+type jsonTask struct {
+	ID          int64  `json:"id"`
+	LeadID      int64  `json:"lead_id,omitempty"`
+	LeadName    string `json:"lead_name,omitempty"`
+	Title       string `json:"title"`
+	Due         string `json:"due,omitempty"`
+	DueTime     string `json:"due_time,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Priority    string `json:"priority"`
+	Status      string `json:"status"`
+	Repeat      string `json:"repeat,omitempty"` // RRULE
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+type jsonDeal struct {
+	ID             int64   `json:"id"`
+	Side           string  `json:"side"`
+	Status         string  `json:"status"`
+	Price          float64 `json:"price"`
+	CommissionRate float64 `json:"commission_rate"`
+	ReferralPct    float64 `json:"referral_pct,omitempty"`
+	SplitPlan      string  `json:"split_plan,omitempty"`
+	Property       string  `json:"property,omitempty"`
+	ContractDate   string  `json:"contract_date,omitempty"`
+	ExpectedClose  string  `json:"expected_close,omitempty"`
+	ClosedDate     string  `json:"closed_date,omitempty"`
+}
+
+type jsonProperty struct {
+	ID        int64   `json:"id"`
+	Role      string  `json:"role"`
+	Address   string  `json:"address"`
+	Status    string  `json:"status"`
+	Price     float64 `json:"price,omitempty"`
+	Beds      int     `json:"beds,omitempty"`
+	Baths     float64 `json:"baths,omitempty"`
+	Sqft      int     `json:"sqft,omitempty"`
+	MLSNumber string  `json:"mls_number,omitempty"`
+}
+
+type jsonRelation struct {
+	Relation string `json:"relation"`
+	LeadID   int64  `json:"lead_id"`
+	Name     string `json:"name"`
+}
+
+func (ex exporter) leadsJSON() (int, error) {
+	arr := jsonArray{w: ex.w}
+	err := ex.r.ExportLeads(ex.cmd.Context(), ex.f, true, func(e db.ExportLead) error {
+		l := e.Lead
+		out := jsonLead{
+			ID: l.ID, Name: l.FullName, Phone: l.Phone, Email: l.Email, Type: l.LeadType,
+			Pipeline: l.PipelineName, Stage: l.StageName, Status: l.Status, Source: l.Source,
+			FollowUp: exportDate(l.NextFollowUp), LastContacted: exportTime(l.LastContacted),
+			CreatedAt: exportTime(&l.CreatedAt), UpdatedAt: exportTime(&l.UpdatedAt), ArchivedAt: exportTime(l.ArchivedAt),
+			Background: l.Notes, Tags: l.Tags, Fields: map[string]string{},
+			Contacts: []jsonContact{}, Notes: []jsonNote{}, Tasks: []jsonTask{}, Deals: []jsonDeal{},
+			Properties: []jsonProperty{}, Relationships: []jsonRelation{},
+		}
+		if out.Tags == nil {
+			out.Tags = []string{}
+		}
+		for _, fv := range e.Fields {
+			out.Fields[fv.Field.Name] = fv.Value
+		}
+		for _, c := range e.Contacts {
+			out.Contacts = append(out.Contacts, jsonContact{Kind: c.Kind, Label: c.Label, Value: c.Value, Primary: c.Primary})
+		}
+		for _, n := range e.Notes {
+			out.Notes = append(out.Notes, jsonNote{ID: n.ID, CreatedAt: exportTime(&n.CreatedAt), Body: n.Body})
+		}
+		for _, t := range e.Tasks {
+			out.Tasks = append(out.Tasks, jsonTask{ID: t.ID, Title: t.Title, Due: exportDate(t.DueDate), DueTime: t.DueTime,
+				Type: t.Type, Priority: db.PriorityName(t.Priority), Status: t.Status, Repeat: t.Recurrence,
+				CreatedAt: exportTime(&t.CreatedAt), CompletedAt: exportTime(t.CompletedAt)})
+		}
+		for _, d := range e.Deals {
+			out.Deals = append(out.Deals, jsonDeal{ID: d.ID, Side: d.Side, Status: d.Status, Price: d.Price,
+				CommissionRate: d.CommissionRate, ReferralPct: d.ReferralPct, SplitPlan: d.SplitPlanName,
+				Property: d.PropertyAddress, ContractDate: exportDate(d.ContractDate),
+				ExpectedClose: exportDate(d.ExpectedClose), ClosedDate: exportDate(d.ClosedDate)})
+		}
+		for _, pl := range e.Properties {
+			p := pl.Property
+			out.Properties = append(out.Properties, jsonProperty{ID: p.ID, Role: pl.Role, Address: p.Address,
+				Status: p.Status, Price: p.Price, Beds: p.Beds, Baths: p.Baths, Sqft: p.Sqft, MLSNumber: p.MLSNumber})
+		}
+		for _, rel := range e.Relationships {
+			out.Relationships = append(out.Relationships, jsonRelation{Relation: rel.Label(), LeadID: rel.RelatedID, Name: rel.RelatedName})
+		}
+		return arr.add(out)
+	})
+	if err != nil {
+		return arr.n, err
+	}
+	return arr.n, arr.close()
+}
+
+func (ex exporter) leadsVCF(version string) (int, error) {
+	if version != "3.0" && version != "4.0" {
+		return 0, fmt.Errorf("unknown vCard version %q (use 3.0|4.0)", version)
+	}
+	n := 0
+	err := ex.r.ExportLeads(ex.cmd.Context(), ex.f, false, func(e db.ExportLead) error {
+		n++
+		return vcard.Write(ex.w, vcard.FromLead(e.Lead, e.Contacts), version)
+	})
+	return n, err
+}
+
+// writeTaskCalendar writes the open tasks on leads matching f as an
+// iCalendar file. It is shared with `serve ics`.
+func writeTaskCalendar(ctx context.Context, r *db.Repo, f db.ExportFilter, events bool, w io.Writer) (int, error) {
+	cw := ical.NewWriter(w, "PipelinePal tasks", events)
+	n := 0
+	err := r.ExportTasks(ctx, f, func(t db.Task) error {
+		if t.Status != "open" || (events && t.DueDate == nil) {
+			return nil
+		}
+		n++
+		return cw.Task(t)
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, cw.Close()
+}
+
+func (ex exporter) notesJSON() (int, error) {
+	arr := jsonArray{w: ex.w}
+	err := ex.r.ExportNotes(ex.cmd.Context(), ex.f, func(n db.Note, leadName string) error {
+		return arr.add(jsonNote{ID: n.ID, LeadID: n.LeadID, LeadName: leadName, CreatedAt: exportTime(&n.CreatedAt), Body: n.Body})
+	})
+	if err != nil {
+		return arr.n, err
+	}
+	return arr.n, arr.close()
+}
+
+func (ex exporter) tasksJSON() (int, error) {
+	arr := jsonArray{w: ex.w}
+	err := ex.r.ExportTasks(ex.cmd.Context(), ex.f, func(t db.Task) error {
+		return arr.add(jsonTask{ID: t.ID, LeadID: t.LeadID, LeadName: t.LeadName, Title: t.Title, Due: exportDate(t.DueDate),
+			DueTime: t.DueTime, Type: t.Type, Priority: db.PriorityName(t.Priority), Status: t.Status, Repeat: t.Recurrence,
+			CreatedAt: exportTime(&t.CreatedAt), CompletedAt: exportTime(t.CompletedAt)})
+	})
+	if err != nil {
+		return arr.n, err
+	}
+	return arr.n, arr.close()
+}
+
+// jsonArray writes a JSON array one element at a time.
+type jsonArray struct {
+	w io.Writer
+	n int
+}
+
+func (a *jsonArray) add(v any) error {
+	b, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if a.n == 0 {
+		sep = "[\n  "
+	}
+	a.n++
+	_, err = fmt.Fprintf(a.w, "%s%s", sep, b)
+	return err
+}
+
+func (a *jsonArray) close() error {
+	end := "\n]\n"
+	if a.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
+}
+
+func exportDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "csv|json|vcf|ics (default: from --out, else csv)")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", "", "write to this file instead of stdout")
+	exportCmd.Flags().StringVar(&exportStage, "stage", "", "only leads in this stage (name, id or pipeline/stage)")
+	exportCmd.Flags().StringVar(&exportType, "type", "", "only leads of this type")
+	exportCmd.Flags().StringVar(&exportSource, "source", "", "only leads from this source")
+	exportCmd.Flags().BoolVar(&exportArchived, "archived", false, "include leads in the trash")
+	exportCmd.Flags().StringVar(&exportVCardVer, "vcard-version", "3.0", "vCard version for vcf exports: 3.0|4.0")
+	exportCmd.Flags().BoolVar(&exportEvents, "events", false, "ics: write all-day events on the due date instead of to-dos")
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fieldKind    string
+	fieldChoices string
+)
+
+var fieldCmd = &cobra.Command{
+	Use:   "field",
+	Short: "Define custom lead fields (pre-approval amount, move-by date, …)",
+	Long: "Custom fields are typed: " + strings.Join(db.FieldKinds, ", ") + ".\n\n" +
+		"  pipelinepal field add \"Pre-approval\" --type money\n" +
+		"  pipelinepal field add District --type choice --choices \"Lincoln,Roosevelt\"\n" +
+		"  pipelinepal lead set 12 Pre-approval=450k District=Lincoln\n\n" +
+		"Filter on them with field:NAME OP VALUE, e.g. 'field:Pre-approval>=400k'.",
+}
+
+var fieldListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List custom field definitions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		fields, err := a.Repo.ListCustomFields(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			fmt.Println("No custom fields defined.")
+			return nil
+		}
+		for _, f := range fields {
+			fmt.Printf("#%d %-24s %-7s %s\n", f.ID, f.Name, f.Kind, strings.Join(f.Choices, " | "))
+		}
+		return nil
+	},
+}
+
+var fieldAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Define a custom field",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		id, err := a.Repo.CreateCustomField(cmd.Context(), args[0], fieldKind, strings.Split(fieldChoices, ","))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Added %s field #%d %s\n", strings.ToLower(fieldKind), id, args[0])
+		return nil
+	},
+}
+
+var fieldDeleteCmd = &cobra.Command{
+	Use:   "delete <name|id>",
+	Short: "Delete a custom field and every lead's value for it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		f, err := a.Repo.FindCustomField(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.DeleteCustomField(cmd.Context(), f.ID); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted field %s\n", f.Name)
+		return nil
+	},
+}
+
+var leadSetCmd = &cobra.Command{
+	Use:   "set <id> <field>=<value>...",
+	Short: "Set custom field values on a lead (an empty value clears)",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		values := make(map[int64]string, len(args)-1)
+		for _, kv := range args[1:] {
+			name, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("%q: expected field=value", kv)
+			}
+			f, err := a.Repo.FindCustomField(cmd.Context(), name)
+			if err != nil {
+				return err
+			}
+			values[f.ID] = v
+		}
+		if err := a.Repo.SetLeadFields(cmd.Context(), id, values); err != nil {
+			return err
+		}
+		return printLeadFields(cmd, a.Repo, id)
+	},
+}
+
+var leadFieldsCmd = &cobra.Command{
+	Use:   "fields <id>",
+	Short: "Show a lead's custom field values",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if _, err := a.Repo.GetLead(cmd.Context(), id); err != nil {
+			return err
+		}
+		return printLeadFields(cmd, a.Repo, id)
+	},
+}
+
+func printLeadFields(cmd *cobra.Command, r *db.Repo, id int64) error {
+	values, err := r.LeadFieldValues(cmd.Context(), id)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		fmt.Println("No custom fields defined.")
+		return nil
+	}
+	for _, fv := range values {
+		fmt.Printf("%-24s %s\n", fv.Field.Name+":", emptyDash(db.FormatFieldValue(fv.Field, fv.Value)))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(fieldCmd)
+	fieldCmd.AddCommand(fieldListCmd)
+	fieldCmd.AddCommand(fieldAddCmd)
+	fieldCmd.AddCommand(fieldDeleteCmd)
+
+	fieldAddCmd.Flags().StringVar(&fieldKind, "type", "text", strings.Join(db.FieldKinds, "|"))
+	fieldAddCmd.Flags().StringVar(&fieldChoices, "choices", "", "comma-separated choices for --type choice")
+
+	leadCmd.AddCommand(leadSetCmd)
+	leadCmd.AddCommand(leadFieldsCmd)
+}
@@ -17,41 +17,54 @@ var followupTodayCmd = &cobra.Command{
 	Use:   "today",
 	Short: "Show follow-ups due today or overdue",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		conn, err := db.Open(dbPath)
+		a, err := openApp(cmd)
 		if err != nil {
 			return err
 		}
-		defer conn.Close()
-
-		// Compare by date; store RFC3339 strings in sqlite, use date() helper.
-		today := time.Now().Format("2006-01-02")
-
-		rows, err := conn.Query(`
-			SELECT id, kind, status, name, source, next_follow_up_at
-			FROM leads
-			WHERE next_follow_up_at IS NOT NULL
-			  AND date(next_follow_up_at) <= date(?)
-			ORDER BY date(next_follow_up_at) ASC
-		`, today)
+		defer a.Close()
+		r := a.Repo
+
+		today := time.Now()
+
+		leads, err := r.ListFollowUpsDue(cmd.Context(), today)
 		if err != nil {
 			return err
 		}
-		defer rows.Close()
+		for _, l := range leads {
+			fmt.Printf("#%d %-6s %-10s %-20s due:%s source:%s\n",
+				l.ID, l.LeadType, l.Status, l.FullName, l.NextFollowUp.Format("2006-01-02"), l.Source)
+		}
 
-		found := false
-		for rows.Next() {
+		// Open tasks are follow-ups too; include the ones due by today.
+		tasks, err := r.ListOpenTasks(cmd.Context())
+		if err != nil {
+			return err
+		}
+		found := len(leads) > 0
+		cutoff := today.Format("2006-01-02")
+		for _, t := range tasks {
+			if t.DueDate == nil || t.DueDate.Format("2006-01-02") > cutoff {
+				continue
+			}
 			found = true
-			var id int64
-			var kind, status, name, source, next string
-			if err := rows.Scan(&id, &kind, &status, &name, &source, &next); err != nil {
-				return err
+			due := t.DueDate.Format("2006-01-02")
+			if t.DueTime != "" {
+				due += " " + t.DueTime
+			}
+			title := t.Title
+			if t.Type != "" {
+				title = t.Type + ": " + title
 			}
-			fmt.Printf("#%d %-6s %-10s %-20s due:%s source:%s\n", id, kind, status, name, next[:10], source)
+			if t.Priority != db.PriorityNormal {
+				title += " (" + db.PriorityName(t.Priority) + ")"
+			}
+			fmt.Printf("#%d task   %-20s due:%s %s\n", t.LeadID, t.LeadName, due, title)
 		}
+
 		if !found {
 			fmt.Println("✅ No follow-ups due today.")
 		}
-		return rows.Err()
+		return nil
 	},
 }
 
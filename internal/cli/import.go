@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/mike-keough/pipelinepal/internal/vcard"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importMap        []string
+	importStageMap   []string
+	importTypeMap    []string
+	importOnDup      string
+	importTag        string
+	importDryRun     bool
+	importSkipErrors bool
+	importType       string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import leads from other tools",
+}
+
+var importCSVCmd = &cobra.Command{
+	Use:   "csv <file>",
+	Short: "Import leads from a CSV file",
+	Long: "Columns are matched to lead fields by header name (\"Name\", \"Full Name\", \"Mobile\", \"Lead Source\", a\n" +
+		"custom field's name, …). Map others with --map TARGET=HEADER; targets are " + strings.Join(db.ImportTargets, ", ") + "\n" +
+		"and field:<custom field>.\n\n" +
+		"  pipelinepal import csv contacts.csv --dry-run\n" +
+		"  pipelinepal import csv zillow.csv --map name=\"Contact\" --map field:Budget=\"Max Price\" \\\n" +
+		"      --stage-map \"Hot=Qualified\" --type-map \"Purchaser=buyer\" --on-duplicate update --tag zillow\n\n" +
+		"Rows that look like a lead on file (same phone, email or name) are skipped, used to update that\n" +
+		"lead, or created anyway, per --on-duplicate. Everything is written in one transaction: if any row\n" +
+		"cannot be imported nothing is, unless --skip-errors is given.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := importSpec()
+		if err != nil {
+			return err
+		}
+		header, records, err := readCSV(args[0])
+		if err != nil {
+			return err
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		plan, err := a.Repo.PlanImport(cmd.Context(), spec, header, records)
+		if err != nil {
+			return err
+		}
+		return runImport(cmd, a.Repo, args[0], "row", plan)
+	},
+}
+
+var importVCFCmd = &cobra.Command{
+	Use:   "vcf <file>",
+	Short: "Import leads from a vCard (.vcf) file",
+	Long: "Reads vCard 3.0 and 4.0 files as exported by phones, Google Contacts and Apple Contacts. Each\n" +
+		"card's name, phones and emails (with their labels), note and categories (as tags) become a lead;\n" +
+		"cards exported by PipelinePal also carry the lead type, source and status.\n\n" +
+		"  pipelinepal import vcf contacts.vcf --type seller --tag open-house --dry-run\n\n" +
+		"Duplicates and errors are handled as for import csv.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := importSpec()
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		cards, err := vcard.Parse(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if len(cards) == 0 {
+			return fmt.Errorf("%s has no cards", args[0])
+		}
+		recs := make([]db.ImportRecord, len(cards))
+		for i, c := range cards {
+			recs[i] = c.ImportRecord(i + 1)
+			if recs[i].Values["type"] == "" {
+				recs[i].Values["type"] = importType
+			}
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		plan, err := a.Repo.PlanImportRecords(cmd.Context(), spec, recs)
+		if err != nil {
+			return err
+		}
+		return runImport(cmd, a.Repo, args[0], "card", plan)
+	},
+}
+
+// importSpec reads the flags the import commands share.
+func importSpec() (db.ImportSpec, error) {
+	spec := db.ImportSpec{OnDuplicate: importOnDup, Tag: importTag}
+	var err error
+	if spec.Mapping, err = parsePairs("--map", importMap); err != nil {
+		return spec, err
+	}
+	if spec.StageMap, err = parsePairs("--stage-map", importStageMap); err != nil {
+		return spec, err
+	}
+	if spec.TypeMap, err = parsePairs("--type-map", importTypeMap); err != nil {
+		return spec, err
+	}
+	return spec, nil
+}
+
+// runImport prints the plan and, unless this is a dry run or some records
+// were rejected, applies it. unit names a record in the listing.
+func runImport(cmd *cobra.Command, r *db.Repo, path, unit string, plan db.ImportPlan) error {
+	printImportPlan(plan, unit, importDryRun)
+
+	errs := plan.Count("error")
+	summary := fmt.Sprintf("%d to create, %d to update, %d skipped, %d with errors",
+		plan.Count("create"), plan.Count("update"), plan.Count("skip"), errs)
+	if importDryRun {
+		fmt.Printf("\n%s. Dry run: nothing was written.\n", summary)
+		return nil
+	}
+	if errs > 0 && !importSkipErrors {
+		return fmt.Errorf("%s; nothing imported (fix the %ss above, or rerun with --skip-errors)", summary, unit)
+	}
+
+	created, updated, err := r.ApplyImport(cmd.Context(), plan)
+	if err != nil {
+		return fmt.Errorf("import rolled back: %w", err)
+	}
+	fmt.Printf("✅ Imported %s: %d created, %d updated, %d skipped", path, created, updated, plan.Count("skip"))
+	if errs > 0 {
+		fmt.Printf(", %d %s(s) with errors left out", errs, unit)
+	}
+	fmt.Println()
+	return nil
+}
+
+// readCSV reads a whole CSV file, header first. A UTF-8 byte order mark
+// (as Excel writes) is dropped.
+func readCSV(path string) ([]string, [][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	rd := csv.NewReader(f)
+	rd.FieldsPerRecord = -1
+	rd.LazyQuotes = true
+	header, err := rd.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%s is empty", path)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	records, err := rd.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return header, records, nil
+}
+
+// parsePairs reads repeated KEY=VALUE flags into a map.
+func parsePairs(flag string, pairs []string) (map[string]string, error) {
+	out := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("%s %q: use KEY=VALUE", flag, p)
+		}
+		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return out, nil
+}
+
+// printImportPlan shows the column mapping, if any, and what each record
+// will do. Only skipped and rejected records are listed unless all is set.
+func printImportPlan(plan db.ImportPlan, unit string, all bool) {
+	targets := make([]string, 0, len(plan.Columns))
+	for t := range plan.Columns {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+	if all && len(targets) > 0 {
+		fmt.Println("Columns:")
+		for _, t := range targets {
+			fmt.Printf("  %-16s ← %q\n", t, plan.Columns[t])
+		}
+		if len(plan.Unmapped) > 0 {
+			fmt.Printf("  (ignored: %s)\n", strings.Join(plan.Unmapped, ", "))
+		}
+		fmt.Println()
+	}
+
+	for _, row := range plan.Rows {
+		l := row.Lead
+		switch row.Action {
+		case "create":
+			if !all {
+				continue
+			}
+			fmt.Printf("%s %-4d create  %s  %s  %s/%s", unit, row.Line, l.FullName, l.LeadType, l.PipelineName, l.StageName)
+			if details := strings.Join(append([]string{l.Phone, l.Email}, row.Tags...), " "); strings.TrimSpace(details) != "" {
+				fmt.Printf("  %s", strings.Join(strings.Fields(details), " "))
+			}
+			if row.Reason != "" {
+				fmt.Printf("  (%s)", row.Reason)
+			}
+		case "update":
+			if !all {
+				continue
+			}
+			fmt.Printf("%s %-4d update  #%d %s: %s", unit, row.Line, l.ID, l.FullName, strings.Join(row.Changes, ", "))
+		case "skip":
+			fmt.Printf("%s %-4d skip    %s: %s", unit, row.Line, l.FullName, row.Reason)
+		default:
+			fmt.Printf("%s %-4d error   %s", unit, row.Line, row.Reason)
+		}
+		fmt.Println()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importCSVCmd)
+
+	importCmd.AddCommand(importVCFCmd)
+
+	importCSVCmd.Flags().StringArrayVar(&importMap, "map", nil, "read a target from a column: TARGET=HEADER (repeatable)")
+	for _, c := range []*cobra.Command{importCSVCmd, importVCFCmd} {
+		c.Flags().StringArrayVar(&importStageMap, "stage-map", nil, "translate a stage value: VALUE=STAGE (repeatable)")
+		c.Flags().StringArrayVar(&importTypeMap, "type-map", nil, "translate a lead type value: VALUE=TYPE (repeatable)")
+		c.Flags().StringVar(&importOnDup, "on-duplicate", "skip", "records matching a lead on file: "+strings.Join(db.DuplicateActions, "|"))
+		c.Flags().StringVar(&importTag, "tag", "", "tag every imported lead")
+		c.Flags().BoolVar(&importDryRun, "dry-run", false, "show what would be imported without writing anything")
+		c.Flags().BoolVar(&importSkipErrors, "skip-errors", false, "import the good records even if some cannot be imported")
+	}
+	importVCFCmd.Flags().StringVar(&importType, "type", "", "lead type for cards that do not carry one (default buyer)")
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 
-	"github.com/mike-keough/pipelinepal/internal/db"
 	"github.com/spf13/cobra"
 )
 
@@ -11,16 +10,13 @@ var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize database and run migrations",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		conn, err := db.Open(dbPath)
+		a, err := openApp(cmd)
 		if err != nil {
 			return err
 		}
-		defer conn.Close()
+		defer a.Close()
 
-		if err := db.Migrate(conn); err != nil {
-			return err
-		}
-		fmt.Println("✅ Database initialized:", dbPath)
+		fmt.Println("✅ Database initialized:", resolvedDBPath())
 		return nil
 	},
 }
@@ -1,18 +1,19 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mike-keough/pipelinepal/internal/db"
-	"github.com/mike-keough/pipelinepal/internal/models"
-	"github.com/mike-keough/pipelinepal/internal/repo"
 	"github.com/spf13/cobra"
 )
 
 var leadCmd = &cobra.Command{
 	Use:   "lead",
-	Short: "Manage leads/buyers/sellers",
+	Short: "Manage leads (buyers, sellers, rentals…)",
 }
 
 var (
@@ -20,79 +21,112 @@ var (
 	leadPhone  string
 	leadEmail  string
 	leadSource string
-	leadKind   string
+	leadType   string
+	leadStage  string
+	leadPipe   string
 	leadStatus string
 	leadNotes  string
 	leadFollow string
+	leadTag    string
+	leadDupOK  bool
 )
 
 var leadAddCmd = &cobra.Command{
 	Use:   "add",
-	Short: "Add a lead (or buyer/seller)",
+	Short: "Add a lead (buyer, seller, rental or other)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		conn, err := db.Open(dbPath)
+		if leadName == "" {
+			return fmt.Errorf("--name is required")
+		}
+
+		next, err := parseFollowUp(leadFollow)
 		if err != nil {
 			return err
 		}
-		defer conn.Close()
 
-		r := repo.NewLeadRepo(conn)
-
-		var next *time.Time
-		if leadFollow != "" {
-			// Accept: 2026-02-03 or RFC3339
-			var t time.Time
-			if len(leadFollow) == 10 {
-				t, err = time.Parse("2006-01-02", leadFollow)
-			} else {
-				t, err = time.Parse(time.RFC3339, leadFollow)
-			}
-			if err != nil {
-				return fmt.Errorf("bad --follow date (use YYYY-MM-DD or RFC3339): %w", err)
-			}
-			next = &t
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
 		}
+		defer a.Close()
+		r := a.Repo
 
-		l := &models.Lead{
-			Name:           leadName,
-			Phone:          leadPhone,
-			Email:          leadEmail,
-			Source:         leadSource,
-			Kind:           defaultStr(leadKind, "lead"),
-			Status:         defaultStr(leadStatus, "new"),
-			Notes:          leadNotes,
-			NextFollowUpAt: next,
+		ltype := defaultStr(strings.ToLower(leadType), "buyer")
+		pipeline, err := r.DefaultPipeline(cmd.Context(), ltype)
+		if leadPipe != "" {
+			pipeline, err = r.FindPipeline(cmd.Context(), leadPipe)
+		}
+		if err != nil {
+			return err
+		}
+		stage, err := r.FindStage(cmd.Context(), pipeline.ID, leadStage)
+		if err != nil {
+			return err
 		}
 
-		if l.Name == "" {
-			return fmt.Errorf("--name is required")
+		l := db.Lead{
+			FullName:     leadName,
+			Phone:        leadPhone,
+			Email:        leadEmail,
+			Source:       leadSource,
+			LeadType:     ltype,
+			StageID:      stage.ID,
+			Status:       defaultStr(strings.ToLower(leadStatus), "new"),
+			Notes:        leadNotes,
+			NextFollowUp: next,
 		}
 
-		id, err := r.Add(l)
+		create := r.CreateLead
+		if leadDupOK {
+			create = r.CreateLeadAllowDuplicate
+		}
+		id, err := create(cmd.Context(), l)
+		var dup *db.DuplicateError
+		if errors.As(err, &dup) {
+			fmt.Println("Possible duplicates:")
+			for _, m := range dup.Matches {
+				fmt.Printf("  (%s) ", strings.Join(m.Reasons, ", "))
+				printLead(m.Lead)
+			}
+			return fmt.Errorf("not added; rerun with --allow-duplicate, or merge later with `pipelinepal lead merge`")
+		}
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("✅ Added %s #%d (%s)\n", l.Kind, id, l.Name)
+		fmt.Printf("✅ Added %s #%d (%s) in %s / %s\n", l.LeadType, id, l.FullName, pipeline.Name, stage.Name)
 		return nil
 	},
 }
 
 var leadListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List leads (or buyers/sellers)",
+	Short: "List leads, optionally of one type",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		conn, err := db.Open(dbPath)
+		a, err := openApp(cmd)
 		if err != nil {
 			return err
 		}
-		defer conn.Close()
+		defer a.Close()
+		r := a.Repo
 
-		r := repo.NewLeadRepo(conn)
-		items, err := r.List(leadKind)
+		items, err := r.ListLeadsByType(cmd.Context(), strings.ToLower(leadType))
 		if err != nil {
 			return err
 		}
+		if leadTag != "" {
+			ids, err := r.LeadIDsWithTag(cmd.Context(), leadTag)
+			if err != nil {
+				return err
+			}
+			kept := items[:0]
+			for _, l := range items {
+				if ids[l.ID] {
+					kept = append(kept, l)
+				}
+			}
+			items = kept
+		}
 
 		if len(items) == 0 {
 			fmt.Println("No records found.")
@@ -100,31 +134,204 @@ var leadListCmd = &cobra.Command{
 		}
 
 		for _, l := range items {
-			fu := ""
-			if l.NextFollowUpAt != nil {
-				fu = l.NextFollowUpAt.Local().Format("2006-01-02")
+			printLead(l)
+		}
+		return nil
+	},
+}
+
+var leadEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a lead; only the flags given are changed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+		r := a.Repo
+
+		l, err := r.GetLead(cmd.Context(), id)
+		if err != nil {
+			return fmt.Errorf("lead #%d: %w", id, err)
+		}
+
+		f := cmd.Flags()
+		if f.Changed("name") {
+			if leadName == "" {
+				return fmt.Errorf("--name cannot be empty")
+			}
+			l.FullName = leadName
+		}
+		if f.Changed("phone") {
+			l.Phone = leadPhone
+		}
+		if f.Changed("email") {
+			l.Email = leadEmail
+		}
+		if f.Changed("source") {
+			l.Source = leadSource
+		}
+		if f.Changed("type") {
+			l.LeadType = strings.ToLower(leadType)
+		}
+		if f.Changed("status") {
+			l.Status = strings.ToLower(leadStatus)
+		}
+		if f.Changed("notes") {
+			l.Notes = leadNotes
+		}
+		if f.Changed("follow") {
+			if l.NextFollowUp, err = parseFollowUp(leadFollow); err != nil {
+				return err
 			}
-			fmt.Printf("#%d %-6s %-10s %-20s follow:%s source:%s\n",
-				l.ID, l.Kind, l.Status, l.Name, fu, l.Source)
 		}
+
+		if err := r.UpdateLead(cmd.Context(), l); err != nil {
+			return err
+		}
+
+		if f.Changed("stage") || f.Changed("pipeline") {
+			pipelineID := l.PipelineID
+			if f.Changed("pipeline") {
+				p, err := r.FindPipeline(cmd.Context(), leadPipe)
+				if err != nil {
+					return err
+				}
+				pipelineID = p.ID
+			}
+			stage, err := r.FindStage(cmd.Context(), pipelineID, leadStage)
+			if err != nil {
+				return err
+			}
+			if err := r.MoveLeadStage(cmd.Context(), l.ID, stage.ID); err != nil {
+				return err
+			}
+		}
+
+		l, err = r.GetLead(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		fmt.Print("✅ Updated ")
+		printLead(l)
+		return nil
+	},
+}
+
+var leadHistoryCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Show a lead's stage timeline",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		l, err := a.Repo.GetLead(cmd.Context(), id)
+		if err != nil {
+			return fmt.Errorf("lead #%d: %w", id, err)
+		}
+		history, err := a.Repo.ListStageHistory(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range history {
+			from := c.FromStage
+			if from == "" {
+				from = "(created)"
+			}
+			fmt.Printf("%s  %s → %s\n", c.ChangedAt.Format("2006-01-02 15:04"), from, c.ToStage)
+		}
+		fmt.Printf("%d day(s) in %s\n", l.DaysInStage(time.Now().UTC()), l.StageName)
 		return nil
 	},
 }
 
 func init() {
 	leadCmd.AddCommand(leadAddCmd)
+	leadCmd.AddCommand(leadHistoryCmd)
 	leadCmd.AddCommand(leadListCmd)
+	leadCmd.AddCommand(leadEditCmd)
 
 	leadAddCmd.Flags().StringVar(&leadName, "name", "", "full name")
 	leadAddCmd.Flags().StringVar(&leadPhone, "phone", "", "phone number")
 	leadAddCmd.Flags().StringVar(&leadEmail, "email", "", "email")
 	leadAddCmd.Flags().StringVar(&leadSource, "source", "", "lead source (referral, open house, online, etc.)")
-	leadAddCmd.Flags().StringVar(&leadKind, "kind", "lead", "lead|buyer|seller")
+	leadAddCmd.Flags().StringVar(&leadType, "type", "buyer", "buyer|seller|rental|other")
+	leadAddCmd.Flags().StringVar(&leadPipe, "pipeline", "", "pipeline name (default: the one for --type)")
+	leadAddCmd.Flags().StringVar(&leadStage, "stage", "", "pipeline stage name (default: first stage)")
 	leadAddCmd.Flags().StringVar(&leadStatus, "status", "new", "new|contacted|nurture|hot|cold|closed|dead")
 	leadAddCmd.Flags().StringVar(&leadNotes, "notes", "", "notes")
 	leadAddCmd.Flags().StringVar(&leadFollow, "follow", "", "next follow up date (YYYY-MM-DD or RFC3339)")
+	leadAddCmd.Flags().BoolVar(&leadDupOK, "allow-duplicate", false, "add even if the lead matches an existing one")
+
+	leadListCmd.Flags().StringVar(&leadType, "type", "", "filter by type: buyer|seller|rental|other (empty = all)")
+	leadListCmd.Flags().StringVar(&leadTag, "tag", "", "only leads with this tag")
 
-	leadListCmd.Flags().StringVar(&leadKind, "kind", "", "filter by kind: lead|buyer|seller (empty = all)")
+	leadEditCmd.Flags().StringVar(&leadName, "name", "", "full name")
+	leadEditCmd.Flags().StringVar(&leadPhone, "phone", "", "phone number")
+	leadEditCmd.Flags().StringVar(&leadEmail, "email", "", "email")
+	leadEditCmd.Flags().StringVar(&leadSource, "source", "", "lead source")
+	leadEditCmd.Flags().StringVar(&leadType, "type", "", "buyer|seller|rental|other")
+	leadEditCmd.Flags().StringVar(&leadPipe, "pipeline", "", "move to this pipeline (first stage unless --stage)")
+	leadEditCmd.Flags().StringVar(&leadStage, "stage", "", "move to this stage of the lead's pipeline")
+	leadEditCmd.Flags().StringVar(&leadStatus, "status", "", "new|contacted|nurture|hot|cold|closed|dead")
+	leadEditCmd.Flags().StringVar(&leadNotes, "notes", "", "notes (replaces existing)")
+	leadEditCmd.Flags().StringVar(&leadFollow, "follow", "", "next follow up date (YYYY-MM-DD or RFC3339; empty clears)")
+}
+
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(s, "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("bad lead id %q", s)
+	}
+	return id, nil
+}
+
+func printLead(l db.Lead) {
+	fu := ""
+	if l.NextFollowUp != nil {
+		fu = l.NextFollowUp.Format("2006-01-02")
+	}
+	fmt.Printf("#%d %-6s %-24s %-10s %-20s follow:%s source:%s",
+		l.ID, l.LeadType, l.PipelineName+"/"+l.StageName, l.Status, l.FullName, fu, l.Source)
+	if len(l.Tags) > 0 {
+		fmt.Printf(" %s", joinTags(l.Tags))
+	}
+	fmt.Println()
+}
+
+// parseFollowUp accepts 2026-02-03 or RFC3339; empty means no follow-up.
+func parseFollowUp(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var t time.Time
+	var err error
+	if len(s) == 10 {
+		t, err = time.Parse("2006-01-02", s)
+	} else {
+		t, err = time.Parse(time.RFC3339, s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad --follow date (use YYYY-MM-DD or RFC3339): %w", err)
+	}
+	return &t, nil
 }
 
 func defaultStr(v, d string) string {
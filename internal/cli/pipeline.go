@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var pipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Manage pipelines (buyer, seller, rental…)",
+}
+
+var pipelineLeadType string
+
+var pipelineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pipelines",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		pipelines, err := a.Repo.ListPipelines(cmd.Context())
+		if err != nil {
+			return err
+		}
+		for _, p := range pipelines {
+			stages, err := a.Repo.ListStages(cmd.Context(), p.ID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("#%d %-16s type:%-8s stages:%d\n", p.ID, p.Name, emptyDash(p.LeadType), len(stages))
+		}
+		return nil
+	},
+}
+
+var pipelineAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a pipeline (starts with a single New stage)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		id, err := a.Repo.CreatePipeline(cmd.Context(), args[0], pipelineLeadType)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Added pipeline #%d (%s)\n", id, args[0])
+		return nil
+	},
+}
+
+func emptyDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(pipelineCmd)
+	pipelineCmd.AddCommand(pipelineListCmd)
+	pipelineCmd.AddCommand(pipelineAddCmd)
+
+	pipelineAddCmd.Flags().StringVar(&pipelineLeadType, "type", "", "lead type that defaults to this pipeline")
+}
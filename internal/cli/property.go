@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	propAddress string
+	propPrice   string
+	propBeds    int
+	propBaths   float64
+	propSqft    int
+	propMLS     string
+	propStatus  string
+	propRole    string
+	propLead    string
+)
+
+var propertyCmd = &cobra.Command{
+	Use:   "property",
+	Short: "Manage properties (listings, homes buyers toured or offered on)",
+}
+
+var propertyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List properties (or the ones linked to --lead)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if propLead != "" {
+			id, err := parseID(propLead)
+			if err != nil {
+				return err
+			}
+			links, err := a.Repo.ListLeadProperties(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			if len(links) == 0 {
+				fmt.Println("No properties linked.")
+				return nil
+			}
+			for _, pl := range links {
+				fmt.Printf("%-10s ", pl.Role)
+				printProperty(pl.Property)
+			}
+			return nil
+		}
+
+		props, err := a.Repo.ListProperties(cmd.Context(), strings.ToLower(propStatus))
+		if err != nil {
+			return err
+		}
+		if len(props) == 0 {
+			fmt.Println("No records found.")
+			return nil
+		}
+		for _, p := range props {
+			printProperty(p)
+		}
+		return nil
+	},
+}
+
+var propertyAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a property",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		p := db.Property{
+			Address:   propAddress,
+			Beds:      propBeds,
+			Baths:     propBaths,
+			Sqft:      propSqft,
+			MLSNumber: propMLS,
+			Status:    propStatus,
+		}
+		if propPrice != "" {
+			if p.Price, err = db.ParseAmount(propPrice); err != nil {
+				return fmt.Errorf("--price: %w", err)
+			}
+		}
+
+		id, err := a.Repo.CreateProperty(cmd.Context(), p)
+		if err != nil {
+			return err
+		}
+		if p, err = a.Repo.GetProperty(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Print("✅ Added ")
+		printProperty(p)
+		return nil
+	},
+}
+
+var propertyEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a property; only the flags given are changed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		p, err := a.Repo.GetProperty(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		f := cmd.Flags()
+		if f.Changed("address") {
+			p.Address = propAddress
+		}
+		if f.Changed("price") {
+			if p.Price, err = db.ParseAmount(propPrice); err != nil {
+				return fmt.Errorf("--price: %w", err)
+			}
+		}
+		if f.Changed("beds") {
+			p.Beds = propBeds
+		}
+		if f.Changed("baths") {
+			p.Baths = propBaths
+		}
+		if f.Changed("sqft") {
+			p.Sqft = propSqft
+		}
+		if f.Changed("mls") {
+			p.MLSNumber = propMLS
+		}
+		if f.Changed("status") {
+			p.Status = propStatus
+		}
+
+		if err := a.Repo.UpdateProperty(cmd.Context(), p); err != nil {
+			return err
+		}
+		if p, err = a.Repo.GetProperty(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Print("✅ Updated ")
+		printProperty(p)
+		return nil
+	},
+}
+
+var propertyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a property and the leads linked to it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		p, err := a.Repo.GetProperty(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		printProperty(p)
+
+		links, err := a.Repo.ListPropertyLeads(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		for _, pl := range links {
+			fmt.Printf("  %-10s lead #%d %s\n", pl.Role, pl.LeadID, pl.LeadName)
+		}
+		return nil
+	},
+}
+
+var propertyDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a property and its links to leads",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.DeleteProperty(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted property #%d\n", id)
+		return nil
+	},
+}
+
+var propertyLinkCmd = &cobra.Command{
+	Use:   "link <property-id> <lead-id>",
+	Short: "Link a property to a lead (re-linking changes the role)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		propID, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		leadID, err := parseID(args[1])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.LinkProperty(cmd.Context(), leadID, propID, propRole); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Linked property #%d to lead #%d as %s\n", propID, leadID, strings.ToLower(propRole))
+		return nil
+	},
+}
+
+var propertyUnlinkCmd = &cobra.Command{
+	Use:   "unlink <property-id> <lead-id>",
+	Short: "Remove the link between a property and a lead",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		propID, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		leadID, err := parseID(args[1])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := a.Repo.UnlinkProperty(cmd.Context(), leadID, propID); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Unlinked property #%d from lead #%d\n", propID, leadID)
+		return nil
+	},
+}
+
+func printProperty(p db.Property) {
+	fmt.Printf("#%d %-32s %s\n", p.ID, p.Address, p.Summary())
+}
+
+func init() {
+	rootCmd.AddCommand(propertyCmd)
+	propertyCmd.AddCommand(propertyListCmd)
+	propertyCmd.AddCommand(propertyAddCmd)
+	propertyCmd.AddCommand(propertyEditCmd)
+	propertyCmd.AddCommand(propertyShowCmd)
+	propertyCmd.AddCommand(propertyDeleteCmd)
+	propertyCmd.AddCommand(propertyLinkCmd)
+	propertyCmd.AddCommand(propertyUnlinkCmd)
+
+	propertyListCmd.Flags().StringVar(&propStatus, "status", "", "filter by status: "+strings.Join(db.PropertyStatuses, "|"))
+	propertyListCmd.Flags().StringVar(&propLead, "lead", "", "only properties linked to this lead id")
+
+	for _, c := range []*cobra.Command{propertyAddCmd, propertyEditCmd} {
+		c.Flags().StringVar(&propAddress, "address", "", "street address")
+		c.Flags().StringVar(&propPrice, "price", "", "list or sale price (e.g. 450000, $450k)")
+		c.Flags().IntVar(&propBeds, "beds", 0, "bedrooms")
+		c.Flags().Float64Var(&propBaths, "baths", 0, "bathrooms (2.5 for two and a half)")
+		c.Flags().IntVar(&propSqft, "sqft", 0, "square feet")
+		c.Flags().StringVar(&propMLS, "mls", "", "MLS number")
+	}
+	propertyAddCmd.Flags().StringVar(&propStatus, "status", "active", strings.Join(db.PropertyStatuses, "|"))
+	propertyEditCmd.Flags().StringVar(&propStatus, "status", "", strings.Join(db.PropertyStatuses, "|"))
+
+	propertyLinkCmd.Flags().StringVar(&propRole, "role", "interested", strings.Join(db.PropertyRoles, "|"))
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Pipeline and income reports",
+}
+
+var reportForecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Weighted GCI of open deals by expected close month",
+	Long: "Each open deal's GCI (price × commission rate) is weighted by the win probability\n" +
+		"of its lead's current stage (set with `pipelinepal stage probability`).",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		items, err := a.Repo.Forecast(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			fmt.Println("No open deals.")
+			return nil
+		}
+
+		fmt.Printf("%-30s %5s %14s %14s\n", "MONTH", "DEALS", "GCI", "WEIGHTED")
+		var total db.ForecastMonth
+		for _, fm := range db.ForecastByMonth(items) {
+			label := "no close date"
+			if t, err := time.Parse("2006-01", fm.Month); err == nil {
+				label = t.Format("Jan 2006")
+			}
+			fmt.Printf("%-30s %5d %14s %14s\n", label, fm.Deals, db.FormatMoney(fm.GCI), db.FormatMoney(fm.Weighted))
+			total.Deals += fm.Deals
+			total.GCI += fm.GCI
+			total.Weighted += fm.Weighted
+		}
+		fmt.Printf("%-30s %5d %14s %14s\n\n", "TOTAL", total.Deals, db.FormatMoney(total.GCI), db.FormatMoney(total.Weighted))
+
+		fmt.Printf("%-30s %5s %14s %14s\n", "STAGE", "WIN%", "GCI", "WEIGHTED")
+		for _, it := range items {
+			fmt.Printf("%-30s %4d%% %14s %14s  #%d %s\n", it.Pipeline+"/"+it.Stage, it.WinProb,
+				db.FormatMoney(it.Deal.GrossCommission()), db.FormatMoney(it.Weighted()), it.Deal.LeadID, it.Deal.LeadName)
+		}
+		return nil
+	},
+}
+
+var incomeAsOf string
+
+var reportIncomeCmd = &cobra.Command{
+	Use:   "income",
+	Short: "Year-to-date net income from closed deals, with brokerage cap progress",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asOf := time.Now().UTC()
+		if incomeAsOf != "" {
+			t, err := time.Parse("2006-01-02", incomeAsOf)
+			if err != nil {
+				return fmt.Errorf("--as-of: use YYYY-MM-DD")
+			}
+			asOf = t
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		rep, err := a.Repo.Income(cmd.Context(), asOf)
+		if err != nil {
+			return err
+		}
+		if len(rep.Deals) == 0 {
+			fmt.Println("No closed deals this year.")
+			return nil
+		}
+
+		fmt.Printf("%-10s %-20s %11s %10s %11s %9s %10s %11s\n",
+			"CLOSED", "LEAD", "GCI", "REFERRAL", "BROKERAGE", "FEES", "TEAM", "NET")
+		for _, n := range rep.Deals {
+			capNote := ""
+			if n.CapHit {
+				capNote = "  capped"
+			}
+			fmt.Printf("%-10s %-20s %11s %10s %11s %9s %10s %11s%s\n",
+				fmtDate(n.Deal.ClosedDate), n.Deal.LeadName, db.FormatMoney(n.GCI), db.FormatMoney(n.Referral),
+				db.FormatMoney(n.Brokerage), db.FormatMoney(n.Fees), db.FormatMoney(n.Team), db.FormatMoney(n.Net), capNote)
+		}
+		fmt.Println()
+
+		for _, y := range rep.Plans {
+			name := y.Plan.Name
+			if name == "" {
+				name = "(no split plan)"
+			}
+			fmt.Printf("%s since %s: %d deal(s), GCI %s, net %s\n",
+				name, y.Since.Format("2006-01-02"), y.Deals, db.FormatMoney(y.GCI), db.FormatMoney(y.Net))
+			switch {
+			case y.Plan.AnnualCap <= 0:
+			case y.CapHitOn != nil:
+				fmt.Printf("  🎯 cap of %s hit on %s — 100%% to you until %s\n", db.FormatMoney(y.Plan.AnnualCap),
+					y.CapHitOn.Format("2006-01-02"), y.Since.AddDate(1, 0, -1).Format("2006-01-02"))
+			default:
+				fmt.Printf("  cap: %s of %s paid, %s to go\n", db.FormatMoney(y.CapPaid),
+					db.FormatMoney(y.Plan.AnnualCap), db.FormatMoney(y.Plan.AnnualCap-y.CapPaid))
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportForecastCmd)
+	reportCmd.AddCommand(reportIncomeCmd)
+
+	reportIncomeCmd.Flags().StringVar(&incomeAsOf, "as-of", "", "report date (YYYY-MM-DD, default today)")
+}
@@ -3,15 +3,21 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/mike-keough/pipelinepal/internal/app"
 	"github.com/spf13/cobra"
 )
 
 var (
+	dataDir string
 	dbPath  string
 	rootCmd = &cobra.Command{
-		Use:   "pipelinepal",
-		Short: "PipelinePal - terminal CRM for real estate",
+		Use:           "pipelinepal",
+		Short:         "PipelinePal - terminal CRM for real estate",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          runTUI,
 	}
 )
 
@@ -23,13 +29,31 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&dbPath, "db", defaultDBPath(), "path to sqlite db file")
+	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", app.DefaultDataDir(), "directory holding the database and exports")
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "path to sqlite db file (default: <data-dir>/"+app.DBFile+")")
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(tuiCmd)
 	rootCmd.AddCommand(leadCmd)
 	rootCmd.AddCommand(followupCmd)
 }
 
-func defaultDBPath() string {
-	home, _ := os.UserHomeDir()
-	return home + "/.local/share/pipelinepal/pipelinepal.db"
+func resolvedDBPath() string {
+	if dbPath != "" {
+		return dbPath
+	}
+	return filepath.Join(dataDir, app.DBFile)
+}
+
+// openApp opens the database, applies pending migrations and returns the
+// same App the TUI runs on. Callers close it.
+func openApp(cmd *cobra.Command) (*app.App, error) {
+	a, err := app.New(resolvedDBPath())
+	if err != nil {
+		return nil, fmt.Errorf("init error: %w", err)
+	}
+	if err := a.Bootstrap(cmd.Context()); err != nil {
+		_ = a.Close()
+		return nil, fmt.Errorf("bootstrap error: %w", err)
+	}
+	return a, nil
 }
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search leads, notes and task titles; filters like type:buyer stale:>14d narrow results",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		q := strings.Join(args, " ")
+		hits, err := a.Repo.QueryLeads(cmd.Context(), q)
+		if err != nil {
+			return err
+		}
+		if f, _ := db.ParseFilter(q); f.Text != "" && !a.Repo.FullTextSearch(cmd.Context()) {
+			fmt.Fprintln(os.Stderr, "Note: "+db.SearchFallbackNote)
+		}
+		printHits(hits)
+		return nil
+	},
+}
+
+func printHits(hits []db.SearchHit) {
+	if len(hits) == 0 {
+		fmt.Println("No matches.")
+		return
+	}
+
+	// lipgloss drops the styling when stdout is not a terminal.
+	match := lipgloss.NewStyle().Bold(true).Underline(true)
+	for _, h := range hits {
+		printLead(h.Lead)
+		if h.Snippet != "" {
+			fmt.Printf("    %s: %s\n", h.Kind, renderSnippet(h.Snippet, match))
+		}
+	}
+}
+
+func renderSnippet(s string, style lipgloss.Style) string {
+	s = strings.Join(strings.Fields(s), " ")
+	for {
+		start := strings.Index(s, db.HighlightStart)
+		end := strings.Index(s, db.HighlightEnd)
+		if start < 0 || end < start {
+			return s
+		}
+		s = s[:start] + style.Render(s[start+len(db.HighlightStart):end]) + s[end+len(db.HighlightEnd):]
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+}
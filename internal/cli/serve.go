@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr   string
+	serveEvents bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve PipelinePal data over HTTP",
+}
+
+var serveICSCmd = &cobra.Command{
+	Use:   "ics",
+	Short: "Serve open tasks as a calendar feed apps can subscribe to",
+	Long: "Serves open tasks at http://<addr>/tasks.ics until interrupted. Subscribe to that URL from\n" +
+		"Apple Calendar, Thunderbird or Outlook; each refresh reads the database afresh. Query parameters\n" +
+		"narrow the feed like the export flags do:\n\n" +
+		"  pipelinepal serve ics --addr 127.0.0.1:8765\n" +
+		"  http://127.0.0.1:8765/tasks.ics?type=buyer&events=1\n\n" +
+		"Parameters: stage, type, source, events (1: all-day events instead of to-dos). The feed has no\n" +
+		"authentication, so keep --addr on localhost unless the network is trusted.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /tasks.ics", func(w http.ResponseWriter, req *http.Request) {
+			q := req.URL.Query()
+			f := db.ExportFilter{Stage: q.Get("stage"), Type: q.Get("type"), Source: q.Get("source")}
+			events := serveEvents
+			if v := q.Get("events"); v != "" {
+				events, _ = strconv.ParseBool(v)
+			}
+			// Build the whole calendar first so a database error can still
+			// be reported as one.
+			var buf bytes.Buffer
+			if _, err := writeTaskCalendar(req.Context(), a.Repo, f, events, &buf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+			w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+			_, _ = w.Write(buf.Bytes())
+		})
+
+		srv := &http.Server{Addr: serveAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdown)
+		}()
+
+		fmt.Printf("Serving tasks at http://%s/tasks.ics (ctrl+c to stop)\n", serveAddr)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.AddCommand(serveICSCmd)
+
+	serveICSCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8765", "address to listen on")
+	serveICSCmd.Flags().BoolVar(&serveEvents, "events", false, "serve all-day events on the due date instead of to-dos")
+}
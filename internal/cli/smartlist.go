@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var smartListCmd = &cobra.Command{
+	Use:   "smartlist",
+	Short: "Saved lead filters (also shown as tabs in the TUI Leads view)",
+	Long: "Smart lists save a filter query, for example:\n\n" +
+		"  pipelinepal smartlist save \"Stale Zillow buyers\" type:buyer source:zillow stage:Contacted stale:>14d\n\n" +
+		"Filter keys: " + strings.Join(db.FilterKeys, " ") + "\n" +
+		"Prefix a term with ! (or - after --) to negate it; other words are full-text searched.",
+}
+
+var smartListListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved smart lists",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		lists, err := a.Repo.ListSmartLists(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(lists) == 0 {
+			fmt.Println("No smart lists saved.")
+			return nil
+		}
+		for _, sl := range lists {
+			fmt.Printf("#%d %-24s %s\n", sl.ID, sl.Name, sl.Query)
+		}
+		return nil
+	},
+}
+
+var smartListSaveCmd = &cobra.Command{
+	Use:   "save <name> <query>",
+	Short: "Save (or replace) a smart list",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if _, err := a.Repo.SaveSmartList(cmd.Context(), args[0], strings.Join(args[1:], " ")); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Saved smart list %s\n", args[0])
+		return nil
+	},
+}
+
+var smartListShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Run a smart list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		sl, err := a.Repo.FindSmartList(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		hits, err := a.Repo.QueryLeads(cmd.Context(), sl.Query)
+		if err != nil {
+			return err
+		}
+		printHits(hits)
+		return nil
+	},
+}
+
+var smartListDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a smart list",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		sl, err := a.Repo.FindSmartList(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.DeleteSmartList(cmd.Context(), sl.ID); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted smart list %s\n", sl.Name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(smartListCmd)
+	smartListCmd.AddCommand(smartListListCmd)
+	smartListCmd.AddCommand(smartListSaveCmd)
+	smartListCmd.AddCommand(smartListShowCmd)
+	smartListCmd.AddCommand(smartListDeleteCmd)
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitAgent   float64
+	splitCap     string
+	splitCapFrom int
+	splitFee     string
+	splitTeam    float64
+	splitDefault bool
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Commission split plans (brokerage split, cap, fees, team override)",
+	Long: "A split plan turns a closed deal's GCI into take-home pay:\n\n" +
+		"  1. referral fee (set per deal with `deal edit --referral`) comes off the top\n" +
+		"  2. the brokerage keeps (100 - agent split)% until the annual cap is paid\n" +
+		"  3. the per-transaction fee is deducted\n" +
+		"  4. the team lead override takes its percent of what is left\n\n" +
+		"  pipelinepal split add \"KW 70/30\" --agent-split 70 --cap 18000 --cap-start 4 --fee 395",
+}
+
+var splitListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List split plans",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		plans, err := a.Repo.ListSplitPlans(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(plans) == 0 {
+			fmt.Println("No split plans yet.")
+			return nil
+		}
+		for _, p := range plans {
+			printSplitPlan(p)
+		}
+		return nil
+	},
+}
+
+var splitAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a split plan (the first one becomes the default)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		p := db.SplitPlan{
+			Name:          args[0],
+			AgentSplit:    splitAgent,
+			CapStartMonth: splitCapFrom,
+			TeamOverride:  splitTeam,
+			IsDefault:     splitDefault,
+		}
+		if err := applySplitAmounts(cmd, &p); err != nil {
+			return err
+		}
+		id, err := a.Repo.CreateSplitPlan(cmd.Context(), p)
+		if err != nil {
+			return err
+		}
+		if p, err = a.Repo.GetSplitPlan(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Print("✅ Added ")
+		printSplitPlan(p)
+		return nil
+	},
+}
+
+var splitEditCmd = &cobra.Command{
+	Use:   "edit <plan>",
+	Short: "Edit a split plan; only the flags given are changed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		p, err := a.Repo.FindSplitPlan(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		f := cmd.Flags()
+		if f.Changed("agent-split") {
+			p.AgentSplit = splitAgent
+		}
+		if f.Changed("cap-start") {
+			p.CapStartMonth = splitCapFrom
+		}
+		if f.Changed("team") {
+			p.TeamOverride = splitTeam
+		}
+		if err := applySplitAmounts(cmd, &p); err != nil {
+			return err
+		}
+		if err := a.Repo.UpdateSplitPlan(cmd.Context(), p); err != nil {
+			return err
+		}
+		if f.Changed("default") && splitDefault {
+			if err := a.Repo.SetDefaultSplitPlan(cmd.Context(), p.ID); err != nil {
+				return err
+			}
+		}
+		if p, err = a.Repo.GetSplitPlan(cmd.Context(), p.ID); err != nil {
+			return err
+		}
+		fmt.Print("✅ Updated ")
+		printSplitPlan(p)
+		return nil
+	},
+}
+
+var splitDeleteCmd = &cobra.Command{
+	Use:   "delete <plan>",
+	Short: "Delete a split plan (its deals fall back to the default plan)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		p, err := a.Repo.FindSplitPlan(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.DeleteSplitPlan(cmd.Context(), p.ID); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted split plan %s\n", p.Name)
+		return nil
+	},
+}
+
+// applySplitAmounts copies the --cap and --fee flags that were given onto p.
+func applySplitAmounts(cmd *cobra.Command, p *db.SplitPlan) error {
+	f := cmd.Flags()
+	var err error
+	if f.Changed("cap") {
+		if p.AnnualCap, err = db.ParseAmount(splitCap); err != nil {
+			return fmt.Errorf("--cap: %w", err)
+		}
+	}
+	if f.Changed("fee") {
+		if p.TransactionFee, err = db.ParseAmount(splitFee); err != nil {
+			return fmt.Errorf("--fee: %w", err)
+		}
+	}
+	return nil
+}
+
+func printSplitPlan(p db.SplitPlan) {
+	def := ""
+	if p.IsDefault {
+		def = " (default)"
+	}
+	capText := "no cap"
+	if p.AnnualCap > 0 {
+		capText = fmt.Sprintf("cap %s from month %d", db.FormatMoney(p.AnnualCap), p.CapStartMonth)
+	}
+	fmt.Printf("#%d %-20s agent %.0f%% • %s • fee %s • team %.0f%%%s\n",
+		p.ID, p.Name, p.AgentSplit, capText, db.FormatMoney(p.TransactionFee), p.TeamOverride, def)
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+	splitCmd.AddCommand(splitListCmd)
+	splitCmd.AddCommand(splitAddCmd)
+	splitCmd.AddCommand(splitEditCmd)
+	splitCmd.AddCommand(splitDeleteCmd)
+
+	for _, c := range []*cobra.Command{splitAddCmd, splitEditCmd} {
+		c.Flags().Float64Var(&splitAgent, "agent-split", 70, "agent's share of GCI in percent, before the cap")
+		c.Flags().StringVar(&splitCap, "cap", "0", "company dollar cap per year (0 = no cap)")
+		c.Flags().IntVar(&splitCapFrom, "cap-start", 1, "month (1-12) the cap year starts")
+		c.Flags().StringVar(&splitFee, "fee", "0", "flat fee per closed transaction")
+		c.Flags().Float64Var(&splitTeam, "team", 0, "team lead override, percent of the agent's share")
+		c.Flags().BoolVar(&splitDefault, "default", false, "use for deals without their own plan")
+	}
+}
@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var stageCmd = &cobra.Command{
+	Use:   "stage",
+	Short: "Manage pipeline stages",
+}
+
+var (
+	stagePipe   string
+	stageColor  string
+	stageMoveTo string
+)
+
+var stageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stages in board order",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		pipelineID, err := stagePipelineID(cmd.Context(), a.Repo)
+		if err != nil {
+			return err
+		}
+		pipelines, err := a.Repo.ListPipelines(cmd.Context())
+		if err != nil {
+			return err
+		}
+		names := make(map[int64]string, len(pipelines))
+		for _, p := range pipelines {
+			names[p.ID] = p.Name
+		}
+
+		stages, err := a.Repo.ListStages(cmd.Context(), pipelineID)
+		if err != nil {
+			return err
+		}
+		// Column widths come from the data so ids past 9 stay aligned.
+		idWidth, nameWidth := 0, 0
+		for _, s := range stages {
+			idWidth = max(idWidth, len(fmt.Sprint("#", s.ID)))
+			nameWidth = max(nameWidth, utf8.RuneCountInString(s.Name))
+		}
+		pos := 0
+		for i, s := range stages {
+			if i == 0 || stages[i-1].PipelineID != s.PipelineID {
+				fmt.Printf("%s pipeline\n", names[s.PipelineID])
+				pos = 0
+			}
+			pos++
+			n, err := a.Repo.CountStageLeads(cmd.Context(), s.ID)
+			if err != nil {
+				return err
+			}
+			deal := ""
+			if s.DealPrompt {
+				deal = " deal-prompt"
+			}
+			fmt.Printf("%2d. %-*s %-*s  leads:%-4d win:%3d%% color:%s%s\n",
+				pos, idWidth, fmt.Sprint("#", s.ID), nameWidth, s.Name, n, s.WinProb, s.Color, deal)
+		}
+		return nil
+	},
+}
+
+var stageAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a stage at the end of the board",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		pipelineID, err := stagePipelineID(cmd.Context(), a.Repo)
+		if err != nil {
+			return err
+		}
+		if pipelineID == 0 {
+			p, err := a.Repo.DefaultPipeline(cmd.Context(), "")
+			if err != nil {
+				return err
+			}
+			pipelineID = p.ID
+		}
+
+		id, err := a.Repo.CreateStage(cmd.Context(), pipelineID, args[0], stageColor)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Added stage #%d (%s)\n", id, args[0])
+		return nil
+	},
+}
+
+var stageRenameCmd = &cobra.Command{
+	Use:   "rename <stage> <new name>",
+	Short: "Rename a stage",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		st, err := resolveStage(cmd.Context(), a.Repo, args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.RenameStage(cmd.Context(), st.ID, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Renamed %s to %s\n", st.Name, args[1])
+		return nil
+	},
+}
+
+var stageColorCmd = &cobra.Command{
+	Use:   "color <stage> <#RRGGBB|0-255|\"\">",
+	Short: "Set the color of a stage's column header",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		st, err := resolveStage(cmd.Context(), a.Repo, args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.SetStageColor(cmd.Context(), st.ID, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Colored %s\n", st.Name)
+		return nil
+	},
+}
+
+var stageProbabilityCmd = &cobra.Command{
+	Use:   "probability <stage> <0-100>",
+	Short: "Set the percent chance that a deal in the stage closes (weights the forecast)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pct, err := strconv.Atoi(strings.TrimSuffix(args[1], "%"))
+		if err != nil {
+			return fmt.Errorf("bad probability %q", args[1])
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		st, err := resolveStage(cmd.Context(), a.Repo, args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.SetStageWinProb(cmd.Context(), st.ID, pct); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s win probability %d%%\n", st.Name, pct)
+		return nil
+	},
+}
+
+var stageDealPromptCmd = &cobra.Command{
+	Use:   "deal-prompt <stage> <on|off>",
+	Short: "Offer to create a deal when a lead is moved into the stage in the TUI",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var on bool
+		switch strings.ToLower(args[1]) {
+		case "on":
+			on = true
+		case "off":
+		default:
+			return fmt.Errorf("expected on or off, got %q", args[1])
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		st, err := resolveStage(cmd.Context(), a.Repo, args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.SetStageDealPrompt(cmd.Context(), st.ID, on); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deal prompt %s for %s\n", strings.ToLower(args[1]), st.Name)
+		return nil
+	},
+}
+
+var stageMoveCmd = &cobra.Command{
+	Use:   "move <stage> <position>",
+	Short: "Move a stage to a 1-based position on the board",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pos, err := strconv.Atoi(args[1])
+		if err != nil || pos < 1 {
+			return fmt.Errorf("bad position %q", args[1])
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		st, err := resolveStage(cmd.Context(), a.Repo, args[0])
+		if err != nil {
+			return err
+		}
+		if err := a.Repo.MoveStage(cmd.Context(), st.ID, pos-1); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Moved %s to position %d\n", st.Name, pos)
+		return nil
+	},
+}
+
+var stageDeleteCmd = &cobra.Command{
+	Use:   "delete <stage>",
+	Short: "Delete a stage (use --move-to when it still has leads)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		st, err := resolveStage(cmd.Context(), a.Repo, args[0])
+		if err != nil {
+			return err
+		}
+
+		// The leads stay in their pipeline, so --move-to names a stage of
+		// the same one.
+		var moveTo int64
+		if stageMoveTo != "" {
+			if id, err := strconv.ParseInt(stageMoveTo, 10, 64); err == nil {
+				moveTo = id
+			} else {
+				dest, err := a.Repo.FindStage(cmd.Context(), st.PipelineID, stageMoveTo)
+				if err != nil {
+					return err
+				}
+				moveTo = dest.ID
+			}
+		}
+
+		if err := a.Repo.DeleteStage(cmd.Context(), st.ID, moveTo); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted stage %s\n", st.Name)
+		return nil
+	},
+}
+
+// resolveStage accepts a stage id or name; names are looked up in the
+// --pipeline pipeline, else the first pipeline that has one.
+func resolveStage(ctx context.Context, r *db.Repo, arg string) (db.Stage, error) {
+	if arg == "" {
+		return db.Stage{}, fmt.Errorf("stage is required")
+	}
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return r.GetStage(ctx, id)
+	}
+	pipelineID, err := stagePipelineID(ctx, r)
+	if err != nil {
+		return db.Stage{}, err
+	}
+	return r.FindStage(ctx, pipelineID, arg)
+}
+
+// stagePipelineID resolves --pipeline; 0 means all pipelines.
+func stagePipelineID(ctx context.Context, r *db.Repo) (int64, error) {
+	if stagePipe == "" {
+		return 0, nil
+	}
+	p, err := r.FindPipeline(ctx, stagePipe)
+	if err != nil {
+		return 0, err
+	}
+	return p.ID, nil
+}
+
+func init() {
+	rootCmd.AddCommand(stageCmd)
+	stageCmd.AddCommand(stageListCmd)
+	stageCmd.AddCommand(stageAddCmd)
+	stageCmd.AddCommand(stageRenameCmd)
+	stageCmd.AddCommand(stageColorCmd)
+	stageCmd.AddCommand(stageMoveCmd)
+	stageCmd.AddCommand(stageDealPromptCmd)
+	stageCmd.AddCommand(stageProbabilityCmd)
+	stageCmd.AddCommand(stageDeleteCmd)
+
+	stageCmd.PersistentFlags().StringVar(&stagePipe, "pipeline", "", "pipeline the stage belongs to (name or id)")
+	stageAddCmd.Flags().StringVar(&stageColor, "color", "", "header color (#RRGGBB or 0-255)")
+	stageDeleteCmd.Flags().StringVar(&stageMoveTo, "move-to", "", "stage to move remaining leads into")
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var leadTagCmd = &cobra.Command{
+	Use:   "tag <id> <tag>...",
+	Short: "Add one or more tags to a lead",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		for _, t := range args[1:] {
+			if err := a.Repo.TagLead(cmd.Context(), id, t); err != nil {
+				return err
+			}
+		}
+		l, err := a.Repo.GetLead(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Lead #%d tags: %s\n", id, emptyDash(joinTags(l.Tags)))
+		return nil
+	},
+}
+
+var leadUntagCmd = &cobra.Command{
+	Use:   "untag <id> <tag>...",
+	Short: "Remove one or more tags from a lead",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		for _, t := range args[1:] {
+			if err := a.Repo.UntagLead(cmd.Context(), id, t); err != nil {
+				return err
+			}
+		}
+		l, err := a.Repo.GetLead(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Lead #%d tags: %s\n", id, emptyDash(joinTags(l.Tags)))
+		return nil
+	},
+}
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags and how many leads carry each",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		tags, err := a.Repo.ListTags(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			fmt.Println("No tags yet.")
+			return nil
+		}
+		for _, t := range tags {
+			fmt.Printf("%-24s %d\n", "#"+t.Name, t.LeadCount)
+		}
+		return nil
+	},
+}
+
+// joinTags renders tags the way the TUI chips do: "#a #b".
+func joinTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(tags, " #")
+}
+
+func init() {
+	leadCmd.AddCommand(leadTagCmd)
+	leadCmd.AddCommand(leadUntagCmd)
+	rootCmd.AddCommand(tagsCmd)
+}
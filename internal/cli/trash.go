@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var leadArchiveCmd = &cobra.Command{
+	Use:   "archive <id>",
+	Short: "Move a lead to the trash",
+	Args:  cobra.ExactArgs(1),
+	RunE: leadTrashAction("Archived", func(ctx context.Context, r *db.Repo, id int64) error {
+		return r.ArchiveLead(ctx, id)
+	}),
+}
+
+var leadRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a lead from the trash",
+	Args:  cobra.ExactArgs(1),
+	RunE: leadTrashAction("Restored", func(ctx context.Context, r *db.Repo, id int64) error {
+		return r.RestoreLead(ctx, id)
+	}),
+}
+
+var leadPurgeCmd = &cobra.Command{
+	Use:   "purge <id>",
+	Short: "Permanently delete an archived lead with its notes and tasks",
+	Args:  cobra.ExactArgs(1),
+	RunE: leadTrashAction("Purged", func(ctx context.Context, r *db.Repo, id int64) error {
+		return r.PurgeLead(ctx, id)
+	}),
+}
+
+var leadTrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List archived leads",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		items, err := a.Repo.ListArchivedLeads(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			fmt.Println("Trash is empty.")
+			return nil
+		}
+		for _, l := range items {
+			printLead(l)
+		}
+		return nil
+	},
+}
+
+func leadTrashAction(verb string, fn func(context.Context, *db.Repo, int64) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+
+		a, err := openApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		if err := fn(cmd.Context(), a.Repo, id); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s lead #%d\n", verb, id)
+		return nil
+	}
+}
+
+func init() {
+	leadCmd.AddCommand(leadArchiveCmd)
+	leadCmd.AddCommand(leadRestoreCmd)
+	leadCmd.AddCommand(leadPurgeCmd)
+	leadCmd.AddCommand(leadTrashCmd)
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Open the interactive pipeline board (default with no command)",
+	RunE:  runTUI,
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	a, err := openApp(cmd)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	p := tea.NewProgram(a.Model(), tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
@@ -0,0 +1,55 @@
+// Package contentline writes the content lines vCard (RFC 6350) and
+// iCalendar (RFC 5545) share: escaped text values on CRLF-terminated lines
+// folded at 75 octets.
+package contentline
+
+import (
+	"io"
+	"strings"
+)
+
+var escaper = strings.NewReplacer(`\`, `\\`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`, ",", `\,`, ";", `\;`)
+
+// Escape escapes a text value: backslashes, commas and semicolons, and any
+// line break (CRLF, LF or a lone CR) as \n.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// Writer writes folded lines. After a write fails it writes nothing more
+// and Err reports the failure, so callers can check once at the end.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+// NewWriter writes lines to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Line writes s as one CRLF-terminated line folded at 75 octets, never
+// splitting a UTF-8 sequence.
+func (lw *Writer) Line(s string) {
+	if lw.err != nil {
+		return
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	b.WriteString("\r\n")
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
+
+// Err is the first write error, if any.
+func (lw *Writer) Err() error {
+	return lw.err
+}
@@ -40,5 +40,11 @@ func (d *DB) Close() error {
 }
 
 func (d *DB) Migrate(ctx context.Context) error {
-	return runMigrations(ctx, d.DB)
+	if err := dropSearchTriggers(ctx, d.DB); err != nil {
+		return err
+	}
+	if err := runMigrations(ctx, d.DB); err != nil {
+		return err
+	}
+	return setupSearch(ctx, d.DB)
 }
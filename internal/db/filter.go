@@ -0,0 +1,290 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter is a parsed smart-list query such as
+//
+//	type:buyer source:zillow stage:Contacted stale:>14d has:open-task
+//
+// Custom fields are matched with field:NAME OP VALUE, where OP is one of
+// = < > <= >= or ~ (contains); field:NAME alone means the field is set.
+//
+// Terms are ANDed; a leading "-" (or "!") negates one. Values with spaces are
+// quoted (stage:"Appointment Set"). Anything that is not key:value, including
+// a whole term in quotes, is free text, matched through SearchLeads.
+type Filter struct {
+	Text  string // free-text part, if any
+	conds []string
+	args  []any
+}
+
+// IsEmpty reports whether f matches every active lead.
+func (f Filter) IsEmpty() bool { return f.Text == "" && len(f.conds) == 0 }
+
+// HasConditions reports whether f has any key:value terms.
+func (f Filter) HasConditions() bool { return len(f.conds) > 0 }
+
+// FilterKeys lists the supported keys, for help text.
+var FilterKeys = []string{
+	"type:", "source:", "stage:", "pipeline:", "status:", "tag:",
+	"stale:>14d", "age:<7d", "has:open-task|overdue-task|notes|follow-up|follow-up-due",
+	"field:name>=value", "property:address-or-mls",
+}
+
+// ParseFilter parses the query language described on Filter. Malformed
+// terms (an unknown key, a missing value, an unclosed quote) are errors
+// rather than being dropped, which would widen the query.
+func ParseFilter(q string) (Filter, error) {
+	var f Filter
+	var text []string
+
+	toks, err := splitQuery(q)
+	if err != nil {
+		return Filter{}, err
+	}
+	for _, tok := range toks {
+		neg := false
+		body := tok
+		if (strings.HasPrefix(body, "-") || strings.HasPrefix(body, "!")) && strings.Contains(body, ":") {
+			neg, body = true, body[1:]
+		}
+
+		k, v, ok := strings.Cut(body, ":")
+		if ok && k == "" {
+			return Filter{}, fmt.Errorf("%q: missing filter name before \":\"", tok)
+		}
+		if !ok || !isWord(k) {
+			text = append(text, unquote(tok))
+			continue
+		}
+		v = unquote(v)
+		if v == "" {
+			return Filter{}, fmt.Errorf("%s: missing value", k)
+		}
+
+		cond, args, err := filterCond(strings.ToLower(k), v)
+		if err != nil {
+			return Filter{}, err
+		}
+		if neg {
+			cond = "NOT (" + cond + ")"
+		}
+		f.conds = append(f.conds, cond)
+		f.args = append(f.args, args...)
+	}
+
+	f.Text = strings.Join(text, " ")
+	return f, nil
+}
+
+func filterCond(key, v string) (string, []any, error) {
+	lv := strings.ToLower(v)
+	switch key {
+	case "type":
+		return "l.lead_type = ?", []any{lv}, nil
+	case "source":
+		return "lower(l.source) LIKE ?", []any{"%" + lv + "%"}, nil
+	case "stage":
+		return "lower(s.name) = ?", []any{lv}, nil
+	case "pipeline":
+		return "lower(p.name) = ?", []any{lv}, nil
+	case "status":
+		return "l.status = ?", []any{lv}, nil
+	case "tag":
+		return "EXISTS (SELECT 1 FROM lead_tags lt JOIN tags tg ON tg.id = lt.tag_id WHERE lt.lead_id = l.id AND tg.name = ?)", []any{strings.TrimPrefix(v, "#")}, nil
+	case "field":
+		return fieldCond(v)
+	case "property":
+		return "EXISTS (SELECT 1 FROM lead_properties lp JOIN properties pr ON pr.id = lp.property_id WHERE lp.lead_id = l.id AND (lower(pr.address) LIKE ? OR lower(pr.mls_number) = ?))", []any{"%" + lv + "%", lv}, nil
+	case "stale", "age":
+		op, days, err := parseDays(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", key, err)
+		}
+		col := "l.updated_at"
+		if key == "age" {
+			col = "l.created_at"
+		}
+		return fmt.Sprintf("julianday('now') - julianday(%s) %s ?", col, op), []any{days}, nil
+	case "has":
+		switch lv {
+		case "open-task":
+			return "EXISTS (SELECT 1 FROM tasks t WHERE t.lead_id = l.id AND t.status = 'open')", nil, nil
+		case "overdue-task":
+			return "EXISTS (SELECT 1 FROM tasks t WHERE t.lead_id = l.id AND t.status = 'open' AND t.due_date <> '' AND t.due_date < date('now', 'localtime'))", nil, nil
+		case "notes", "note":
+			return "EXISTS (SELECT 1 FROM notes n WHERE n.lead_id = l.id)", nil, nil
+		case "follow-up":
+			return "COALESCE(l.next_follow_up, '') <> ''", nil, nil
+		case "follow-up-due":
+			return "COALESCE(l.next_follow_up, '') <> '' AND l.next_follow_up <= date('now', 'localtime')", nil, nil
+		}
+		return "", nil, fmt.Errorf("has: unknown value %q", v)
+	}
+	return "", nil, fmt.Errorf("unknown filter %q (try %s)", key+":", strings.Join(FilterKeys, " "))
+}
+
+// fieldCond builds the condition for field:NAME[OP VALUE]. Values that read
+// as amounts ("400k") compare numerically, anything else as text, which also
+// orders YYYY-MM-DD dates correctly.
+func fieldCond(v string) (string, []any, error) {
+	const base = "EXISTS (SELECT 1 FROM lead_field_values fv JOIN custom_fields cf ON cf.id = fv.field_id WHERE fv.lead_id = l.id AND cf.name = ?"
+
+	i := strings.IndexAny(v, "<>=~")
+	if i < 0 {
+		return base + ")", []any{strings.TrimSpace(v)}, nil
+	}
+	name, rest := strings.TrimSpace(v[:i]), v[i:]
+	op := rest[:1]
+	if len(rest) > 1 && rest[1] == '=' && (op == "<" || op == ">") {
+		op = rest[:2]
+	}
+	val := strings.TrimSpace(rest[len(op):])
+	if name == "" || val == "" {
+		return "", nil, fmt.Errorf("field: use field:NAME>=VALUE (got %q)", v)
+	}
+
+	if op == "~" {
+		return base + " AND lower(fv.value) LIKE ?)", []any{name, "%" + strings.ToLower(val) + "%"}, nil
+	}
+	if n, err := ParseAmount(val); err == nil {
+		return base + " AND CAST(fv.value AS REAL) " + op + " ?)", []any{name, n}, nil
+	}
+	if op == "=" {
+		return base + " AND lower(fv.value) = ?)", []any{name, strings.ToLower(val)}, nil
+	}
+	return base + " AND fv.value " + op + " ?)", []any{name, val}, nil
+}
+
+// parseDays reads ">14d", "<2w", "30" (days; bare means ">=") into an SQL
+// operator and a day count.
+func parseDays(v string) (string, float64, error) {
+	op := ">="
+	for _, p := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(v, p) {
+			op, v = p, v[len(p):]
+			break
+		}
+	}
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(v, "d"):
+		v = strings.TrimSuffix(v, "d")
+	case strings.HasSuffix(v, "w"):
+		v, mult = strings.TrimSuffix(v, "w"), 7
+	case strings.HasSuffix(v, "m"):
+		v, mult = strings.TrimSuffix(v, "m"), 30
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return "", 0, fmt.Errorf("bad duration %q (use e.g. >14d, <2w)", v)
+	}
+	return op, n * mult, nil
+}
+
+// splitQuery splits on whitespace, keeping double-quoted runs together.
+func splitQuery(q string) ([]string, error) {
+	var out []string
+	var cur strings.Builder
+	inQuote := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuote:
+			if cur.Len() > 0 {
+				out = append(out, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unclosed quote in %q", q)
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out, nil
+}
+
+func unquote(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, `"`, ""))
+}
+
+func isWord(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryLeads runs a smart-list query: key:value terms filter active leads,
+// and any free text ranks them through SearchLeads.
+func (r *Repo) QueryLeads(ctx context.Context, q string) ([]SearchHit, error) {
+	f, err := ParseFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	return r.FilterLeads(ctx, f)
+}
+
+// FilterLeads applies a parsed Filter. Results are search-ranked when f has
+// free text, otherwise most recently updated first.
+func (r *Repo) FilterLeads(ctx context.Context, f Filter) ([]SearchHit, error) {
+	var hits []SearchHit
+	if f.Text != "" {
+		var err error
+		if hits, err = r.SearchLeads(ctx, f.Text); err != nil {
+			return nil, err
+		}
+		if !f.HasConditions() {
+			return hits, nil
+		}
+	}
+
+	where := append([]string{"l.archived_at IS NULL"}, f.conds...)
+	rows, err := r.db.QueryContext(ctx, leadSelect+`
+WHERE `+strings.Join(where, "\n  AND ")+`
+ORDER BY l.updated_at DESC, l.id DESC
+`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	leads, err := scanLeads(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Text == "" {
+		out := make([]SearchHit, 0, len(leads))
+		for _, l := range leads {
+			out = append(out, SearchHit{Lead: l, Rank: len(out)})
+		}
+		return out, nil
+	}
+
+	// Keep the search ranking, dropping hits the conditions rule out.
+	keep := make(map[int64]bool, len(leads))
+	for _, l := range leads {
+		keep[l.ID] = true
+	}
+	out := hits[:0]
+	for _, h := range hits {
+		if keep[h.Lead.ID] {
+			h.Rank = len(out)
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
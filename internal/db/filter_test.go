@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		q        string
+		wantText string
+		wantCond []string // substrings of each condition, in order
+		wantArgs []any
+	}{
+		{"", "", nil, nil},
+		{"type:Buyer", "", []string{"l.lead_type = ?"}, []any{"buyer"}},
+		{"source:zillow", "", []string{"lower(l.source) LIKE ?"}, []any{"%zillow%"}},
+		{"stage:Contacted", "", []string{"lower(s.name) = ?"}, []any{"contacted"}},
+		{`stage:"Appointment Set"`, "", []string{"lower(s.name) = ?"}, []any{"appointment set"}},
+		{`"stage:Appointment Set"`, "stage:Appointment Set", nil, nil}, // a quoted term is free text
+		{"pipeline:Seller", "", []string{"lower(p.name) = ?"}, []any{"seller"}},
+		{"status:new", "", []string{"l.status = ?"}, []any{"new"}},
+		{"tag:first-time-buyer", "", []string{"tg.name = ?"}, []any{"first-time-buyer"}},
+		{"tag:#vip", "", []string{"tg.name = ?"}, []any{"vip"}},
+		{`tag:"open house"`, "", []string{"tg.name = ?"}, []any{"open house"}},
+		{"property:MLS123", "", []string{"lower(pr.mls_number) = ?"}, []any{"%mls123%", "mls123"}},
+		{`property:"12 Oak St"`, "", []string{"lower(pr.address) LIKE ?"}, []any{"%12 oak st%", "12 oak st"}},
+		{"stale:>14d", "", []string{"julianday(l.updated_at) > ?"}, []any{14.0}},
+		{"stale:<2w", "", []string{"julianday(l.updated_at) < ?"}, []any{14.0}},
+		{"stale:30", "", []string{"julianday(l.updated_at) >= ?"}, []any{30.0}},
+		{"age:<=1m", "", []string{"julianday(l.created_at) <= ?"}, []any{30.0}},
+		{"has:open-task", "", []string{"t.status = 'open'"}, nil},
+		{"has:overdue-task", "", []string{"t.due_date < date('now', 'localtime')"}, nil},
+		{"has:notes", "", []string{"FROM notes n"}, nil},
+		{"has:follow-up", "", []string{"COALESCE(l.next_follow_up, '') <> ''"}, nil},
+		{"has:follow-up-due", "", []string{"l.next_follow_up <= date('now', 'localtime')"}, nil},
+		{"field:move-by", "", []string{"cf.name = ?)"}, []any{"move-by"}},
+		{`field:"pre-approval>=400k"`, "", []string{"CAST(fv.value AS REAL) >= ?"}, []any{"pre-approval", 400000.0}},
+		{"field:budget<500000", "", []string{"CAST(fv.value AS REAL) < ?"}, []any{"budget", 500000.0}},
+		{"field:district=Lincoln", "", []string{"lower(fv.value) = ?"}, []any{"district", "lincoln"}},
+		{`field:"move by>2026-06-01"`, "", []string{"fv.value > ?"}, []any{"move by", "2026-06-01"}},
+		{"field:notes~Pool", "", []string{"lower(fv.value) LIKE ?"}, []any{"notes", "%pool%"}},
+		{"-type:seller", "", []string{"NOT (l.lead_type = ?)"}, []any{"seller"}},
+		{"!has:notes", "", []string{"NOT (EXISTS (SELECT 1 FROM notes n"}, nil},
+		{"type:buyer stale:>14d", "", []string{"l.lead_type = ?", "julianday(l.updated_at) > ?"}, []any{"buyer", 14.0}},
+		{"jane", "jane", nil, nil},
+		{`"jane doe" type:buyer`, "jane doe", []string{"l.lead_type = ?"}, []any{"buyer"}},
+		{"555-1234 -pending", "555-1234 -pending", nil, nil},
+		{"call at 10:30", "call at 10:30", nil, nil},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.q)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.q, err)
+			continue
+		}
+		if f.Text != tt.wantText {
+			t.Errorf("ParseFilter(%q).Text = %q, want %q", tt.q, f.Text, tt.wantText)
+		}
+		if len(f.conds) != len(tt.wantCond) {
+			t.Errorf("ParseFilter(%q) conditions = %q, want %d", tt.q, f.conds, len(tt.wantCond))
+			continue
+		}
+		for i, want := range tt.wantCond {
+			if !strings.Contains(f.conds[i], want) {
+				t.Errorf("ParseFilter(%q) condition %d = %q, want it to contain %q", tt.q, i, f.conds[i], want)
+			}
+		}
+		if len(f.args) != 0 || len(tt.wantArgs) != 0 {
+			if !reflect.DeepEqual(f.args, tt.wantArgs) {
+				t.Errorf("ParseFilter(%q) args = %#v, want %#v", tt.q, f.args, tt.wantArgs)
+			}
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, q := range []string{
+		"type:",
+		`type:""`,
+		"sorce:zillow",
+		"type:buyer sorce:zillow",
+		":buyer",
+		"-:buyer",
+		`stage:"Appointment Set`,
+		`"jane doe`,
+		"has:everything",
+		"stale:14days",
+		"stale:>d",
+		"stale:>-5d",
+		"age:soon",
+		`field:">=400k"`,
+		"field:budget>=",
+	} {
+		if f, err := ParseFilter(q); err == nil {
+			t.Errorf("ParseFilter(%q) = %+v, want an error", q, f)
+		}
+	}
+}
+
+// TestQueryLeads runs filters against a database, so every condition is
+// also checked to be valid SQL over leadSelect.
+func TestQueryLeads(t *testing.T) {
+	ctx := context.Background()
+	d, err := Open(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if err := d.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRepo(d)
+
+	jane, err := r.CreateLead(ctx, Lead{FullName: "Jane Buyer", LeadType: "buyer", Source: "Zillow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sam, err := r.CreateLead(ctx, Lead{FullName: "Sam Seller", LeadType: "seller", Source: "Referral"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.TagLead(ctx, jane, "vip"); err != nil {
+		t.Fatal(err)
+	}
+	budget, err := r.CreateCustomField(ctx, "budget", "money", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetLeadFields(ctx, jane, map[int64]string{budget: "450000"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CreateTask(ctx, Task{LeadID: sam, Title: "Call"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		q    string
+		want []int64
+	}{
+		{"type:buyer", []int64{jane}},
+		{"-type:buyer", []int64{sam}},
+		{"source:zill", []int64{jane}},
+		{"pipeline:seller", []int64{sam}},
+		{"stage:new type:seller", []int64{sam}},
+		{"status:new tag:vip", []int64{jane}},
+		{"field:budget>=400k", []int64{jane}},
+		{"field:budget<400k", nil},
+		{"field:budget", []int64{jane}},
+		{"has:open-task", []int64{sam}},
+		{"has:overdue-task", nil},
+		{"has:notes", nil},
+		{"has:follow-up", nil},
+		{"has:follow-up-due", nil},
+		{"property:oak", nil},
+		{"stale:>14d", nil},
+		{"age:<1d", []int64{jane, sam}},
+	}
+	for _, tt := range tests {
+		hits, err := r.QueryLeads(ctx, tt.q)
+		if err != nil {
+			t.Errorf("QueryLeads(%q): %v", tt.q, err)
+			continue
+		}
+		var got []int64
+		for _, h := range hits {
+			got = append(got, h.Lead.ID)
+		}
+		if !sameIDs(got, tt.want) {
+			t.Errorf("QueryLeads(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func sameIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[int64]int{}
+	for _, id := range a {
+		seen[id]++
+	}
+	for _, id := range b {
+		if seen[id] == 0 {
+			return false
+		}
+		seen[id]--
+	}
+	return true
+}
@@ -12,6 +12,34 @@ import (
 //go:embed migrations/*.sql
 var migrationFS embed.FS
 
+// migrationHooks run Go code after a migration's SQL, in the same
+// transaction, for data changes SQL alone cannot express.
+var migrationHooks = map[string]func(context.Context, *sql.Tx) error{
+	"006_pipelines":          checkLeadPipelines,
+	"016_normalize_contacts": normalizeContactsMigration,
+}
+
+// checkLeadPipelines fails a migration that leaves a lead in the pipeline
+// of another lead type while its own type has one.
+func checkLeadPipelines(ctx context.Context, tx *sql.Tx) error {
+	var n int
+	var example sql.NullString
+	if err := tx.QueryRowContext(ctx, `
+SELECT COUNT(*), MIN('#' || l.id || ' ' || l.lead_type || ' in ' || p.name)
+FROM leads l
+JOIN stages s ON s.id = l.stage_id
+JOIN pipelines p ON p.id = s.pipeline_id
+WHERE p.lead_type NOT IN ('', l.lead_type)
+  AND EXISTS (SELECT 1 FROM pipelines own WHERE own.lead_type = l.lead_type)
+`).Scan(&n, &example); err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%d lead(s) left in another lead type's pipeline (e.g. %s)", n, example.String)
+	}
+	return nil
+}
+
 func runMigrations(ctx context.Context, db *sql.DB) error {
 	if _, err := db.ExecContext(ctx, `
 CREATE TABLE IF NOT EXISTS schema_migrations (
@@ -64,6 +92,12 @@ CREATE TABLE IF NOT EXISTS schema_migrations (
 			_ = tx.Rollback()
 			return fmt.Errorf("migration %s failed: %w", f, err)
 		}
+		if hook := migrationHooks[version]; hook != nil {
+			if err := hook(ctx, tx); err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("migration %s failed: %w", f, err)
+			}
+		}
 		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations(version) VALUES (?)`, version); err != nil {
 			_ = tx.Rollback()
 			return err
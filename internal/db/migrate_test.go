@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openBefore opens a database migrated only up to (not including) version,
+// the way an older release left it.
+func openBefore(t *testing.T, version string) *DB {
+	t.Helper()
+	ctx := context.Background()
+	d, err := Open(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	if _, err := d.ExecContext(ctx, `CREATE TABLE schema_migrations (
+  version TEXT PRIMARY KEY,
+  applied_at TEXT NOT NULL DEFAULT (datetime('now'))
+)`); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		v := strings.TrimSuffix(e.Name(), ".sql")
+		if v >= version {
+			break
+		}
+		b, err := migrationFS.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.ExecContext(ctx, string(b)); err != nil {
+			t.Fatalf("%s: %v", v, err)
+		}
+		if _, err := d.ExecContext(ctx, `INSERT INTO schema_migrations(version) VALUES (?)`, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return d
+}
+
+// leadPlacement is the pipeline and stage a lead sits on.
+func leadPlacement(t *testing.T, d *DB, id int64) (pipeline, stage string) {
+	t.Helper()
+	if err := d.QueryRowContext(context.Background(), `
+SELECT p.name, s.name FROM leads l
+JOIN stages s ON s.id = l.stage_id
+JOIN pipelines p ON p.id = s.pipeline_id
+WHERE l.id = ?`, id).Scan(&pipeline, &stage); err != nil {
+		t.Fatal(err)
+	}
+	return pipeline, stage
+}
+
+func TestMigratePipelinesMovesLeadsByType(t *testing.T) {
+	ctx := context.Background()
+	d := openBefore(t, "006_pipelines")
+
+	ids := map[string]int64{}
+	for _, l := range []struct{ name, typ, stage string }{
+		{"Bea Buyer", "buyer", "Appointment Set"},
+		{"Sal Seller", "seller", "Contacted"},
+		{"Sid Seller", "seller", "Appointment Set"},
+		{"Rae Rental", "rental", "Contacted"},
+		{"Ray Rental", "rental", "Under Contract"},
+		{"Oli Other", "other", "New"},
+	} {
+		res, err := d.ExecContext(ctx, `INSERT INTO leads(full_name, lead_type, stage_id)
+VALUES (?, ?, (SELECT id FROM stages WHERE name = ?))`, l.name, l.typ, l.stage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[l.name], _ = res.LastInsertId()
+	}
+	if err := d.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ name, pipeline, stage string }{
+		{"Bea Buyer", "Buyer", "Appointment Set"},
+		{"Sal Seller", "Seller", "Contacted"},
+		{"Sid Seller", "Seller", "New"},
+		{"Rae Rental", "Rental", "Contacted"},
+		{"Ray Rental", "Rental", "New"},
+		{"Oli Other", "Buyer", "New"},
+	}
+	for _, tt := range tests {
+		if p, s := leadPlacement(t, d, ids[tt.name]); p != tt.pipeline || s != tt.stage {
+			t.Errorf("%s is in %s/%s, want %s/%s", tt.name, p, s, tt.pipeline, tt.stage)
+		}
+	}
+}
+
+// TestMigrateRepairsRentalLeads covers databases that ran 006 before it
+// moved rentals.
+func TestMigrateRepairsRentalLeads(t *testing.T) {
+	ctx := context.Background()
+	d := openBefore(t, "020_lead_type_pipelines")
+
+	ids := map[string]int64{}
+	for _, l := range []struct{ name, typ, pipeline, stage string }{
+		{"Rae Rental", "rental", "Buyer", "Contacted"},
+		{"Ray Rental", "rental", "Buyer", "Appointment Set"},
+		{"Bea Buyer", "buyer", "Buyer", "Contacted"},
+	} {
+		res, err := d.ExecContext(ctx, `INSERT INTO leads(full_name, lead_type, stage_id)
+VALUES (?, ?, (SELECT s.id FROM stages s JOIN pipelines p ON p.id = s.pipeline_id WHERE p.name = ? AND s.name = ?))`,
+			l.name, l.typ, l.pipeline, l.stage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[l.name], _ = res.LastInsertId()
+	}
+	if err := d.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ name, pipeline, stage string }{
+		{"Rae Rental", "Rental", "Contacted"},
+		{"Ray Rental", "Rental", "New"},
+		{"Bea Buyer", "Buyer", "Contacted"},
+	}
+	for _, tt := range tests {
+		if p, s := leadPlacement(t, d, ids[tt.name]); p != tt.pipeline || s != tt.stage {
+			t.Errorf("%s is in %s/%s, want %s/%s", tt.name, p, s, tt.pipeline, tt.stage)
+		}
+	}
+}
+
+func TestCheckLeadPipelines(t *testing.T) {
+	ctx := context.Background()
+	d, err := Open(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if err := d.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ExecContext(ctx, `INSERT INTO leads(full_name, lead_type, stage_id)
+VALUES ('Rae Rental', 'rental', (SELECT id FROM stages WHERE pipeline_id = 1 ORDER BY sort LIMIT 1))`); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := checkLeadPipelines(ctx, tx); err == nil || !strings.Contains(err.Error(), "rental in Buyer") {
+		t.Errorf("checkLeadPipelines = %v, want the rental lead in Buyer reported", err)
+	}
+}
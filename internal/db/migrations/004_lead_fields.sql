@@ -0,0 +1,8 @@
+PRAGMA foreign_keys = ON;
+
+-- Fields the CLI has always wanted on a lead, now on the one real leads table.
+ALTER TABLE leads ADD COLUMN status TEXT NOT NULL DEFAULT 'new'; -- new|contacted|nurture|hot|cold|closed|dead
+ALTER TABLE leads ADD COLUMN next_follow_up TEXT;                -- YYYY-MM-DD (optional)
+ALTER TABLE leads ADD COLUMN notes TEXT NOT NULL DEFAULT '';     -- free-text background, separate from the notes timeline
+
+CREATE INDEX IF NOT EXISTS idx_leads_follow_up ON leads(next_follow_up);
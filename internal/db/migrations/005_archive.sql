@@ -0,0 +1,6 @@
+PRAGMA foreign_keys = ON;
+
+-- Soft delete: archived leads sit in the trash until restored or purged.
+ALTER TABLE leads ADD COLUMN archived_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_leads_archived ON leads(archived_at);
@@ -0,0 +1,53 @@
+PRAGMA foreign_keys = ON;
+
+-- Each pipeline owns its stages; a lead belongs to the pipeline of its stage.
+CREATE TABLE IF NOT EXISTS pipelines (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE,
+  lead_type TEXT NOT NULL DEFAULT '', -- new leads of this type land here
+  sort INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+INSERT INTO pipelines(id, name, lead_type, sort) VALUES
+ (1, 'Buyer', 'buyer', 10),
+ (2, 'Seller', 'seller', 20),
+ (3, 'Rental', 'rental', 30);
+
+-- SQLite cannot add a REFERENCES column with a non-NULL default, so existing
+-- stages are backfilled into the Buyer pipeline right after.
+ALTER TABLE stages ADD COLUMN pipeline_id INTEGER REFERENCES pipelines(id) ON DELETE CASCADE;
+UPDATE stages SET pipeline_id = 1;
+
+CREATE INDEX IF NOT EXISTS idx_stages_pipeline ON stages(pipeline_id, sort);
+
+INSERT INTO stages(pipeline_id, name, sort) VALUES
+ (2, 'New', 10),
+ (2, 'Contacted', 20),
+ (2, 'Listing Appointment', 30),
+ (2, 'Listed', 40),
+ (2, 'Under Contract', 50),
+ (2, 'Closed', 60),
+ (3, 'New', 10),
+ (3, 'Contacted', 20),
+ (3, 'Showing', 30),
+ (3, 'Application', 40),
+ (3, 'Lease Signed', 50);
+
+-- Sellers move to the Seller pipeline, keeping their stage where the names match.
+UPDATE leads
+SET stage_id = COALESCE(
+  (SELECT s2.id FROM stages s1
+   JOIN stages s2 ON lower(s2.name) = lower(s1.name) AND s2.pipeline_id = 2
+   WHERE s1.id = leads.stage_id),
+  (SELECT id FROM stages WHERE pipeline_id = 2 ORDER BY sort, id LIMIT 1))
+WHERE lead_type = 'seller';
+
+-- Rentals move to the Rental pipeline the same way.
+UPDATE leads
+SET stage_id = COALESCE(
+  (SELECT s2.id FROM stages s1
+   JOIN stages s2 ON lower(s2.name) = lower(s1.name) AND s2.pipeline_id = 3
+   WHERE s1.id = leads.stage_id),
+  (SELECT id FROM stages WHERE pipeline_id = 3 ORDER BY sort, id LIMIT 1))
+WHERE lead_type = 'rental';
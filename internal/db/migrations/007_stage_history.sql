@@ -0,0 +1,23 @@
+PRAGMA foreign_keys = ON;
+
+-- One row per stage a lead enters. Stage names are snapshotted so the
+-- timeline survives stages being renamed or deleted.
+CREATE TABLE IF NOT EXISTS stage_history (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  lead_id INTEGER NOT NULL,
+  from_stage_id INTEGER,          -- NULL when the lead was created
+  from_stage TEXT NOT NULL DEFAULT '',
+  to_stage_id INTEGER NOT NULL,
+  to_stage TEXT NOT NULL,
+  changed_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY(lead_id) REFERENCES leads(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_stage_history_lead ON stage_history(lead_id, changed_at);
+
+-- Existing leads get a starting entry. updated_at is the latest a past move
+-- could have happened, so time-in-stage is never overstated.
+INSERT INTO stage_history(lead_id, to_stage_id, to_stage, changed_at)
+SELECT l.id, l.stage_id, s.name, l.updated_at
+FROM leads l
+JOIN stages s ON s.id = l.stage_id;
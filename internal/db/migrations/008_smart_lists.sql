@@ -0,0 +1,10 @@
+PRAGMA foreign_keys = ON;
+
+-- Saved filter queries, shown as tabs on the Leads view.
+CREATE TABLE IF NOT EXISTS smart_lists (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE,
+  query TEXT NOT NULL,
+  sort INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,22 @@
+PRAGMA foreign_keys = ON;
+
+-- User-defined lead attributes. Values are stored as text in a canonical
+-- form per kind: numbers/money as plain decimals, dates as YYYY-MM-DD.
+CREATE TABLE IF NOT EXISTS custom_fields (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+  kind TEXT NOT NULL CHECK (kind IN ('text','number','money','date','choice')),
+  choices TEXT NOT NULL DEFAULT '', -- comma separated, choice fields only
+  sort INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS lead_field_values (
+  lead_id INTEGER NOT NULL,
+  field_id INTEGER NOT NULL,
+  value TEXT NOT NULL,
+  PRIMARY KEY (lead_id, field_id),
+  FOREIGN KEY(lead_id) REFERENCES leads(id) ON DELETE CASCADE,
+  FOREIGN KEY(field_id) REFERENCES custom_fields(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_lead_field_values_field ON lead_field_values(field_id);
@@ -0,0 +1,29 @@
+PRAGMA foreign_keys = ON;
+
+CREATE TABLE IF NOT EXISTS properties (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  address TEXT NOT NULL,
+  price REAL NOT NULL DEFAULT 0,
+  beds INTEGER NOT NULL DEFAULT 0,
+  baths REAL NOT NULL DEFAULT 0,
+  sqft INTEGER NOT NULL DEFAULT 0,
+  mls_number TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('coming-soon','active','pending','sold','off-market')),
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- How a lead relates to a property: a seller's listing, or a house a buyer
+-- was interested in, toured or offered on. One role per lead/property pair.
+CREATE TABLE IF NOT EXISTS lead_properties (
+  lead_id INTEGER NOT NULL,
+  property_id INTEGER NOT NULL,
+  role TEXT NOT NULL CHECK (role IN ('listing','interested','toured','offered')),
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (lead_id, property_id),
+  FOREIGN KEY(lead_id) REFERENCES leads(id) ON DELETE CASCADE,
+  FOREIGN KEY(property_id) REFERENCES properties(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_lead_properties_property ON lead_properties(property_id);
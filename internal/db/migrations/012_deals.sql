@@ -0,0 +1,25 @@
+PRAGMA foreign_keys = ON;
+
+-- A transaction for a lead. Commission rate is a percentage (3 = 3%).
+CREATE TABLE IF NOT EXISTS deals (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  lead_id INTEGER NOT NULL,
+  property_id INTEGER,
+  price REAL NOT NULL DEFAULT 0,
+  commission_rate REAL NOT NULL DEFAULT 0,
+  side TEXT NOT NULL DEFAULT 'buyer' CHECK (side IN ('buyer','listing','dual')),
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','closed','fell-through')),
+  contract_date TEXT,   -- YYYY-MM-DD
+  expected_close TEXT,  -- YYYY-MM-DD
+  closed_date TEXT,     -- YYYY-MM-DD
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY(lead_id) REFERENCES leads(id) ON DELETE CASCADE,
+  FOREIGN KEY(property_id) REFERENCES properties(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_deals_lead ON deals(lead_id);
+
+-- Moving a lead into a stage with deal_prompt set offers to create a deal.
+ALTER TABLE stages ADD COLUMN deal_prompt INTEGER NOT NULL DEFAULT 0;
+UPDATE stages SET deal_prompt = 1 WHERE lower(name) = 'under contract';
@@ -0,0 +1,18 @@
+-- Chance (0-100%) that a deal in this stage closes, for the weighted forecast.
+ALTER TABLE stages ADD COLUMN win_probability INTEGER NOT NULL DEFAULT 0
+  CHECK (win_probability BETWEEN 0 AND 100);
+
+UPDATE stages SET win_probability = CASE lower(name)
+  WHEN 'new' THEN 5
+  WHEN 'contacted' THEN 10
+  WHEN 'appointment set' THEN 20
+  WHEN 'listing appointment' THEN 25
+  WHEN 'showing' THEN 30
+  WHEN 'active client' THEN 40
+  WHEN 'listed' THEN 50
+  WHEN 'application' THEN 60
+  WHEN 'under contract' THEN 90
+  WHEN 'closed' THEN 100
+  WHEN 'lease signed' THEN 100
+  ELSE 0
+END;
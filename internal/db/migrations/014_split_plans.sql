@@ -0,0 +1,22 @@
+PRAGMA foreign_keys = ON;
+
+-- How GCI is split with the brokerage. agent_split is the agent's share in
+-- percent until the company dollar paid in a cap year reaches annual_cap
+-- (0 = no cap); cap years start on the 1st of cap_start_month. The team lead
+-- override is a percent of what the agent keeps after the brokerage split.
+CREATE TABLE IF NOT EXISTS split_plans (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+  agent_split REAL NOT NULL DEFAULT 100 CHECK (agent_split BETWEEN 0 AND 100),
+  annual_cap REAL NOT NULL DEFAULT 0,
+  cap_start_month INTEGER NOT NULL DEFAULT 1 CHECK (cap_start_month BETWEEN 1 AND 12),
+  transaction_fee REAL NOT NULL DEFAULT 0,
+  team_override REAL NOT NULL DEFAULT 0 CHECK (team_override BETWEEN 0 AND 100),
+  is_default INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- Referral fees are a percent of the deal's GCI paid out before the split.
+ALTER TABLE deals ADD COLUMN referral_pct REAL NOT NULL DEFAULT 0;
+-- NULL uses the default plan.
+ALTER TABLE deals ADD COLUMN split_plan_id INTEGER REFERENCES split_plans(id) ON DELETE SET NULL;
@@ -0,0 +1,37 @@
+PRAGMA foreign_keys = ON;
+
+-- Labelled phones and emails. The primary entry of each kind is mirrored
+-- into leads.phone / leads.email so the board and lists keep one number.
+CREATE TABLE IF NOT EXISTS lead_contacts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  lead_id INTEGER NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('phone','email')),
+  label TEXT NOT NULL DEFAULT '',
+  value TEXT NOT NULL,
+  is_primary INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY(lead_id) REFERENCES leads(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_lead_contacts_lead ON lead_contacts(lead_id, kind);
+
+INSERT INTO lead_contacts(lead_id, kind, value, is_primary)
+SELECT id, 'phone', phone, 1 FROM leads WHERE phone <> '';
+INSERT INTO lead_contacts(lead_id, kind, value, is_primary)
+SELECT id, 'email', email, 1 FROM leads WHERE email <> '';
+
+-- Links between leads, read as "lead's <kind> is related". Spouse and
+-- co-buyer read the same both ways; referred-by and attorney do not.
+CREATE TABLE IF NOT EXISTS lead_relationships (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  lead_id INTEGER NOT NULL,
+  related_id INTEGER NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('spouse','co-buyer','referred-by','attorney')),
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  UNIQUE (lead_id, related_id, kind),
+  CHECK (lead_id <> related_id),
+  FOREIGN KEY(lead_id) REFERENCES leads(id) ON DELETE CASCADE,
+  FOREIGN KEY(related_id) REFERENCES leads(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_lead_relationships_related ON lead_relationships(related_id);
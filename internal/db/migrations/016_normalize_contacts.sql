@@ -0,0 +1,13 @@
+PRAGMA foreign_keys = ON;
+
+-- App-wide preferences, one row per key.
+CREATE TABLE IF NOT EXISTS settings (
+  key TEXT PRIMARY KEY,
+  value TEXT NOT NULL
+);
+
+-- Region used to read phone numbers typed without a country code.
+INSERT OR IGNORE INTO settings(key, value) VALUES ('phone_region', 'US');
+
+-- Existing phones and emails are rewritten to E.164 / validated form by the
+-- Go hook registered for this migration (normalizeContactsMigration).
@@ -0,0 +1,3 @@
+-- Repeating tasks: an RRULE such as FREQ=DAILY;INTERVAL=30. Completing the
+-- task creates the next occurrence; empty for one-off tasks.
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- Time of day (HH:MM, empty for all-day), kind and priority of a task.
+-- Priority: 1 high, 0 normal, -1 low.
+ALTER TABLE tasks ADD COLUMN due_time TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN task_type TEXT NOT NULL DEFAULT ''
+  CHECK (task_type IN ('', 'call', 'text', 'email', 'showing', 'meeting'));
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0
+  CHECK (priority BETWEEN -1 AND 1);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status_priority ON tasks(status, priority, due_date);
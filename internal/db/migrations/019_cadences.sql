@@ -0,0 +1,25 @@
+PRAGMA foreign_keys = ON;
+
+-- Cadences are reusable follow-up plans: applying one to a lead creates a
+-- task for each step, due day_offset days after the start date. A cadence
+-- with auto_source and/or auto_type set is applied to every new lead that
+-- matches all of the ones set.
+CREATE TABLE IF NOT EXISTS cadences (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+  auto_source TEXT NOT NULL DEFAULT '',
+  auto_type TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS cadence_steps (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  cadence_id INTEGER NOT NULL REFERENCES cadences(id) ON DELETE CASCADE,
+  sort INTEGER NOT NULL,
+  day_offset INTEGER NOT NULL CHECK (day_offset >= 0),
+  title TEXT NOT NULL,
+  task_type TEXT NOT NULL DEFAULT ''
+    CHECK (task_type IN ('', 'call', 'text', 'email', 'showing', 'meeting'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_cadence_steps_cadence ON cadence_steps(cadence_id, sort);
@@ -0,0 +1,17 @@
+-- Databases upgraded before 006 moved rentals left them on Buyer stages.
+-- Move them to the first Rental pipeline, keeping their stage where the
+-- names match.
+UPDATE leads
+SET stage_id = COALESCE(
+  (SELECT s2.id FROM stages s1
+   JOIN stages s2 ON lower(s2.name) = lower(s1.name)
+   WHERE s1.id = leads.stage_id
+     AND s2.pipeline_id = (SELECT id FROM pipelines WHERE lead_type = 'rental' ORDER BY sort, id LIMIT 1)
+   ORDER BY s2.sort, s2.id LIMIT 1),
+  (SELECT id FROM stages
+   WHERE pipeline_id = (SELECT id FROM pipelines WHERE lead_type = 'rental' ORDER BY sort, id LIMIT 1)
+   ORDER BY sort, id LIMIT 1))
+WHERE lead_type = 'rental'
+  AND (SELECT p.lead_type FROM stages s JOIN pipelines p ON p.id = s.pipeline_id WHERE s.id = leads.stage_id) = 'buyer'
+  AND EXISTS (SELECT 1 FROM stages
+              WHERE pipeline_id = (SELECT id FROM pipelines WHERE lead_type = 'rental' ORDER BY sort, id LIMIT 1));
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// -------- Phone & email normalization --------
+
+// phoneRegion is what NormalizePhone needs to read a national number: the
+// country calling code and the trunk prefix dialled before it at home
+// ("0" in most of Europe, none in NANP countries, Italy or Spain).
+type phoneRegion struct {
+	code  string
+	trunk string
+}
+
+var phoneRegions = map[string]phoneRegion{
+	"US": {"1", ""}, "CA": {"1", ""}, "PR": {"1", ""},
+	"GB": {"44", "0"}, "IE": {"353", "0"}, "AU": {"61", "0"}, "NZ": {"64", "0"},
+	"DE": {"49", "0"}, "FR": {"33", "0"}, "NL": {"31", "0"}, "BE": {"32", "0"},
+	"CH": {"41", "0"}, "AT": {"43", "0"}, "SE": {"46", "0"}, "FI": {"358", "0"},
+	"IT": {"39", ""}, "ES": {"34", ""}, "PT": {"351", ""}, "NO": {"47", ""}, "DK": {"45", ""},
+	"MX": {"52", ""}, "BR": {"55", "0"}, "IN": {"91", "0"}, "ZA": {"27", "0"},
+	"JP": {"81", "0"}, "SG": {"65", ""}, "PH": {"63", "0"}, "IL": {"972", "0"},
+}
+
+// PhoneRegions lists the region codes NormalizePhone understands.
+func PhoneRegions() []string {
+	out := make([]string, 0, len(phoneRegions))
+	for r := range phoneRegions {
+		out = append(out, r)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NormalizePhone turns a typed phone number into E.164 ("+15551234567").
+// Numbers starting with + (or 00, or 011 in NANP regions) are taken as
+// international; anything else is read as a national number of region.
+func NormalizePhone(raw, region string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	reg, ok := phoneRegions[strings.ToUpper(region)]
+	if !ok {
+		return "", fmt.Errorf("unknown phone region %q", region)
+	}
+
+	var b strings.Builder
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case strings.ContainsRune(" -(). /", r):
+		default:
+			return "", fmt.Errorf("%q is not a phone number", raw)
+		}
+	}
+	d := b.String()
+
+	switch {
+	case strings.HasPrefix(d, "+"):
+		d = d[1:]
+	case reg.code == "1" && strings.HasPrefix(d, "011"):
+		d = d[3:]
+	case strings.HasPrefix(d, "00"):
+		d = d[2:]
+	case reg.code == "1":
+		// NANP: ten digits, optionally with the leading 1.
+		if len(d) == 11 && d[0] == '1' {
+			d = d[1:]
+		}
+		if len(d) != 10 {
+			return "", fmt.Errorf("%q needs ten digits including the area code", raw)
+		}
+		d = "1" + d
+	default:
+		d = reg.code + strings.TrimPrefix(d, reg.trunk)
+	}
+
+	if len(d) < 8 || len(d) > 15 || d[0] == '0' {
+		return "", fmt.Errorf("%q is not a valid phone number", raw)
+	}
+	return "+" + d, nil
+}
+
+// NormalizeEmail checks that raw is a bare address (no display name) with a
+// dotted domain, and lowercases the domain.
+func NormalizeEmail(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	addr, err := mail.ParseAddress(raw)
+	if err != nil || addr.Address != raw {
+		return "", fmt.Errorf("%q is not an email address", raw)
+	}
+	local, domain, _ := strings.Cut(addr.Address, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", fmt.Errorf("%q is missing a domain like example.com", raw)
+	}
+	return local + "@" + strings.ToLower(domain), nil
+}
+
+// FieldError is a rejected lead input, naming the field ("phone", "email")
+// so forms can show it next to the right box.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Err.Error() }
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// NormalizeContact normalizes one phone or email value.
+func NormalizeContact(kind, value, region string) (string, error) {
+	var out string
+	var err error
+	if kind == "phone" {
+		out, err = NormalizePhone(value, region)
+	} else {
+		out, err = NormalizeEmail(value)
+	}
+	if err != nil {
+		return "", &FieldError{Field: kind, Err: err}
+	}
+	return out, nil
+}
+
+// normalizeLeadContact normalizes l.Phone and l.Email in place using the
+// configured phone region. A value equal to the one stored is kept as it
+// is: the normalize migration leaves unparseable values alone, and they must
+// not block edits to a lead's other fields.
+func (r *Repo) normalizeLeadContact(ctx context.Context, l *Lead, stored Lead) error {
+	region, err := r.PhoneRegion(ctx)
+	if err != nil {
+		return err
+	}
+	if l.Phone != stored.Phone || stored.ID == 0 {
+		if l.Phone, err = NormalizeContact("phone", l.Phone, region); err != nil {
+			return err
+		}
+	}
+	if l.Email != stored.Email || stored.ID == 0 {
+		if l.Email, err = NormalizeContact("email", l.Email, region); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeContactsMigration rewrites every stored phone and email in its
+// normalized form, once. Values that cannot be parsed are left alone;
+// entries that become identical on the same lead are collapsed.
+func normalizeContactsMigration(ctx context.Context, tx *sql.Tx) error {
+	region := "US"
+	if err := tx.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'phone_region'`).Scan(&region); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	type contact struct {
+		id, leadID  int64
+		kind, value string
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, lead_id, kind, value FROM lead_contacts ORDER BY is_primary DESC, id ASC`)
+	if err != nil {
+		return err
+	}
+	var all []contact
+	for rows.Next() {
+		var c contact
+		if err := rows.Scan(&c.id, &c.leadID, &c.kind, &c.value); err != nil {
+			rows.Close()
+			return err
+		}
+		all = append(all, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	touched := map[int64]bool{}
+	for _, c := range all {
+		v, err := NormalizeContact(c.kind, c.value, region)
+		if err != nil {
+			v = c.value
+		}
+		key := fmt.Sprint(c.leadID, c.kind, strings.ToLower(v))
+		switch {
+		case seen[key]:
+			_, err = tx.ExecContext(ctx, `DELETE FROM lead_contacts WHERE id = ?`, c.id)
+		case v != c.value:
+			_, err = tx.ExecContext(ctx, `UPDATE lead_contacts SET value = ? WHERE id = ?`, v, c.id)
+		default:
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		seen[key] = true
+		touched[c.leadID] = true
+	}
+	for leadID := range touched {
+		for _, kind := range ContactKinds {
+			if err := syncPrimaryContact(ctx, tx, leadID, kind); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// -------- Task recurrence --------
+
+// Recurrence is the subset of an iCalendar RRULE (RFC 5545) tasks use:
+// FREQ, INTERVAL, an optional COUNT or UNTIL, and BYMONTHDAY. It is stored
+// on the task in RRULE form, e.g. "FREQ=DAILY;INTERVAL=30".
+type Recurrence struct {
+	Freq     string // DAILY|WEEKLY|MONTHLY|YEARLY
+	Interval int
+	Count    int        // occurrences left including this one; 0 = no limit
+	Until    *time.Time // last date an occurrence may fall on
+	MonthDay int        // day of the month a short month moved back; 0 = the due date's
+}
+
+var recurrenceFreqs = map[string]string{
+	"d": "DAILY", "day": "DAILY", "days": "DAILY", "daily": "DAILY",
+	"w": "WEEKLY", "week": "WEEKLY", "weeks": "WEEKLY", "weekly": "WEEKLY",
+	"m": "MONTHLY", "month": "MONTHLY", "months": "MONTHLY", "monthly": "MONTHLY",
+	"y": "YEARLY", "year": "YEARLY", "years": "YEARLY", "yearly": "YEARLY", "annually": "YEARLY",
+}
+
+// ParseRecurrence reads an RRULE ("FREQ=MONTHLY;INTERVAL=3", with or
+// without the "RRULE:" prefix) or a shorthand: "weekly", "quarterly",
+// "every 30 days", "every 2 weeks", "30d", "3m". Empty means no recurrence.
+func ParseRecurrence(s string) (*Recurrence, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	upper := strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	if strings.Contains(upper, "FREQ=") {
+		return parseRRule(upper)
+	}
+
+	in := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	if in == "quarterly" || in == "every quarter" {
+		return &Recurrence{Freq: "MONTHLY", Interval: 3}, nil
+	}
+	in = strings.TrimPrefix(in, "every ")
+	// Split "30 days" / "30days" / "30d" into number and unit.
+	i := strings.IndexFunc(in, func(r rune) bool { return !unicode.IsDigit(r) })
+	n, unit := 1, in
+	if i > 0 {
+		n, _ = strconv.Atoi(in[:i])
+		unit = strings.TrimSpace(in[i:])
+	}
+	freq, ok := recurrenceFreqs[unit]
+	if !ok || n < 1 {
+		return nil, fmt.Errorf("repeat %q: use e.g. weekly, monthly, quarterly, every 30 days, 2w or FREQ=DAILY;INTERVAL=30", s)
+	}
+	return &Recurrence{Freq: freq, Interval: n}, nil
+}
+
+func parseRRule(s string) (*Recurrence, error) {
+	rec := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "FREQ":
+			if v != "DAILY" && v != "WEEKLY" && v != "MONTHLY" && v != "YEARLY" {
+				return nil, fmt.Errorf("RRULE FREQ %q is not supported (use DAILY|WEEKLY|MONTHLY|YEARLY)", v)
+			}
+			rec.Freq = v
+		case "INTERVAL", "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("RRULE %s must be a positive number", k)
+			}
+			if k == "INTERVAL" {
+				rec.Interval = n
+			} else {
+				rec.Count = n
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 31 {
+				return nil, fmt.Errorf("RRULE BYMONTHDAY must be a day of the month (1-31)")
+			}
+			rec.MonthDay = n
+		case "UNTIL":
+			if len(v) < 8 {
+				return nil, fmt.Errorf("RRULE UNTIL %q is not a date", v)
+			}
+			t, err := time.Parse("20060102", v[:8])
+			if err != nil {
+				return nil, fmt.Errorf("RRULE UNTIL %q is not a date", v)
+			}
+			rec.Until = &t
+		default:
+			return nil, fmt.Errorf("RRULE part %q is not supported", part)
+		}
+	}
+	if rec.Freq == "" {
+		return nil, fmt.Errorf("RRULE needs a FREQ")
+	}
+	if rec.Count > 0 && rec.Until != nil {
+		return nil, fmt.Errorf("RRULE cannot have both COUNT and UNTIL")
+	}
+	return rec, nil
+}
+
+// String is the rule in RRULE form, as stored.
+func (r Recurrence) String() string {
+	s := "FREQ=" + r.Freq
+	if r.Interval > 1 {
+		s += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	if r.Count > 0 {
+		s += ";COUNT=" + strconv.Itoa(r.Count)
+	}
+	if r.Until != nil {
+		s += ";UNTIL=" + r.Until.Format("20060102")
+	}
+	if r.MonthDay > 0 {
+		s += ";BYMONTHDAY=" + strconv.Itoa(r.MonthDay)
+	}
+	return s
+}
+
+// Describe reads the rule out for task lists: "every 30 days", "quarterly".
+func (r Recurrence) Describe() string {
+	unit := map[string]string{"DAILY": "day", "WEEKLY": "week", "MONTHLY": "month", "YEARLY": "year"}[r.Freq]
+	var s string
+	switch {
+	case r.Freq == "MONTHLY" && r.Interval == 3:
+		s = "quarterly"
+	case r.Interval <= 1:
+		s = strings.ToLower(r.Freq)
+	default:
+		s = fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	if r.Count > 0 {
+		s += fmt.Sprintf(", %d left", r.Count)
+	}
+	if r.Until != nil {
+		s += " until " + r.Until.Format("2006-01-02")
+	}
+	return s
+}
+
+// DescribeRecurrence reads out a stored rule, or "" for one-off tasks.
+func DescribeRecurrence(rule string) string {
+	r, err := ParseRecurrence(rule)
+	if err != nil || r == nil {
+		return rule
+	}
+	return r.Describe()
+}
+
+// step moves d on by n intervals. Months and years keep the day of the
+// month (MonthDay when set), falling back to the month's last day
+// (Jan 31 → Feb 28).
+func (r Recurrence) step(d time.Time, n int) time.Time {
+	switch r.Freq {
+	case "DAILY":
+		return d.AddDate(0, 0, n*r.Interval)
+	case "WEEKLY":
+		return d.AddDate(0, 0, 7*n*r.Interval)
+	}
+	months := n * r.Interval
+	if r.Freq == "YEARLY" {
+		months *= 12
+	}
+	first := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location()).AddDate(0, months, 0)
+	last := first.AddDate(0, 1, -1).Day()
+	day := d.Day()
+	if r.MonthDay > 0 {
+		day = r.MonthDay
+	}
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+// Next is the occurrence that follows one due on due, when it is completed
+// on done: one interval on, skipping occurrences already in the past so a
+// late completion does not leave a backlog of overdue copies. ok is false
+// when COUNT or UNTIL has run out; the returned rule is the one the next
+// occurrence carries (its COUNT counts down, and a day of the month a short
+// month moved back is kept in BYMONTHDAY so Jan 31 → Feb 28 → Mar 31).
+func (r Recurrence) Next(due, done time.Time) (next time.Time, rule Recurrence, ok bool) {
+	if r.Count == 1 {
+		return time.Time{}, r, false
+	}
+	today := time.Date(done.Year(), done.Month(), done.Day(), 0, 0, 0, 0, due.Location())
+	next = r.step(due, 1)
+	for n := 2; next.Before(today); n++ {
+		next = r.step(due, n)
+	}
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, r, false
+	}
+	if r.Count > 0 {
+		r.Count--
+	}
+	if (r.Freq == "MONTHLY" || r.Freq == "YEARLY") && r.MonthDay == 0 && next.Day() != due.Day() {
+		r.MonthDay = due.Day()
+	}
+	return next, r, true
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	return mustParseDate(s)
+}
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // RRULE form; "" for no recurrence
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"daily", "FREQ=DAILY"},
+		{"weekly", "FREQ=WEEKLY"},
+		{"Monthly", "FREQ=MONTHLY"},
+		{"annually", "FREQ=YEARLY"},
+		{"quarterly", "FREQ=MONTHLY;INTERVAL=3"},
+		{"every quarter", "FREQ=MONTHLY;INTERVAL=3"},
+		{"every 30 days", "FREQ=DAILY;INTERVAL=30"},
+		{"every 2 weeks", "FREQ=WEEKLY;INTERVAL=2"},
+		{"every   2   weeks", "FREQ=WEEKLY;INTERVAL=2"},
+		{"every week", "FREQ=WEEKLY"},
+		{"30d", "FREQ=DAILY;INTERVAL=30"},
+		{"30days", "FREQ=DAILY;INTERVAL=30"},
+		{"2w", "FREQ=WEEKLY;INTERVAL=2"},
+		{"3m", "FREQ=MONTHLY;INTERVAL=3"},
+		{"1y", "FREQ=YEARLY"},
+		{"FREQ=DAILY;INTERVAL=30", "FREQ=DAILY;INTERVAL=30"},
+		{"RRULE:FREQ=WEEKLY", "FREQ=WEEKLY"},
+		{"rrule:freq=monthly;interval=6", "FREQ=MONTHLY;INTERVAL=6"},
+		{"FREQ=MONTHLY;COUNT=3", "FREQ=MONTHLY;COUNT=3"},
+		{"FREQ=WEEKLY;UNTIL=20261231T000000Z", "FREQ=WEEKLY;UNTIL=20261231"},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", "FREQ=MONTHLY;BYMONTHDAY=31"},
+	}
+	for _, tt := range tests {
+		rec, err := ParseRecurrence(tt.in)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q): %v", tt.in, err)
+			continue
+		}
+		got := ""
+		if rec != nil {
+			got = rec.String()
+		}
+		if got != tt.want {
+			t.Errorf("ParseRecurrence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	for _, in := range []string{
+		"fortnightly",
+		"every",
+		"0d",
+		"every 0 weeks",
+		"3x",
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=two",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"FREQ=WEEKLY;UNTIL=2026",
+		"FREQ=WEEKLY;UNTIL=2026-01-01",
+		"FREQ=WEEKLY;BYDAY=MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+	} {
+		if rec, err := ParseRecurrence(in); err == nil {
+			t.Errorf("ParseRecurrence(%q) = %v, want an error", in, rec)
+		}
+	}
+}
+
+func TestRecurrenceDescribe(t *testing.T) {
+	tests := []struct{ rule, want string }{
+		{"", ""},
+		{"FREQ=WEEKLY", "weekly"},
+		{"FREQ=MONTHLY;INTERVAL=3", "quarterly"},
+		{"FREQ=DAILY;INTERVAL=30", "every 30 days"},
+		{"FREQ=MONTHLY;INTERVAL=6;COUNT=2", "every 6 months, 2 left"},
+		{"FREQ=YEARLY;UNTIL=20301231", "yearly until 2030-12-31"},
+		{"nonsense", "nonsense"},
+	}
+	for _, tt := range tests {
+		if got := DescribeRecurrence(tt.rule); got != tt.want {
+			t.Errorf("DescribeRecurrence(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceStep(t *testing.T) {
+	tests := []struct {
+		rule string
+		from string
+		n    int
+		want string
+	}{
+		{"FREQ=DAILY;INTERVAL=30", "2026-01-15", 1, "2026-02-14"},
+		{"FREQ=WEEKLY;INTERVAL=2", "2026-12-24", 1, "2027-01-07"},
+		{"FREQ=WEEKLY", "2026-01-01", 3, "2026-01-22"},
+		{"FREQ=MONTHLY", "2026-01-31", 1, "2026-02-28"},
+		{"FREQ=MONTHLY", "2028-01-31", 1, "2028-02-29"},
+		{"FREQ=MONTHLY", "2026-01-31", 2, "2026-03-31"},
+		{"FREQ=MONTHLY", "2026-01-31", 3, "2026-04-30"},
+		{"FREQ=MONTHLY;INTERVAL=3", "2026-11-30", 1, "2027-02-28"},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", "2026-02-28", 1, "2026-03-31"},
+		{"FREQ=MONTHLY;BYMONTHDAY=30", "2026-01-30", 1, "2026-02-28"},
+		{"FREQ=YEARLY", "2028-02-29", 1, "2029-02-28"},
+		{"FREQ=YEARLY", "2028-02-29", 4, "2032-02-29"},
+	}
+	for _, tt := range tests {
+		rec, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseRecurrence(%q): %v", tt.rule, err)
+		}
+		if got := rec.step(date(tt.from), tt.n).Format("2006-01-02"); got != tt.want {
+			t.Errorf("%s: step(%s, %d) = %s, want %s", tt.rule, tt.from, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		due      string
+		done     string
+		want     string // "" when the series has ended
+		wantRule string
+	}{
+		{"on time", "FREQ=WEEKLY", "2026-10-05", "2026-10-05", "2026-10-12", "FREQ=WEEKLY"},
+		{"early", "FREQ=WEEKLY", "2026-10-05", "2026-10-01", "2026-10-12", "FREQ=WEEKLY"},
+		{"late skips missed", "FREQ=WEEKLY", "2026-09-01", "2026-10-18", "2026-10-20", "FREQ=WEEKLY"},
+		{"late lands today", "FREQ=DAILY;INTERVAL=7", "2026-10-04", "2026-10-18", "2026-10-18", "FREQ=DAILY;INTERVAL=7"},
+		{"late monthly keeps day", "FREQ=MONTHLY", "2026-01-31", "2026-05-02", "2026-05-31", "FREQ=MONTHLY"},
+		{"month end clamps", "FREQ=MONTHLY", "2026-01-31", "2026-01-31", "2026-02-28", "FREQ=MONTHLY;BYMONTHDAY=31"},
+		{"month end recovers", "FREQ=MONTHLY;BYMONTHDAY=31", "2026-02-28", "2026-02-28", "2026-03-31", "FREQ=MONTHLY;BYMONTHDAY=31"},
+		{"leap day", "FREQ=YEARLY", "2028-02-29", "2028-02-29", "2029-02-28", "FREQ=YEARLY;BYMONTHDAY=29"},
+		{"count counts down", "FREQ=MONTHLY;COUNT=3", "2026-10-01", "2026-10-01", "2026-11-01", "FREQ=MONTHLY;COUNT=2"},
+		{"count exhausted", "FREQ=MONTHLY;COUNT=1", "2026-10-01", "2026-10-01", "", ""},
+		{"until reached", "FREQ=WEEKLY;UNTIL=20261231", "2026-12-24", "2026-12-24", "2026-12-31", "FREQ=WEEKLY;UNTIL=20261231"},
+		{"until passed", "FREQ=WEEKLY;UNTIL=20261231", "2026-12-28", "2026-12-28", "", ""},
+		{"until passed while late", "FREQ=WEEKLY;UNTIL=20261101", "2026-10-01", "2026-11-20", "", ""},
+	}
+	for _, tt := range tests {
+		rec, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatalf("%s: ParseRecurrence(%q): %v", tt.name, tt.rule, err)
+		}
+		next, rule, ok := rec.Next(date(tt.due), date(tt.done))
+		if tt.want == "" {
+			if ok {
+				t.Errorf("%s: Next = %s, want the series to end", tt.name, next.Format("2006-01-02"))
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%s: Next ended the series, want %s", tt.name, tt.want)
+			continue
+		}
+		if got := next.Format("2006-01-02"); got != tt.want || rule.String() != tt.wantRule {
+			t.Errorf("%s: Next = %s %q, want %s %q", tt.name, got, rule.String(), tt.want, tt.wantRule)
+		}
+	}
+}
+
+// TestRecurrenceNextChain completes each occurrence as it falls due, the way
+// CompleteTask stores the returned rule on the next task.
+func TestRecurrenceNextChain(t *testing.T) {
+	tests := []struct {
+		rule string
+		due  string
+		want []string
+		ends bool // the series ends after want
+	}{
+		{"FREQ=MONTHLY", "2026-01-31", []string{"2026-02-28", "2026-03-31", "2026-04-30", "2026-05-31"}, false},
+		{"FREQ=MONTHLY;INTERVAL=3", "2026-11-30", []string{"2027-02-28", "2027-05-30", "2027-08-30"}, false},
+		{"FREQ=YEARLY", "2028-02-29", []string{"2029-02-28", "2030-02-28", "2031-02-28", "2032-02-29"}, false},
+		{"FREQ=WEEKLY;COUNT=3", "2026-10-05", []string{"2026-10-12", "2026-10-19"}, true},
+		{"FREQ=DAILY;INTERVAL=10;UNTIL=20261030", "2026-10-01", []string{"2026-10-11", "2026-10-21"}, true},
+	}
+	for _, tt := range tests {
+		rec, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseRecurrence(%q): %v", tt.rule, err)
+		}
+		due := date(tt.due)
+		for i, want := range tt.want {
+			next, rule, ok := rec.Next(due, due)
+			if got := next.Format("2006-01-02"); !ok || got != want {
+				t.Errorf("%s from %s: occurrence %d = %s (ok %v), want %s", tt.rule, tt.due, i+1, got, ok, want)
+				break
+			}
+			due, rec = next, &rule
+		}
+		if _, _, ok := rec.Next(due, due); ok == tt.ends {
+			t.Errorf("%s from %s: after %s, series continues = %v, want %v", tt.rule, tt.due, due.Format("2006-01-02"), ok, !tt.ends)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -15,8 +17,24 @@ func NewRepo(db *DB) *Repo { return &Repo{db: db} }
 
 // -------- Stages --------
 
-func (r *Repo) ListStages(ctx context.Context) ([]Stage, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, name, sort, color FROM stages ORDER BY sort ASC, id ASC`)
+const stageCols = `s.id, s.pipeline_id, s.name, s.sort, s.color, s.deal_prompt, s.win_probability`
+
+func scanStage(row rowScanner) (Stage, error) {
+	var s Stage
+	err := row.Scan(&s.ID, &s.PipelineID, &s.Name, &s.Sort, &s.Color, &s.DealPrompt, &s.WinProb)
+	return s, err
+}
+
+// ListStages returns a pipeline's stages in board order, or every stage
+// (pipeline by pipeline) when pipelineID is 0.
+func (r *Repo) ListStages(ctx context.Context, pipelineID int64) ([]Stage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+stageCols+`
+FROM stages s
+JOIN pipelines p ON p.id = s.pipeline_id
+WHERE ? = 0 OR s.pipeline_id = ?
+ORDER BY p.sort ASC, p.id ASC, s.sort ASC, s.id ASC
+`, pipelineID, pipelineID)
 	if err != nil {
 		return nil, err
 	}
@@ -24,8 +42,8 @@ func (r *Repo) ListStages(ctx context.Context) ([]Stage, error) {
 
 	var out []Stage
 	for rows.Next() {
-		var s Stage
-		if err := rows.Scan(&s.ID, &s.Name, &s.Sort, &s.Color); err != nil {
+		s, err := scanStage(rows)
+		if err != nil {
 			return nil, err
 		}
 		out = append(out, s)
@@ -33,146 +51,388 @@ func (r *Repo) ListStages(ctx context.Context) ([]Stage, error) {
 	return out, rows.Err()
 }
 
-// -------- Leads --------
+// FindStage looks a stage up by name (case-insensitive) within a pipeline
+// (any pipeline when pipelineID is 0). An empty name returns the first
+// stage in board order.
+func (r *Repo) FindStage(ctx context.Context, pipelineID int64, name string) (Stage, error) {
+	s, err := scanStage(r.db.QueryRowContext(ctx, `
+SELECT `+stageCols+`
+FROM stages s
+JOIN pipelines p ON p.id = s.pipeline_id
+WHERE (? = 0 OR s.pipeline_id = ?)
+  AND (? = '' OR lower(s.name) = lower(?))
+ORDER BY p.sort ASC, p.id ASC, s.sort ASC, s.id ASC
+LIMIT 1
+`, pipelineID, pipelineID, name, name))
+	if err == sql.ErrNoRows {
+		return Stage{}, fmt.Errorf("stage %q not found", name)
+	}
+	return s, err
+}
 
-func (r *Repo) ListLeads(ctx context.Context, q string) ([]Lead, error) {
-	q = strings.TrimSpace(q)
-	var rows *sql.Rows
-	var err error
+// -------- Leads --------
 
-	if q == "" {
-		rows, err = r.db.QueryContext(ctx, `
-SELECT l.id, l.full_name, l.phone, l.email, l.lead_type, l.source,
-       l.stage_id, s.name,
-       l.created_at, l.updated_at, l.last_contacted
-FROM leads l
-JOIN stages s ON s.id = l.stage_id
-ORDER BY l.updated_at DESC, l.id DESC
-`)
-	} else {
-		like := "%" + q + "%"
-		rows, err = r.db.QueryContext(ctx, `
+// leadSelect is the column list every lead query scans through scanLead.
+const leadSelect = `
 SELECT l.id, l.full_name, l.phone, l.email, l.lead_type, l.source,
-       l.stage_id, s.name,
-       l.created_at, l.updated_at, l.last_contacted
+       l.stage_id, s.name, s.pipeline_id, p.name,
+       l.status, l.next_follow_up, l.notes,
+       l.created_at, l.updated_at, l.last_contacted, l.archived_at,
+       COALESCE((SELECT MAX(h.changed_at) FROM stage_history h WHERE h.lead_id = l.id), l.created_at),
+       COALESCE((SELECT group_concat(t.name, ',') FROM lead_tags lt JOIN tags t ON t.id = lt.tag_id WHERE lt.lead_id = l.id), '')
 FROM leads l
 JOIN stages s ON s.id = l.stage_id
-WHERE l.full_name LIKE ? OR l.phone LIKE ? OR l.email LIKE ? OR l.source LIKE ?
-ORDER BY l.updated_at DESC, l.id DESC
-`, like, like, like, like)
-	}
+JOIN pipelines p ON p.id = s.pipeline_id
+`
 
-	if err != nil {
-		return nil, err
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLead(row rowScanner) (Lead, error) {
+	var l Lead
+	var created, updated, stageSince, tags string
+	var next, last, archived sql.NullString
+	if err := row.Scan(
+		&l.ID, &l.FullName, &l.Phone, &l.Email, &l.LeadType, &l.Source,
+		&l.StageID, &l.StageName, &l.PipelineID, &l.PipelineName,
+		&l.Status, &next, &l.Notes,
+		&created, &updated, &last, &archived, &stageSince, &tags,
+	); err != nil {
+		return Lead{}, err
+	}
+	l.CreatedAt = mustParseTime(created)
+	l.UpdatedAt = mustParseTime(updated)
+	l.StageSince = mustParseTime(stageSince)
+	if tags != "" {
+		l.Tags = strings.Split(tags, ",")
+		sort.Strings(l.Tags)
+	}
+	if next.Valid && next.String != "" {
+		d := mustParseDate(next.String)
+		l.NextFollowUp = &d
+	}
+	if last.Valid && last.String != "" {
+		t := mustParseTime(last.String)
+		l.LastContacted = &t
 	}
+	if archived.Valid && archived.String != "" {
+		t := mustParseTime(archived.String)
+		l.ArchivedAt = &t
+	}
+	return l, nil
+}
+
+func scanLeads(rows *sql.Rows) ([]Lead, error) {
 	defer rows.Close()
 
 	var out []Lead
 	for rows.Next() {
-		var l Lead
-		var created, updated string
-		var last sql.NullString
-		if err := rows.Scan(
-			&l.ID, &l.FullName, &l.Phone, &l.Email, &l.LeadType, &l.Source,
-			&l.StageID, &l.StageName,
-			&created, &updated, &last,
-		); err != nil {
+		l, err := scanLead(rows)
+		if err != nil {
 			return nil, err
 		}
-		l.CreatedAt = mustParseTime(created)
-		l.UpdatedAt = mustParseTime(updated)
-		if last.Valid && last.String != "" {
-			t := mustParseTime(last.String)
-			l.LastContacted = &t
-		}
 		out = append(out, l)
 	}
 	return out, rows.Err()
 }
 
-func (r *Repo) ListLeadsByStage(ctx context.Context) (map[int64][]Lead, error) {
-	rows, err := r.db.QueryContext(ctx, `
-SELECT l.id, l.full_name, l.phone, l.email, l.lead_type, l.source,
-       l.stage_id, s.name,
-       l.created_at, l.updated_at, l.last_contacted
-FROM leads l
-JOIN stages s ON s.id = l.stage_id
-ORDER BY s.sort ASC, l.updated_at DESC, l.id DESC
+// ListLeads lists active leads, most recently updated first. A non-empty q
+// returns SearchLeads matches in rank order instead.
+func (r *Repo) ListLeads(ctx context.Context, q string) ([]Lead, error) {
+	if q = strings.TrimSpace(q); q != "" {
+		hits, err := r.SearchLeads(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]Lead, 0, len(hits))
+		for _, h := range hits {
+			out = append(out, h.Lead)
+		}
+		return out, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, leadSelect+`
+WHERE l.archived_at IS NULL
+ORDER BY l.updated_at DESC, l.id DESC
 `)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanLeads(rows)
+}
+
+// ListLeadsByType lists leads of one lead_type (all leads when leadType is empty).
+func (r *Repo) ListLeadsByType(ctx context.Context, leadType string) ([]Lead, error) {
+	rows, err := r.db.QueryContext(ctx, leadSelect+`
+WHERE l.archived_at IS NULL AND (? = '' OR l.lead_type = ?)
+ORDER BY l.created_at DESC, l.id DESC
+`, leadType, leadType)
+	if err != nil {
+		return nil, err
+	}
+	return scanLeads(rows)
+}
+
+// ListLeadsByStage groups one pipeline's active leads by stage id.
+func (r *Repo) ListLeadsByStage(ctx context.Context, pipelineID int64) (map[int64][]Lead, error) {
+	rows, err := r.db.QueryContext(ctx, leadSelect+`
+WHERE l.archived_at IS NULL AND s.pipeline_id = ?
+ORDER BY s.sort ASC, l.updated_at DESC, l.id DESC
+`, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	leads, err := scanLeads(rows)
+	if err != nil {
+		return nil, err
+	}
 
 	out := make(map[int64][]Lead)
-	for rows.Next() {
-		var l Lead
-		var created, updated string
-		var last sql.NullString
-		if err := rows.Scan(
-			&l.ID, &l.FullName, &l.Phone, &l.Email, &l.LeadType, &l.Source,
-			&l.StageID, &l.StageName,
-			&created, &updated, &last,
-		); err != nil {
-			return nil, err
+	for _, l := range leads {
+		out[l.StageID] = append(out[l.StageID], l)
+	}
+	return out, nil
+}
+
+// ListFollowUpsDue returns leads whose next follow-up is on or before day.
+func (r *Repo) ListFollowUpsDue(ctx context.Context, day time.Time) ([]Lead, error) {
+	rows, err := r.db.QueryContext(ctx, leadSelect+`
+WHERE l.archived_at IS NULL
+  AND l.next_follow_up IS NOT NULL AND l.next_follow_up <> ''
+  AND l.next_follow_up <= ?
+ORDER BY l.next_follow_up ASC, l.id ASC
+`, day.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	return scanLeads(rows)
+}
+
+// CreateLead inserts l unless it looks like a lead already on file, in
+// which case it returns a *DuplicateError listing the matches.
+func (r *Repo) CreateLead(ctx context.Context, l Lead) (int64, error) {
+	if err := r.normalizeLeadContact(ctx, &l, Lead{}); err != nil {
+		return 0, err
+	}
+	dupes, err := r.FindDuplicates(ctx, l)
+	if err != nil {
+		return 0, err
+	}
+	if len(dupes) > 0 {
+		return 0, &DuplicateError{Matches: dupes}
+	}
+	return r.CreateLeadAllowDuplicate(ctx, l)
+}
+
+// CreateLeadAllowDuplicate inserts l without the duplicate check. Phone and
+// email are normalized (a *FieldError when invalid). Status defaults to
+// "new"; without a StageID the lead starts in the first stage of its lead
+// type's default pipeline. Cadences set to auto-apply to the lead's source
+// or type are applied once it is saved.
+func (r *Repo) CreateLeadAllowDuplicate(ctx context.Context, l Lead) (int64, error) {
+	if err := r.normalizeLeadContact(ctx, &l, Lead{}); err != nil {
+		return 0, err
+	}
+	if l.LeadType == "" {
+		l.LeadType = "buyer"
+	}
+	if l.Status == "" {
+		l.Status = "new"
+	}
+	if l.StageID == 0 {
+		p, err := r.DefaultPipeline(ctx, l.LeadType)
+		if err != nil {
+			return 0, err
 		}
-		l.CreatedAt = mustParseTime(created)
-		l.UpdatedAt = mustParseTime(updated)
-		if last.Valid && last.String != "" {
-			t := mustParseTime(last.String)
-			l.LastContacted = &t
+		st, err := r.FindStage(ctx, p.ID, "")
+		if err != nil {
+			return 0, fmt.Errorf("pipeline %s has no stages", p.Name)
 		}
-		out[l.StageID] = append(out[l.StageID], l)
+		l.StageID = st.ID
 	}
-	return out, rows.Err()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	id, err := insertLead(ctx, tx, l)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	l.ID = id
+	if err := r.applyAutoCadences(ctx, l); err != nil {
+		return id, fmt.Errorf("lead #%d was saved, but its follow-up cadence was not: %w", id, err)
+	}
+	return id, nil
 }
 
-func (r *Repo) CreateLead(ctx context.Context, fullName, phone, email, leadType, source string, stageID int64) (int64, error) {
-	res, err := r.db.ExecContext(ctx, `
-INSERT INTO leads(full_name, phone, email, lead_type, source, stage_id)
-VALUES (?, ?, ?, ?, ?, ?)
-`, fullName, phone, email, leadType, source, stageID)
+// insertLead writes an already normalized and defaulted lead, its first
+// stage history entry and its primary phone and email.
+func insertLead(ctx context.Context, tx *sql.Tx, l Lead) (int64, error) {
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO leads(full_name, phone, email, lead_type, source, stage_id, status, next_follow_up, notes)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, l.FullName, l.Phone, l.Email, l.LeadType, l.Source, l.StageID, l.Status, nullDate(l.NextFollowUp), l.Notes)
 	if err != nil {
 		return 0, err
 	}
-	return res.LastInsertId()
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := recordStageChange(ctx, tx, id, 0, l.StageID); err != nil {
+		return 0, err
+	}
+	for kind, v := range map[string]string{"phone": l.Phone, "email": l.Email} {
+		if err := setPrimaryContactValue(ctx, tx, id, kind, v); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// UpdateLead saves l's editable fields and bumps updated_at. Phone and
+// email are normalized, unless unchanged from what is stored, and replace
+// the lead's primary contact methods. Stage changes go through
+// MoveLeadStage.
+func (r *Repo) UpdateLead(ctx context.Context, l Lead) error {
+	stored, err := r.GetLead(ctx, l.ID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("lead #%d not found", l.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := r.normalizeLeadContact(ctx, &l, stored); err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := updateLead(ctx, tx, l); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateLead is UpdateLead inside the caller's transaction; l must already
+// be normalized.
+func updateLead(ctx context.Context, tx *sql.Tx, l Lead) error {
+	res, err := tx.ExecContext(ctx, `
+UPDATE leads
+SET full_name = ?, phone = ?, email = ?, lead_type = ?, source = ?,
+    status = ?, next_follow_up = ?, notes = ?,
+    updated_at = datetime('now')
+WHERE id = ?
+`, l.FullName, l.Phone, l.Email, l.LeadType, l.Source, l.Status, nullDate(l.NextFollowUp), l.Notes, l.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("lead #%d not found", l.ID)
+	}
+	for kind, v := range map[string]string{"phone": l.Phone, "email": l.Email} {
+		if err := setPrimaryContactValue(ctx, tx, l.ID, kind, v); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// MoveLeadStage moves a lead and records the change in its stage history.
+// Moving a lead to the stage it is already in is a no-op.
 func (r *Repo) MoveLeadStage(ctx context.Context, leadID, newStageID int64) error {
-	_, err := r.db.ExecContext(ctx, `
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var oldStageID int64
+	if err := tx.QueryRowContext(ctx, `SELECT stage_id FROM leads WHERE id = ?`, leadID).Scan(&oldStageID); err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("lead #%d not found", leadID)
+		}
+		return err
+	}
+	if oldStageID == newStageID {
+		return tx.Rollback()
+	}
+	if _, err := tx.ExecContext(ctx, `
 UPDATE leads
 SET stage_id = ?, updated_at = datetime('now')
 WHERE id = ?
-`, newStageID, leadID)
-	return err
+`, newStageID, leadID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := recordStageChange(ctx, tx, leadID, oldStageID, newStageID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *Repo) GetLead(ctx context.Context, id int64) (Lead, error) {
-	var l Lead
-	var created, updated string
-	var last sql.NullString
-	err := r.db.QueryRowContext(ctx, `
-SELECT l.id, l.full_name, l.phone, l.email, l.lead_type, l.source,
-       l.stage_id, s.name,
-       l.created_at, l.updated_at, l.last_contacted
-FROM leads l
-JOIN stages s ON s.id = l.stage_id
+	return scanLead(r.db.QueryRowContext(ctx, leadSelect+`
 WHERE l.id = ?
-`, id).Scan(
-		&l.ID, &l.FullName, &l.Phone, &l.Email, &l.LeadType, &l.Source,
-		&l.StageID, &l.StageName,
-		&created, &updated, &last,
-	)
+`, id))
+}
+
+// ListArchivedLeads returns the trash, most recently archived first.
+func (r *Repo) ListArchivedLeads(ctx context.Context) ([]Lead, error) {
+	rows, err := r.db.QueryContext(ctx, leadSelect+`
+WHERE l.archived_at IS NOT NULL
+ORDER BY l.archived_at DESC, l.id DESC
+`)
 	if err != nil {
-		return Lead{}, err
+		return nil, err
 	}
-	l.CreatedAt = mustParseTime(created)
-	l.UpdatedAt = mustParseTime(updated)
-	if last.Valid && last.String != "" {
-		t := mustParseTime(last.String)
-		l.LastContacted = &t
+	return scanLeads(rows)
+}
+
+// ArchiveLead moves a lead to the trash, hiding it from every other view.
+func (r *Repo) ArchiveLead(ctx context.Context, id int64) error {
+	return r.execOne(ctx, id, `
+UPDATE leads
+SET archived_at = datetime('now'), updated_at = datetime('now')
+WHERE id = ? AND archived_at IS NULL
+`, id)
+}
+
+// RestoreLead takes a lead back out of the trash.
+func (r *Repo) RestoreLead(ctx context.Context, id int64) error {
+	return r.execOne(ctx, id, `
+UPDATE leads
+SET archived_at = NULL, updated_at = datetime('now')
+WHERE id = ? AND archived_at IS NOT NULL
+`, id)
+}
+
+// PurgeLead permanently deletes an archived lead. Notes and tasks go with
+// it via ON DELETE CASCADE.
+func (r *Repo) PurgeLead(ctx context.Context, id int64) error {
+	return r.execOne(ctx, id, `
+DELETE FROM leads WHERE id = ? AND archived_at IS NOT NULL
+`, id)
+}
+
+// execOne runs a statement that must touch exactly the lead with id.
+func (r *Repo) execOne(ctx context.Context, id int64, query string, args ...any) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
 	}
-	return l, nil
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("lead #%d not found or not in that state", id)
+	}
+	return nil
 }
 
 // -------- Notes --------
@@ -215,108 +475,220 @@ INSERT INTO notes(lead_id, body) VALUES (?, ?)
 
 // -------- Tasks --------
 
-func (r *Repo) ListOpenTasks(ctx context.Context) ([]Task, error) {
-	rows, err := r.db.QueryContext(ctx, `
-SELECT t.id, t.lead_id, l.full_name, t.title, t.due_date, t.status, t.created_at, t.completed_at
+// taskSelect is the column list every task query scans through scanTask.
+const taskSelect = `
+SELECT t.id, t.lead_id, l.full_name, t.title, t.due_date, t.due_time, t.task_type, t.priority,
+       t.status, t.recurrence, t.created_at, t.completed_at
 FROM tasks t
 JOIN leads l ON l.id = t.lead_id
-WHERE t.status = 'open'
-ORDER BY
-  CASE WHEN t.due_date IS NULL OR t.due_date = '' THEN 1 ELSE 0 END,
-  t.due_date ASC,
-  t.id DESC
-`)
-	if err != nil {
-		return nil, err
+`
+
+func scanTask(row rowScanner) (Task, error) {
+	var t Task
+	var due sql.NullString
+	var created string
+	var completed sql.NullString
+	if err := row.Scan(&t.ID, &t.LeadID, &t.LeadName, &t.Title, &due, &t.DueTime, &t.Type, &t.Priority,
+		&t.Status, &t.Recurrence, &created, &completed); err != nil {
+		return Task{}, err
+	}
+	t.CreatedAt = mustParseTime(created)
+	if due.Valid && due.String != "" {
+		dd := mustParseDate(due.String)
+		t.DueDate = &dd
+	}
+	if completed.Valid && completed.String != "" {
+		ct := mustParseTime(completed.String)
+		t.CompletedAt = &ct
 	}
+	return t, nil
+}
+
+func scanTasks(rows *sql.Rows) ([]Task, error) {
 	defer rows.Close()
 
 	var out []Task
 	for rows.Next() {
-		var t Task
-		var due sql.NullString
-		var created string
-		var completed sql.NullString
-		if err := rows.Scan(&t.ID, &t.LeadID, &t.LeadName, &t.Title, &due, &t.Status, &created, &completed); err != nil {
+		t, err := scanTask(rows)
+		if err != nil {
 			return nil, err
 		}
-		t.CreatedAt = mustParseTime(created)
-		if due.Valid && due.String != "" {
-			dd := mustParseDate(due.String)
-			t.DueDate = &dd
-		}
-		if completed.Valid && completed.String != "" {
-			ct := mustParseTime(completed.String)
-			t.CompletedAt = &ct
-		}
 		out = append(out, t)
 	}
 	return out, rows.Err()
 }
 
-func (r *Repo) ListTasksForLead(ctx context.Context, leadID int64) ([]Task, error) {
-	rows, err := r.db.QueryContext(ctx, `
-SELECT t.id, t.lead_id, '' as lead_name, t.title, t.due_date, t.status, t.created_at, t.completed_at
-FROM tasks t
-WHERE t.lead_id = ?
-ORDER BY
-  CASE WHEN t.status = 'open' THEN 0 ELSE 1 END,
+// taskOrder sorts tasks by priority, then by when they are due: dated
+// before undated, and within a day timed tasks before all-day ones.
+const taskOrder = `
+  t.priority DESC,
   CASE WHEN t.due_date IS NULL OR t.due_date = '' THEN 1 ELSE 0 END,
   t.due_date ASC,
+  CASE WHEN t.due_time = '' THEN 1 ELSE 0 END,
+  t.due_time ASC,
   t.id DESC
-`, leadID)
+`
+
+func (r *Repo) ListOpenTasks(ctx context.Context) ([]Task, error) {
+	rows, err := r.db.QueryContext(ctx, taskSelect+`
+WHERE t.status = 'open' AND l.archived_at IS NULL
+ORDER BY`+taskOrder)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanTasks(rows)
+}
 
-	var out []Task
-	for rows.Next() {
-		var t Task
-		var due sql.NullString
-		var created string
-		var completed sql.NullString
-		if err := rows.Scan(&t.ID, &t.LeadID, &t.LeadName, &t.Title, &due, &t.Status, &created, &completed); err != nil {
-			return nil, err
-		}
-		t.CreatedAt = mustParseTime(created)
-		if due.Valid && due.String != "" {
-			dd := mustParseDate(due.String)
-			t.DueDate = &dd
-		}
-		if completed.Valid && completed.String != "" {
-			ct := mustParseTime(completed.String)
-			t.CompletedAt = &ct
-		}
-		out = append(out, t)
+func (r *Repo) ListTasksForLead(ctx context.Context, leadID int64) ([]Task, error) {
+	rows, err := r.db.QueryContext(ctx, taskSelect+`
+WHERE t.lead_id = ?
+ORDER BY
+  CASE WHEN t.status = 'open' THEN 0 ELSE 1 END,`+taskOrder, leadID)
+	if err != nil {
+		return nil, err
 	}
-	return out, rows.Err()
+	return scanTasks(rows)
 }
 
-func (r *Repo) CreateTask(ctx context.Context, leadID int64, title string, due *time.Time) (int64, error) {
-	var dueStr any = nil
-	if due != nil {
-		dueStr = due.Format("2006-01-02")
+// CreateTask adds a task to a lead. A recurring task (Recurrence set, in
+// RRULE or shorthand form) is due today unless a due date is given.
+func (r *Repo) CreateTask(ctx context.Context, t Task) (int64, error) {
+	if err := validateTask(&t); err != nil {
+		return 0, err
 	}
-	res, err := r.db.ExecContext(ctx, `
-INSERT INTO tasks(lead_id, title, due_date)
-VALUES (?, ?, ?)
-`, leadID, title, dueStr)
+	rec, err := ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return 0, err
+	}
+	t.Recurrence = ""
+	if rec != nil {
+		t.Recurrence = rec.String()
+		if t.DueDate == nil {
+			today := time.Now()
+			t.DueDate = &today
+		}
+	}
+	id, err := insertTask(ctx, r.db, t)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = r.db.ExecContext(ctx, `UPDATE leads SET updated_at = datetime('now') WHERE id = ?`, t.LeadID)
+	return id, nil
+}
+
+// insertTask writes a validated task.
+func insertTask(ctx context.Context, ex execer, t Task) (int64, error) {
+	res, err := ex.ExecContext(ctx, `
+INSERT INTO tasks(lead_id, title, due_date, due_time, task_type, priority, recurrence)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`, t.LeadID, t.Title, nullDate(t.DueDate), t.DueTime, t.Type, t.Priority, t.Recurrence)
 	if err != nil {
 		return 0, err
 	}
-	_, _ = r.db.ExecContext(ctx, `UPDATE leads SET updated_at = datetime('now') WHERE id = ?`, leadID)
 	return res.LastInsertId()
 }
 
-func (r *Repo) CompleteTask(ctx context.Context, taskID int64) error {
-	_, err := r.db.ExecContext(ctx, `
+// CompleteTask marks an open task done. For a recurring task it also
+// creates the next occurrence and returns its due date, which is nil when
+// the task does not repeat, its COUNT or UNTIL has run out, or it was
+// already done.
+func (r *Repo) CompleteTask(ctx context.Context, taskID int64) (*time.Time, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	t, err := scanTask(tx.QueryRowContext(ctx, taskSelect+`WHERE t.id = ?`, taskID))
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task #%d not found", taskID)
+		}
+		return nil, err
+	}
+	if t.Status != "open" {
+		return nil, tx.Rollback()
+	}
+	if _, err := tx.ExecContext(ctx, `
 UPDATE tasks
 SET status = 'done',
     completed_at = datetime('now')
 WHERE id = ?
-`, taskID)
-	return err
+`, taskID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	var next *time.Time
+	if rec, _ := ParseRecurrence(t.Recurrence); rec != nil {
+		now := time.Now()
+		due := now
+		if t.DueDate != nil {
+			due = *t.DueDate
+		}
+		if d, rule, ok := rec.Next(due, now); ok {
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO tasks(lead_id, title, due_date, due_time, task_type, priority, recurrence)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`, t.LeadID, t.Title, d.Format("2006-01-02"), t.DueTime, t.Type, t.Priority, rule.String()); err != nil {
+				_ = tx.Rollback()
+				return nil, err
+			}
+			next = &d
+		}
+	}
+	return next, tx.Commit()
+}
+
+// SnoozeTask pushes an open task back by d and returns it as saved. Timed
+// tasks move from when they are due, or from now if that has passed.
+// All-day tasks move by whole days from their due date or today, whichever
+// is later; snoozing one that is due today or overdue by less than a day
+// gives it a time d from now. A task with no due date counts as due today.
+func (r *Repo) SnoozeTask(ctx context.Context, taskID int64, d time.Duration) (Task, error) {
+	t, err := scanTask(r.db.QueryRowContext(ctx, taskSelect+`WHERE t.id = ?`, taskID))
+	if err == sql.ErrNoRows {
+		return t, fmt.Errorf("task #%d not found", taskID)
+	}
+	if err != nil {
+		return t, err
+	}
+	if t.Status != "open" {
+		return t, fmt.Errorf("task #%d is already done", taskID)
+	}
+
+	const day = 24 * time.Hour
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	due, ok := t.Due()
+	if !ok {
+		due = today
+	}
+	var next time.Time
+	switch {
+	case t.DueTime != "":
+		if due.Before(now) {
+			due = now
+		}
+		next = due.Add(d)
+		t.DueTime = next.Format("15:04")
+	case !due.After(today) && d < day:
+		next = now.Add(d)
+		t.DueTime = next.Format("15:04")
+	default:
+		if due.Before(today) {
+			due = today
+		}
+		days := int((d + day - 1) / day) // part days round up
+		next = due.AddDate(0, 0, days)
+	}
+	date := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, time.UTC)
+	t.DueDate = &date
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE tasks SET due_date = ?, due_time = ? WHERE id = ?`,
+		nullDate(t.DueDate), t.DueTime, taskID); err != nil {
+		return t, err
+	}
+	return t, nil
 }
 
 // -------- Helpers --------
@@ -335,6 +707,14 @@ func mustParseTime(s string) time.Time {
 	return time.Time{}
 }
 
+// nullDate formats an optional date as YYYY-MM-DD, or NULL when unset.
+func nullDate(t *time.Time) any {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
 func mustParseDate(s string) time.Time {
 	// YYYY-MM-DD
 	t, err := time.Parse("2006-01-02", s)
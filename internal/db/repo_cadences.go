@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -------- Follow-up cadences --------
+
+// ListCadences returns every cadence with its steps, by name.
+func (r *Repo) ListCadences(ctx context.Context) ([]Cadence, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, auto_source, auto_type FROM cadences ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	var out []Cadence
+	for rows.Next() {
+		var c Cadence
+		if err := rows.Scan(&c.ID, &c.Name, &c.AutoSource, &c.AutoType); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	steps, err := r.cadenceSteps(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Steps = steps[out[i].ID]
+	}
+	return out, nil
+}
+
+// FindCadence looks a cadence up by id or name (case-insensitive).
+func (r *Repo) FindCadence(ctx context.Context, nameOrID string) (Cadence, error) {
+	nameOrID = strings.TrimSpace(nameOrID)
+	id, _ := strconv.ParseInt(nameOrID, 10, 64)
+	var c Cadence
+	err := r.db.QueryRowContext(ctx, `
+SELECT id, name, auto_source, auto_type FROM cadences
+WHERE id = ? OR name = ?
+ORDER BY id = ? DESC
+LIMIT 1
+`, id, nameOrID, id).Scan(&c.ID, &c.Name, &c.AutoSource, &c.AutoType)
+	if err == sql.ErrNoRows {
+		return c, fmt.Errorf("cadence %q not found", nameOrID)
+	}
+	if err != nil {
+		return c, err
+	}
+	steps, err := r.cadenceSteps(ctx, c.ID)
+	c.Steps = steps[c.ID]
+	return c, err
+}
+
+// cadenceSteps loads the steps of one cadence, or of all with id 0, keyed
+// by cadence.
+func (r *Repo) cadenceSteps(ctx context.Context, id int64) (map[int64][]CadenceStep, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT cadence_id, day_offset, title, task_type FROM cadence_steps
+WHERE ? = 0 OR cadence_id = ?
+ORDER BY cadence_id, sort
+`, id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int64][]CadenceStep{}
+	for rows.Next() {
+		var cid int64
+		var s CadenceStep
+		if err := rows.Scan(&cid, &s.Day, &s.Title, &s.Type); err != nil {
+			return nil, err
+		}
+		out[cid] = append(out[cid], s)
+	}
+	return out, rows.Err()
+}
+
+// CreateCadence adds a cadence and its steps.
+func (r *Repo) CreateCadence(ctx context.Context, c Cadence) (int64, error) {
+	if err := validateCadence(&c); err != nil {
+		return 0, err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO cadences(name, auto_source, auto_type) VALUES (?, ?, ?)`,
+		c.Name, c.AutoSource, c.AutoType)
+	if err != nil {
+		_ = tx.Rollback()
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return 0, fmt.Errorf("cadence %q already exists", c.Name)
+		}
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if err := insertCadenceSteps(ctx, tx, id, c.Steps); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// UpdateCadence saves c's name, auto-apply rule and steps, replacing the
+// steps on file.
+func (r *Repo) UpdateCadence(ctx context.Context, c Cadence) error {
+	if err := validateCadence(&c); err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE cadences SET name = ?, auto_source = ?, auto_type = ? WHERE id = ?`,
+		c.Name, c.AutoSource, c.AutoType, c.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("cadence %q already exists", c.Name)
+		}
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		_ = tx.Rollback()
+		return fmt.Errorf("cadence #%d not found", c.ID)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cadence_steps WHERE cadence_id = ?`, c.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := insertCadenceSteps(ctx, tx, c.ID, c.Steps); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertCadenceSteps(ctx context.Context, ex execer, id int64, steps []CadenceStep) error {
+	for i, s := range steps {
+		if _, err := ex.ExecContext(ctx, `
+INSERT INTO cadence_steps(cadence_id, sort, day_offset, title, task_type)
+VALUES (?, ?, ?, ?, ?)
+`, id, i, s.Day, s.Title, s.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteCadence removes a cadence. Tasks it already created are kept.
+func (r *Repo) DeleteCadence(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM cadences WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("cadence #%d not found", id)
+	}
+	return nil
+}
+
+// ApplyCadence creates c's tasks on a lead, each due its step's offset in
+// days after start, and returns how many were created. The tasks are
+// written in one transaction: if any step fails, none are added.
+func (r *Repo) ApplyCadence(ctx context.Context, c Cadence, leadID int64, start time.Time) (int, error) {
+	if len(c.Steps) == 0 {
+		return 0, fmt.Errorf("cadence %s has no steps", c.Name)
+	}
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	tasks := make([]Task, len(c.Steps))
+	for i, s := range c.Steps {
+		due := day.AddDate(0, 0, s.Day)
+		tasks[i] = Task{LeadID: leadID, Title: s.Title, Type: s.Type, DueDate: &due}
+		if err := validateTask(&tasks[i]); err != nil {
+			return 0, fmt.Errorf("cadence %s step %d: %w", c.Name, i+1, err)
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	for i, t := range tasks {
+		if _, err := insertTask(ctx, tx, t); err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("cadence %s step %d: %w", c.Name, i+1, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE leads SET updated_at = datetime('now') WHERE id = ?`, leadID); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(tasks), nil
+}
+
+// applyAutoCadences applies, from today, every cadence whose auto-apply
+// rule matches a newly created lead.
+func (r *Repo) applyAutoCadences(ctx context.Context, l Lead) error {
+	all, err := r.ListCadences(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range all {
+		if !c.matches(l) || len(c.Steps) == 0 {
+			continue
+		}
+		if _, err := r.ApplyCadence(ctx, c, l.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches reports whether c is applied automatically to l: it needs an
+// auto-apply rule, and every part of it that is set must match.
+func (c Cadence) matches(l Lead) bool {
+	if c.AutoSource == "" && c.AutoType == "" {
+		return false
+	}
+	if c.AutoSource != "" && !strings.EqualFold(c.AutoSource, strings.TrimSpace(l.Source)) {
+		return false
+	}
+	return c.AutoType == "" || c.AutoType == l.LeadType
+}
+
+func validateCadence(c *Cadence) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("cadence name is required")
+	}
+	c.AutoSource = strings.TrimSpace(c.AutoSource)
+	c.AutoType = strings.ToLower(strings.TrimSpace(c.AutoType))
+	if c.AutoType != "" && !oneOf(c.AutoType, LeadTypes) {
+		return fmt.Errorf("unknown lead type %q (use %s)", c.AutoType, strings.Join(LeadTypes, "|"))
+	}
+	if len(c.Steps) == 0 {
+		return fmt.Errorf("cadence %s needs at least one step", c.Name)
+	}
+	for i := range c.Steps {
+		s := &c.Steps[i]
+		s.Title = strings.TrimSpace(s.Title)
+		var err error
+		switch {
+		case s.Title == "":
+			return fmt.Errorf("step %d has no title", i+1)
+		case s.Day < 0:
+			return fmt.Errorf("step %d: day %d is before the start", i+1, s.Day)
+		}
+		if s.Type, err = ParseTaskType(s.Type); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	sort.SliceStable(c.Steps, func(i, j int) bool { return c.Steps[i].Day < c.Steps[j].Day })
+	return nil
+}
+
+// ParseCadenceStep reads a step written as DAY:TYPE:TITLE or DAY:TITLE,
+// e.g. "0:call:Intro call" or "14:Send market report".
+func ParseCadenceStep(s string) (CadenceStep, error) {
+	day, rest, ok := strings.Cut(strings.TrimSpace(s), ":")
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(day), "+"))
+	if !ok || err != nil {
+		return CadenceStep{}, fmt.Errorf("step %q: use DAY:TYPE:TITLE or DAY:TITLE, e.g. 3:email:Send listings", s)
+	}
+	step := CadenceStep{Day: n, Title: rest}
+	if t, title, ok := strings.Cut(rest, ":"); ok && oneOf(strings.ToLower(strings.TrimSpace(t)), TaskTypes) {
+		step.Type, step.Title = strings.ToLower(strings.TrimSpace(t)), title
+	}
+	step.Title = strings.TrimSpace(step.Title)
+	return step, nil
+}
@@ -0,0 +1,288 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// -------- Contact methods & relationships --------
+
+var (
+	ContactKinds      = []string{"phone", "email"}
+	RelationshipKinds = []string{"spouse", "co-buyer", "referred-by", "attorney"}
+)
+
+// symmetricKinds read the same from either lead, so only one row is kept.
+var symmetricKinds = map[string]bool{"spouse": true, "co-buyer": true}
+
+// ListLeadContacts returns a lead's phones then emails, primary first.
+func (r *Repo) ListLeadContacts(ctx context.Context, leadID int64) ([]ContactMethod, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, lead_id, kind, label, value, is_primary
+FROM lead_contacts
+WHERE lead_id = ?
+ORDER BY kind = 'email', is_primary DESC, id ASC
+`, leadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ContactMethod
+	for rows.Next() {
+		var c ContactMethod
+		if err := rows.Scan(&c.ID, &c.LeadID, &c.Kind, &c.Label, &c.Value, &c.Primary); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// AddContact adds a phone or email to a lead, normalized like lead input.
+// The lead's first entry of a kind, or one with Primary set, becomes its
+// primary.
+func (r *Repo) AddContact(ctx context.Context, c ContactMethod) (int64, error) {
+	c.Kind = strings.ToLower(strings.TrimSpace(c.Kind))
+	c.Label = strings.TrimSpace(c.Label)
+	c.Value = strings.TrimSpace(c.Value)
+	if !oneOf(c.Kind, ContactKinds) {
+		return 0, fmt.Errorf("unknown contact kind %q (use %s)", c.Kind, strings.Join(ContactKinds, "|"))
+	}
+	if c.Value == "" {
+		return 0, fmt.Errorf("%s is required", c.Kind)
+	}
+	region, err := r.PhoneRegion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if c.Value, err = NormalizeContact(c.Kind, c.Value, region); err != nil {
+		return 0, err
+	}
+	if _, err := r.GetLead(ctx, c.LeadID); err == sql.ErrNoRows {
+		return 0, fmt.Errorf("lead #%d not found", c.LeadID)
+	} else if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	var dup int
+	if err := tx.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM lead_contacts WHERE lead_id = ? AND kind = ? AND lower(value) = lower(?)
+`, c.LeadID, c.Kind, c.Value).Scan(&dup); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if dup > 0 {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("lead #%d already has %s %s", c.LeadID, c.Kind, c.Value)
+	}
+	if c.Primary {
+		if _, err := tx.ExecContext(ctx, `UPDATE lead_contacts SET is_primary = 0 WHERE lead_id = ? AND kind = ?`, c.LeadID, c.Kind); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO lead_contacts(lead_id, kind, label, value, is_primary) VALUES (?, ?, ?, ?, ?)
+`, c.LeadID, c.Kind, c.Label, c.Value, c.Primary)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if err := syncPrimaryContact(ctx, tx, c.LeadID, c.Kind); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// SetPrimaryContact makes a contact its lead's primary phone or email.
+func (r *Repo) SetPrimaryContact(ctx context.Context, id int64) error {
+	return r.withContact(ctx, id, func(tx *sql.Tx, c ContactMethod) error {
+		_, err := tx.ExecContext(ctx, `
+UPDATE lead_contacts SET is_primary = (id = ?) WHERE lead_id = ? AND kind = ?
+`, id, c.LeadID, c.Kind)
+		return err
+	})
+}
+
+// DeleteContact removes a contact. Deleting the primary promotes the
+// lead's oldest remaining entry of that kind.
+func (r *Repo) DeleteContact(ctx context.Context, id int64) error {
+	return r.withContact(ctx, id, func(tx *sql.Tx, c ContactMethod) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM lead_contacts WHERE id = ?`, id)
+		return err
+	})
+}
+
+// withContact runs fn on contact id inside a transaction, then re-syncs the
+// lead's primary of that kind.
+func (r *Repo) withContact(ctx context.Context, id int64, fn func(*sql.Tx, ContactMethod) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var c ContactMethod
+	if err := tx.QueryRowContext(ctx, `SELECT id, lead_id, kind FROM lead_contacts WHERE id = ?`, id).
+		Scan(&c.ID, &c.LeadID, &c.Kind); err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("contact #%d not found", id)
+		}
+		return err
+	}
+	if err := fn(tx, c); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := syncPrimaryContact(ctx, tx, c.LeadID, c.Kind); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// setPrimaryContactValue makes value the lead's primary of kind, as when
+// CreateLead or UpdateLead writes leads.phone / leads.email. A value that is
+// already on file is promoted; otherwise the current primary is rewritten.
+// An empty value drops the primary, and the next entry (if any) takes over.
+func setPrimaryContactValue(ctx context.Context, tx *sql.Tx, leadID int64, kind, value string) error {
+	value = strings.TrimSpace(value)
+	var primaryID, matchID sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `
+SELECT
+  (SELECT id FROM lead_contacts WHERE lead_id = ?1 AND kind = ?2 AND is_primary = 1),
+  (SELECT id FROM lead_contacts WHERE lead_id = ?1 AND kind = ?2 AND lower(value) = lower(?3) ORDER BY id LIMIT 1)
+`, leadID, kind, value).Scan(&primaryID, &matchID); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case value == "":
+		if primaryID.Valid {
+			_, err = tx.ExecContext(ctx, `DELETE FROM lead_contacts WHERE id = ?`, primaryID.Int64)
+		}
+	case matchID.Valid:
+		_, err = tx.ExecContext(ctx, `
+UPDATE lead_contacts SET is_primary = (id = ?) WHERE lead_id = ? AND kind = ?
+`, matchID.Int64, leadID, kind)
+	case primaryID.Valid:
+		_, err = tx.ExecContext(ctx, `UPDATE lead_contacts SET value = ? WHERE id = ?`, value, primaryID.Int64)
+	default:
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO lead_contacts(lead_id, kind, value, is_primary) VALUES (?, ?, ?, 1)
+`, leadID, kind, value)
+	}
+	if err != nil {
+		return err
+	}
+	return syncPrimaryContact(ctx, tx, leadID, kind)
+}
+
+// syncPrimaryContact makes sure a lead with any entries of kind has exactly
+// one primary, and copies it into the matching leads column.
+func syncPrimaryContact(ctx context.Context, tx *sql.Tx, leadID int64, kind string) error {
+	col := "phone"
+	if kind == "email" {
+		col = "email"
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE lead_contacts
+SET is_primary = (id = (SELECT id FROM lead_contacts WHERE lead_id = ?1 AND kind = ?2 ORDER BY is_primary DESC, id ASC LIMIT 1))
+WHERE lead_id = ?1 AND kind = ?2
+`, leadID, kind); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+UPDATE leads
+SET `+col+` = COALESCE((SELECT value FROM lead_contacts WHERE lead_id = ?1 AND kind = ?2 AND is_primary = 1), '')
+WHERE id = ?1 AND `+col+` IS NOT COALESCE((SELECT value FROM lead_contacts WHERE lead_id = ?1 AND kind = ?2 AND is_primary = 1), '')
+`, leadID, kind)
+	return err
+}
+
+// ListLeadRelationships returns every link touching a lead, seen from its
+// side, grouped by kind.
+func (r *Repo) ListLeadRelationships(ctx context.Context, leadID int64) ([]Relationship, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT rl.id, rl.kind AS kind, rl.related_id, l.full_name AS name, 0
+FROM lead_relationships rl JOIN leads l ON l.id = rl.related_id
+WHERE rl.lead_id = ?1
+UNION ALL
+SELECT rl.id, rl.kind, rl.lead_id, l.full_name, 1
+FROM lead_relationships rl JOIN leads l ON l.id = rl.lead_id
+WHERE rl.related_id = ?1
+ORDER BY kind, name COLLATE NOCASE
+`, leadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Relationship
+	for rows.Next() {
+		rel := Relationship{LeadID: leadID}
+		if err := rows.Scan(&rel.ID, &rel.Kind, &rel.RelatedID, &rel.RelatedName, &rel.Inverse); err != nil {
+			return nil, err
+		}
+		if symmetricKinds[rel.Kind] {
+			rel.Inverse = false
+		}
+		out = append(out, rel)
+	}
+	return out, rows.Err()
+}
+
+// LinkLeads records that leadID's kind is relatedID ("#3's spouse is #4",
+// "#5 was referred by #2"). Linking twice is a no-op.
+func (r *Repo) LinkLeads(ctx context.Context, leadID int64, kind string, relatedID int64) error {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if !oneOf(kind, RelationshipKinds) {
+		return fmt.Errorf("unknown relationship %q (use %s)", kind, strings.Join(RelationshipKinds, "|"))
+	}
+	if leadID == relatedID {
+		return fmt.Errorf("a lead cannot be linked to itself")
+	}
+	for _, id := range []int64{leadID, relatedID} {
+		if _, err := r.GetLead(ctx, id); err == sql.ErrNoRows {
+			return fmt.Errorf("lead #%d not found", id)
+		} else if err != nil {
+			return err
+		}
+	}
+	_, err := r.db.ExecContext(ctx, `
+INSERT OR IGNORE INTO lead_relationships(lead_id, related_id, kind)
+SELECT ?1, ?2, ?3
+WHERE NOT (?4 AND EXISTS (SELECT 1 FROM lead_relationships WHERE lead_id = ?2 AND related_id = ?1 AND kind = ?3))
+`, leadID, relatedID, kind, symmetricKinds[kind])
+	return err
+}
+
+// UnlinkLeads removes the links between two leads in either direction,
+// only those of kind when it is not empty.
+func (r *Repo) UnlinkLeads(ctx context.Context, leadID, relatedID int64, kind string) error {
+	res, err := r.db.ExecContext(ctx, `
+DELETE FROM lead_relationships
+WHERE ((lead_id = ?1 AND related_id = ?2) OR (lead_id = ?2 AND related_id = ?1))
+  AND (?3 = '' OR kind = ?3)
+`, leadID, relatedID, strings.ToLower(strings.TrimSpace(kind)))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("lead #%d and #%d are not linked", leadID, relatedID)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -------- Custom fields --------
+
+// FieldKinds are the supported custom field types.
+var FieldKinds = []string{"text", "number", "money", "date", "choice"}
+
+func (r *Repo) ListCustomFields(ctx context.Context) ([]CustomField, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, kind, choices, sort FROM custom_fields ORDER BY sort ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CustomField
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// FindCustomField looks a field up by id or name (case-insensitive).
+func (r *Repo) FindCustomField(ctx context.Context, nameOrID string) (CustomField, error) {
+	nameOrID = strings.TrimSpace(nameOrID)
+	id, _ := strconv.ParseInt(nameOrID, 10, 64)
+	f, err := scanCustomField(r.db.QueryRowContext(ctx, `
+SELECT id, name, kind, choices, sort FROM custom_fields
+WHERE id = ? OR name = ?
+ORDER BY id = ? DESC
+LIMIT 1
+`, id, nameOrID, id))
+	if err == sql.ErrNoRows {
+		return CustomField{}, fmt.Errorf("custom field %q not found", nameOrID)
+	}
+	return f, err
+}
+
+// CreateCustomField defines a new field at the end of the list. Choice fields
+// need at least one choice; other kinds ignore choices.
+func (r *Repo) CreateCustomField(ctx context.Context, name, kind string, choices []string) (int64, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if name == "" {
+		return 0, fmt.Errorf("field name is required")
+	}
+	if strings.ContainsAny(name, "<>=~:\"") {
+		return 0, fmt.Errorf("field %q: name cannot contain < > = ~ : or quotes", name)
+	}
+	if !oneOf(kind, FieldKinds) {
+		return 0, fmt.Errorf("unknown field type %q (use %s)", kind, strings.Join(FieldKinds, "|"))
+	}
+
+	var clean []string
+	if kind == "choice" {
+		for _, c := range choices {
+			if c = strings.TrimSpace(c); c != "" {
+				clean = append(clean, c)
+			}
+		}
+		if len(clean) == 0 {
+			return 0, fmt.Errorf("choice field %q needs at least one choice", name)
+		}
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO custom_fields(name, kind, choices, sort)
+VALUES (?, ?, ?, (SELECT COALESCE(MAX(sort), 0) + 10 FROM custom_fields))
+`, name, kind, strings.Join(clean, ","))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return 0, fmt.Errorf("custom field %q already exists", name)
+		}
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// DeleteCustomField removes a field definition and every lead's value for it.
+func (r *Repo) DeleteCustomField(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM custom_fields WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("custom field #%d not found", id)
+	}
+	return nil
+}
+
+// LeadFieldValues returns every defined field with the lead's value for it.
+func (r *Repo) LeadFieldValues(ctx context.Context, leadID int64) ([]FieldValue, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT f.id, f.name, f.kind, f.choices, f.sort, COALESCE(v.value, '')
+FROM custom_fields f
+LEFT JOIN lead_field_values v ON v.field_id = f.id AND v.lead_id = ?
+ORDER BY f.sort ASC, f.id ASC
+`, leadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []FieldValue
+	for rows.Next() {
+		var fv FieldValue
+		var choices string
+		if err := rows.Scan(&fv.Field.ID, &fv.Field.Name, &fv.Field.Kind, &choices, &fv.Field.Sort, &fv.Value); err != nil {
+			return nil, err
+		}
+		fv.Field.Choices = splitChoices(choices)
+		out = append(out, fv)
+	}
+	return out, rows.Err()
+}
+
+// SetLeadFields validates and stores custom field values on a lead, keyed by
+// field id. An empty value clears the field. Nothing is written unless every
+// value is valid.
+func (r *Repo) SetLeadFields(ctx context.Context, leadID int64, values map[int64]string) error {
+	fields, err := r.ListCustomFields(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]CustomField, len(fields))
+	for _, f := range fields {
+		byID[f.ID] = f
+	}
+
+	clean := make(map[int64]string, len(values))
+	for id, raw := range values {
+		f, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("custom field #%d not found", id)
+		}
+		v, err := ParseFieldValue(f, raw)
+		if err != nil {
+			return err
+		}
+		clean[id] = v
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE leads SET updated_at = datetime('now') WHERE id = ?`, leadID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		_ = tx.Rollback()
+		return fmt.Errorf("lead #%d not found", leadID)
+	}
+	for id, v := range clean {
+		if err := setLeadField(ctx, tx, leadID, id, v); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// setLeadField stores one already parsed value; "" clears it.
+func setLeadField(ctx context.Context, ex execer, leadID, fieldID int64, v string) error {
+	var err error
+	if v == "" {
+		_, err = ex.ExecContext(ctx, `DELETE FROM lead_field_values WHERE lead_id = ? AND field_id = ?`, leadID, fieldID)
+	} else {
+		_, err = ex.ExecContext(ctx, `
+INSERT INTO lead_field_values(lead_id, field_id, value) VALUES (?, ?, ?)
+ON CONFLICT(lead_id, field_id) DO UPDATE SET value = excluded.value
+`, leadID, fieldID, v)
+	}
+	return err
+}
+
+// ParseFieldValue checks raw against the field's kind and returns the
+// canonical stored form: "$450k" becomes "450000", dates are YYYY-MM-DD and
+// choices take the spelling they were defined with.
+func ParseFieldValue(f CustomField, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	switch f.Kind {
+	case "number", "money":
+		n, err := ParseAmount(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s: %q is not a number", f.Name, raw)
+		}
+		if f.Kind == "money" {
+			n = math.Round(n*100) / 100
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case "date":
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return "", fmt.Errorf("%s: %q is not a date (use YYYY-MM-DD)", f.Name, raw)
+		}
+		return t.Format("2006-01-02"), nil
+	case "choice":
+		for _, c := range f.Choices {
+			if strings.EqualFold(c, raw) {
+				return c, nil
+			}
+		}
+		return "", fmt.Errorf("%s: %q is not one of %s", f.Name, raw, strings.Join(f.Choices, ", "))
+	}
+	return raw, nil
+}
+
+// FormatFieldValue renders a stored value for display, e.g. money as
+// "$450,000".
+func FormatFieldValue(f CustomField, v string) string {
+	if v == "" || f.Kind != "money" {
+		return v
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return FormatMoney(n)
+}
+
+// FormatMoney renders n as dollars with thousands separators, showing cents
+// only when there are any.
+func FormatMoney(n float64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	whole := int64(n)
+	cents := int64(math.Round((n - float64(whole)) * 100))
+	if cents == 100 {
+		whole, cents = whole+1, 0
+	}
+
+	digits := strconv.FormatInt(whole, 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if cents > 0 {
+		return fmt.Sprintf("%s$%s.%02d", sign, b.String(), cents)
+	}
+	return sign + "$" + b.String()
+}
+
+// ParseAmount reads numbers the way people type prices: "$450,000", "450k",
+// "1.2m".
+func ParseAmount(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		s, mult = strings.TrimSuffix(s, "k"), 1e3
+	case strings.HasSuffix(s, "m"):
+		s, mult = strings.TrimSuffix(s, "m"), 1e6
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("bad number %q", s)
+	}
+	return n * mult, nil
+}
+
+func splitChoices(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func scanCustomField(row rowScanner) (CustomField, error) {
+	var f CustomField
+	var choices string
+	if err := row.Scan(&f.ID, &f.Name, &f.Kind, &choices, &f.Sort); err != nil {
+		return CustomField{}, err
+	}
+	f.Choices = splitChoices(choices)
+	return f, nil
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// -------- Deals --------
+
+var (
+	DealSides    = []string{"buyer", "listing", "dual"}
+	DealStatuses = []string{"open", "closed", "fell-through"}
+)
+
+const dealSelect = `
+SELECT d.id, d.lead_id, l.full_name, COALESCE(d.property_id, 0), COALESCE(p.address, ''),
+       d.price, d.commission_rate, d.referral_pct, COALESCE(d.split_plan_id, 0), COALESCE(sp.name, ''),
+       d.side, d.status, d.contract_date, d.expected_close, d.closed_date, d.created_at, d.updated_at
+FROM deals d
+JOIN leads l ON l.id = d.lead_id
+LEFT JOIN properties p ON p.id = d.property_id
+LEFT JOIN split_plans sp ON sp.id = d.split_plan_id
+`
+
+// ListDeals returns deals of active leads, soonest expected close first. An
+// empty status lists all.
+func (r *Repo) ListDeals(ctx context.Context, status string) ([]Deal, error) {
+	return r.queryDeals(ctx, `
+WHERE l.archived_at IS NULL AND (? = '' OR d.status = ?)
+ORDER BY CASE WHEN d.expected_close IS NULL THEN 1 ELSE 0 END, d.expected_close ASC, d.id ASC
+`, status, status)
+}
+
+// ListLeadDeals returns a lead's deals, newest first.
+func (r *Repo) ListLeadDeals(ctx context.Context, leadID int64) ([]Deal, error) {
+	return r.queryDeals(ctx, `
+WHERE d.lead_id = ?
+ORDER BY d.created_at DESC, d.id DESC
+`, leadID)
+}
+
+func (r *Repo) GetDeal(ctx context.Context, id int64) (Deal, error) {
+	deals, err := r.queryDeals(ctx, `WHERE d.id = ?`, id)
+	if err != nil {
+		return Deal{}, err
+	}
+	if len(deals) == 0 {
+		return Deal{}, fmt.Errorf("deal #%d not found", id)
+	}
+	return deals[0], nil
+}
+
+func (r *Repo) CreateDeal(ctx context.Context, d Deal) (int64, error) {
+	if err := validateDeal(&d); err != nil {
+		return 0, err
+	}
+	if _, err := r.GetLead(ctx, d.LeadID); err == sql.ErrNoRows {
+		return 0, fmt.Errorf("lead #%d not found", d.LeadID)
+	} else if err != nil {
+		return 0, err
+	}
+	if d.PropertyID != 0 {
+		if _, err := r.GetProperty(ctx, d.PropertyID); err != nil {
+			return 0, err
+		}
+	}
+	if d.SplitPlanID != 0 {
+		if _, err := r.GetSplitPlan(ctx, d.SplitPlanID); err != nil {
+			return 0, err
+		}
+	}
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO deals(lead_id, property_id, price, commission_rate, referral_pct, split_plan_id, side, status, contract_date, expected_close, closed_date)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, d.LeadID, nullID(d.PropertyID), d.Price, d.CommissionRate, d.ReferralPct, nullID(d.SplitPlanID), d.Side, d.Status,
+		nullDate(d.ContractDate), nullDate(d.ExpectedClose), nullDate(d.ClosedDate))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// UpdateDeal saves every field of d (except the lead) and bumps updated_at.
+func (r *Repo) UpdateDeal(ctx context.Context, d Deal) error {
+	if err := validateDeal(&d); err != nil {
+		return err
+	}
+	if d.PropertyID != 0 {
+		if _, err := r.GetProperty(ctx, d.PropertyID); err != nil {
+			return err
+		}
+	}
+	if d.SplitPlanID != 0 {
+		if _, err := r.GetSplitPlan(ctx, d.SplitPlanID); err != nil {
+			return err
+		}
+	}
+	res, err := r.db.ExecContext(ctx, `
+UPDATE deals
+SET property_id = ?, price = ?, commission_rate = ?, referral_pct = ?, split_plan_id = ?, side = ?, status = ?,
+    contract_date = ?, expected_close = ?, closed_date = ?, updated_at = datetime('now')
+WHERE id = ?
+`, nullID(d.PropertyID), d.Price, d.CommissionRate, d.ReferralPct, nullID(d.SplitPlanID), d.Side, d.Status,
+		nullDate(d.ContractDate), nullDate(d.ExpectedClose), nullDate(d.ClosedDate), d.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("deal #%d not found", d.ID)
+	}
+	return nil
+}
+
+func (r *Repo) DeleteDeal(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM deals WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("deal #%d not found", id)
+	}
+	return nil
+}
+
+// HasOpenDeal reports whether the lead already has a deal in progress.
+func (r *Repo) HasOpenDeal(ctx context.Context, leadID int64) (bool, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM deals WHERE lead_id = ? AND status = 'open'`, leadID).Scan(&n)
+	return n > 0, err
+}
+
+func (r *Repo) queryDeals(ctx context.Context, where string, args ...any) ([]Deal, error) {
+	rows, err := r.db.QueryContext(ctx, dealSelect+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Deal
+	for rows.Next() {
+		var d Deal
+		var contract, expected, closed sql.NullString
+		var created, updated string
+		if err := rows.Scan(&d.ID, &d.LeadID, &d.LeadName, &d.PropertyID, &d.PropertyAddress,
+			&d.Price, &d.CommissionRate, &d.ReferralPct, &d.SplitPlanID, &d.SplitPlanName,
+			&d.Side, &d.Status, &contract, &expected, &closed, &created, &updated); err != nil {
+			return nil, err
+		}
+		d.ContractDate = optDate(contract)
+		d.ExpectedClose = optDate(expected)
+		d.ClosedDate = optDate(closed)
+		d.CreatedAt = mustParseTime(created)
+		d.UpdatedAt = mustParseTime(updated)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func validateDeal(d *Deal) error {
+	d.Side = strings.ToLower(strings.TrimSpace(d.Side))
+	d.Status = strings.ToLower(strings.TrimSpace(d.Status))
+	if d.Side == "" {
+		d.Side = "buyer"
+	}
+	if d.Status == "" {
+		d.Status = "open"
+	}
+	if !oneOf(d.Side, DealSides) {
+		return fmt.Errorf("unknown side %q (use %s)", d.Side, strings.Join(DealSides, "|"))
+	}
+	if !oneOf(d.Status, DealStatuses) {
+		return fmt.Errorf("unknown deal status %q (use %s)", d.Status, strings.Join(DealStatuses, "|"))
+	}
+	if d.Price < 0 {
+		return fmt.Errorf("price cannot be negative")
+	}
+	if d.CommissionRate < 0 || d.CommissionRate > 100 {
+		return fmt.Errorf("commission rate %.2f%% is out of range", d.CommissionRate)
+	}
+	if d.ReferralPct < 0 || d.ReferralPct > 100 {
+		return fmt.Errorf("referral fee %.2f%% is out of range", d.ReferralPct)
+	}
+	if d.Status == "closed" && d.ClosedDate == nil {
+		now := time.Now()
+		d.ClosedDate = &now
+	}
+	return nil
+}
+
+// nullID stores 0 as NULL for optional foreign keys.
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// optDate reads an optional YYYY-MM-DD column.
+func optDate(ns sql.NullString) *time.Time {
+	if !ns.Valid || ns.String == "" {
+		return nil
+	}
+	t := mustParseDate(ns.String)
+	return &t
+}
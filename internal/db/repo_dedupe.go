@@ -0,0 +1,360 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// -------- Duplicates & merge --------
+
+// DuplicateMatch is an existing lead that looks like the same person, with
+// why: "phone", "email" and/or "name".
+type DuplicateMatch struct {
+	Lead    Lead
+	Reasons []string
+}
+
+// DuplicatePair is two active leads that look like the same person.
+type DuplicatePair struct {
+	A, B    Lead
+	Reasons []string
+}
+
+// DuplicateError is returned by CreateLead when the new lead looks like one
+// already on file. CreateLeadAllowDuplicate skips the check.
+type DuplicateError struct {
+	Matches []DuplicateMatch
+}
+
+func (e *DuplicateError) Error() string {
+	m := e.Matches[0]
+	msg := fmt.Sprintf("possible duplicate of #%d %s (same %s)", m.Lead.ID, m.Lead.FullName, strings.Join(m.Reasons, ", "))
+	if len(e.Matches) > 1 {
+		msg += fmt.Sprintf(" and %d more", len(e.Matches)-1)
+	}
+	return msg
+}
+
+// dupeKeys is what duplicate detection compares for one lead.
+type dupeKeys struct {
+	lead   Lead
+	phones []string
+	emails []string
+	name   string
+}
+
+// NormalizePhoneKey reduces a phone number to its last ten digits, so
+// "+1 (555) 123-4567" and "555.123.4567" compare equal. Numbers with fewer
+// than seven digits give "".
+func NormalizePhoneKey(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	d := b.String()
+	if len(d) < 7 {
+		return ""
+	}
+	if len(d) > 10 {
+		d = d[len(d)-10:]
+	}
+	return d
+}
+
+// normalizeName lowercases a name, drops punctuation and sorts the words,
+// so "Lee, Ann" and "ann lee" compare equal.
+func normalizeName(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// namesMatch is the fuzzy name test: equal after normalizing, or within one
+// edit per eight characters for names of at least five characters.
+func namesMatch(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	n := max(len([]rune(a)), len([]rune(b)))
+	if n < 5 {
+		return false
+	}
+	return levenshtein(a, b)*8 <= n
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func newDupeKeys(l Lead, phones, emails []string) dupeKeys {
+	k := dupeKeys{lead: l, name: normalizeName(l.FullName)}
+	for _, p := range append([]string{l.Phone}, phones...) {
+		if key := NormalizePhoneKey(p); key != "" {
+			k.phones = append(k.phones, key)
+		}
+	}
+	for _, e := range append([]string{l.Email}, emails...) {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			k.emails = append(k.emails, e)
+		}
+	}
+	return k
+}
+
+// reasons lists what a and b have in common, or nil.
+func (a dupeKeys) reasons(b dupeKeys) []string {
+	var out []string
+	if overlaps(a.phones, b.phones) {
+		out = append(out, "phone")
+	}
+	if overlaps(a.emails, b.emails) {
+		out = append(out, "email")
+	}
+	if namesMatch(a.name, b.name) {
+		out = append(out, "name")
+	}
+	return out
+}
+
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// activeDupeKeys loads every active lead with all its phones and emails.
+func (r *Repo) activeDupeKeys(ctx context.Context) ([]dupeKeys, error) {
+	leads, err := r.ListLeads(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT lead_id, kind, value FROM lead_contacts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	phones, emails := map[int64][]string{}, map[int64][]string{}
+	for rows.Next() {
+		var id int64
+		var kind, value string
+		if err := rows.Scan(&id, &kind, &value); err != nil {
+			return nil, err
+		}
+		if kind == "phone" {
+			phones[id] = append(phones[id], value)
+		} else {
+			emails[id] = append(emails[id], value)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]dupeKeys, 0, len(leads))
+	for _, l := range leads {
+		out = append(out, newDupeKeys(l, phones[l.ID], emails[l.ID]))
+	}
+	return out, nil
+}
+
+// FindDuplicates returns active leads that look like l: same phone (by
+// digits), same email (case-insensitive) or a near-identical name. l itself
+// is skipped when it has an ID. Phone and email matches come first.
+func (r *Repo) FindDuplicates(ctx context.Context, l Lead) ([]DuplicateMatch, error) {
+	all, err := r.activeDupeKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	self := newDupeKeys(l, nil, nil)
+	for _, k := range all {
+		if l.ID != 0 && k.lead.ID == l.ID {
+			self = k
+		}
+	}
+
+	var out []DuplicateMatch
+	for _, k := range all {
+		if l.ID != 0 && k.lead.ID == l.ID {
+			continue
+		}
+		if why := self.reasons(k); len(why) > 0 {
+			out = append(out, DuplicateMatch{Lead: k.lead, Reasons: why})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return strongerMatch(out[i].Reasons, out[j].Reasons) })
+	return out, nil
+}
+
+// DuplicateReport pairs up every two active leads that look like the same
+// person, strongest matches first.
+func (r *Repo) DuplicateReport(ctx context.Context) ([]DuplicatePair, error) {
+	all, err := r.activeDupeKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].lead.ID < all[j].lead.ID })
+
+	var out []DuplicatePair
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			if why := all[i].reasons(all[j]); len(why) > 0 {
+				out = append(out, DuplicatePair{A: all[i].lead, B: all[j].lead, Reasons: why})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return strongerMatch(out[i].Reasons, out[j].Reasons) })
+	return out, nil
+}
+
+// strongerMatch orders contact matches before name-only ones, then by the
+// number of things in common.
+func strongerMatch(a, b []string) bool {
+	nameOnly := func(r []string) bool { return len(r) == 1 && r[0] == "name" }
+	if nameOnly(a) != nameOnly(b) {
+		return !nameOnly(a)
+	}
+	return len(a) > len(b)
+}
+
+// MergeLeads folds dropID into keepID in one transaction and deletes dropID.
+// Notes, tasks, stage history and deals move over; tags, phones, emails,
+// custom field values, properties and relationships are added where keepID
+// lacks them; blank source, notes and follow-up are filled in. A note on
+// keepID records the merge.
+func (r *Repo) MergeLeads(ctx context.Context, keepID, dropID int64) error {
+	if keepID == dropID {
+		return fmt.Errorf("cannot merge lead #%d into itself", keepID)
+	}
+	keep, err := r.GetLead(ctx, keepID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("lead #%d not found", keepID)
+	} else if err != nil {
+		return err
+	}
+	drop, err := r.GetLead(ctx, dropID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("lead #%d not found", dropID)
+	} else if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmts := []string{
+		`UPDATE notes SET lead_id = ?1 WHERE lead_id = ?2`,
+		`UPDATE tasks SET lead_id = ?1 WHERE lead_id = ?2`,
+		`UPDATE stage_history SET lead_id = ?1 WHERE lead_id = ?2`,
+		`UPDATE deals SET lead_id = ?1 WHERE lead_id = ?2`,
+		`INSERT OR IGNORE INTO lead_tags(lead_id, tag_id) SELECT ?1, tag_id FROM lead_tags WHERE lead_id = ?2`,
+		`INSERT OR IGNORE INTO lead_field_values(lead_id, field_id, value) SELECT ?1, field_id, value FROM lead_field_values WHERE lead_id = ?2`,
+		`INSERT OR IGNORE INTO lead_properties(lead_id, property_id, role, created_at) SELECT ?1, property_id, role, created_at FROM lead_properties WHERE lead_id = ?2`,
+		`INSERT INTO lead_contacts(lead_id, kind, label, value, is_primary, created_at)
+SELECT ?1, c.kind, c.label, c.value, 0, c.created_at FROM lead_contacts c
+WHERE c.lead_id = ?2
+  AND NOT EXISTS (SELECT 1 FROM lead_contacts k WHERE k.lead_id = ?1 AND k.kind = c.kind AND lower(k.value) = lower(c.value))
+ORDER BY c.is_primary DESC, c.id`,
+		`INSERT OR IGNORE INTO lead_relationships(lead_id, related_id, kind, created_at)
+SELECT CASE lead_id WHEN ?2 THEN ?1 ELSE lead_id END, CASE related_id WHEN ?2 THEN ?1 ELSE related_id END, kind, created_at
+FROM lead_relationships
+WHERE (lead_id = ?2 AND related_id <> ?1) OR (related_id = ?2 AND lead_id <> ?1)`,
+		`UPDATE leads SET
+  source = CASE WHEN source = '' THEN (SELECT source FROM leads WHERE id = ?2) ELSE source END,
+  notes = CASE
+    WHEN (SELECT notes FROM leads WHERE id = ?2) = '' THEN notes
+    WHEN notes = '' THEN (SELECT notes FROM leads WHERE id = ?2)
+    ELSE notes || char(10) || char(10) || (SELECT notes FROM leads WHERE id = ?2) END,
+  next_follow_up = COALESCE(NULLIF(next_follow_up, ''), (SELECT next_follow_up FROM leads WHERE id = ?2)),
+  last_contacted = CASE
+    WHEN COALESCE(last_contacted, '') >= COALESCE((SELECT last_contacted FROM leads WHERE id = ?2), '') THEN last_contacted
+    ELSE (SELECT last_contacted FROM leads WHERE id = ?2) END,
+  updated_at = datetime('now')
+WHERE id = ?1`,
+	}
+	for _, s := range stmts {
+		if _, err := tx.ExecContext(ctx, s, keepID, dropID); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("merge: %w", err)
+		}
+	}
+	for _, kind := range ContactKinds {
+		if err := syncPrimaryContact(ctx, tx, keepID, kind); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	// The folded-in history may end in another stage; log the kept lead's
+	// stage again so the timeline and time-in-stage stay right.
+	var lastStage int64
+	if err := tx.QueryRowContext(ctx, `
+SELECT to_stage_id FROM stage_history WHERE lead_id = ? ORDER BY changed_at DESC, id DESC LIMIT 1
+`, keepID).Scan(&lastStage); err != nil && err != sql.ErrNoRows {
+		_ = tx.Rollback()
+		return err
+	}
+	if lastStage != keep.StageID {
+		if err := recordStageChange(ctx, tx, keepID, lastStage, keep.StageID); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	summary := fmt.Sprintf("Merged lead #%d %s into this lead", drop.ID, drop.FullName)
+	if details := strings.Join(nonEmpty(drop.Phone, drop.Email, drop.Source), " • "); details != "" {
+		summary += " (" + details + ")"
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO notes(lead_id, body) VALUES (?, ?)`, keepID, summary); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM leads WHERE id = ?`, dropID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func nonEmpty(ss ...string) []string {
+	var out []string
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
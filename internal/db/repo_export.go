@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"strings"
+)
+
+// -------- Export --------
+
+// exportPageSize is how many leads an export holds in memory at a time.
+const exportPageSize = 200
+
+// ExportFilter picks the leads an export covers; empty fields match all.
+type ExportFilter struct {
+	Stage    string // stage name or id, optionally "pipeline/stage"
+	Type     string
+	Source   string
+	Archived bool // include leads in the trash
+}
+
+func (f ExportFilter) where() (string, []any) {
+	conds := []string{"1 = 1"}
+	var args []any
+	if !f.Archived {
+		conds = append(conds, "l.archived_at IS NULL")
+	}
+	if stage := strings.TrimSpace(f.Stage); stage != "" {
+		if pipeline, name, ok := strings.Cut(stage, "/"); ok {
+			conds = append(conds, "lower(p.name) = lower(?) AND lower(s.name) = lower(?)")
+			args = append(args, strings.TrimSpace(pipeline), strings.TrimSpace(name))
+		} else {
+			conds = append(conds, "(lower(s.name) = lower(?) OR CAST(s.id AS TEXT) = ?)")
+			args = append(args, stage, stage)
+		}
+	}
+	if t := strings.TrimSpace(f.Type); t != "" {
+		conds = append(conds, "l.lead_type = lower(?)")
+		args = append(args, t)
+	}
+	if src := strings.TrimSpace(f.Source); src != "" {
+		conds = append(conds, "lower(l.source) = lower(?)")
+		args = append(args, src)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// ExportLead is a lead with everything attached to it.
+type ExportLead struct {
+	Lead          Lead
+	Contacts      []ContactMethod
+	Fields        []FieldValue // set values only
+	Notes         []Note       // oldest first
+	Tasks         []Task
+	Deals         []Deal
+	Properties    []PropertyLink
+	Relationships []Relationship
+}
+
+// ExportLeads calls fn for each lead matching f, in id order. Leads are read
+// a page at a time so large databases never sit in memory at once; with
+// related set, each lead also comes with its notes, tasks, deals,
+// properties and relationships; custom fields and contacts are always
+// loaded.
+func (r *Repo) ExportLeads(ctx context.Context, f ExportFilter, related bool, fn func(ExportLead) error) error {
+	where, args := f.where()
+	var after int64
+	for {
+		rows, err := r.db.QueryContext(ctx, leadSelect+`
+WHERE `+where+` AND l.id > ?
+ORDER BY l.id ASC
+LIMIT ?
+`, append(args, after, exportPageSize)...)
+		if err != nil {
+			return err
+		}
+		page, err := scanLeads(rows)
+		if err != nil {
+			return err
+		}
+
+		for _, l := range page {
+			e, err := r.exportLead(ctx, l, related)
+			if err != nil {
+				return err
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
+
+func (r *Repo) exportLead(ctx context.Context, l Lead, related bool) (ExportLead, error) {
+	e := ExportLead{Lead: l}
+	values, err := r.LeadFieldValues(ctx, l.ID)
+	if err != nil {
+		return e, err
+	}
+	for _, fv := range values {
+		if fv.Value != "" {
+			e.Fields = append(e.Fields, fv)
+		}
+	}
+	if e.Contacts, err = r.ListLeadContacts(ctx, l.ID); err != nil || !related {
+		return e, err
+	}
+
+	if e.Notes, err = r.ListNotes(ctx, l.ID); err != nil {
+		return e, err
+	}
+	for i, j := 0, len(e.Notes)-1; i < j; i, j = i+1, j-1 {
+		e.Notes[i], e.Notes[j] = e.Notes[j], e.Notes[i]
+	}
+	if e.Tasks, err = r.ListTasksForLead(ctx, l.ID); err != nil {
+		return e, err
+	}
+	if e.Deals, err = r.ListLeadDeals(ctx, l.ID); err != nil {
+		return e, err
+	}
+	if e.Properties, err = r.ListLeadProperties(ctx, l.ID); err != nil {
+		return e, err
+	}
+	e.Relationships, err = r.ListLeadRelationships(ctx, l.ID)
+	return e, err
+}
+
+// ExportNotes calls fn for each note on a lead matching f, with the lead's
+// name, by lead then date.
+func (r *Repo) ExportNotes(ctx context.Context, f ExportFilter, fn func(n Note, leadName string) error) error {
+	where, args := f.where()
+	rows, err := r.db.QueryContext(ctx, `
+SELECT n.id, n.lead_id, n.body, n.created_at, l.full_name
+FROM notes n
+JOIN leads l ON l.id = n.lead_id
+JOIN stages s ON s.id = l.stage_id
+JOIN pipelines p ON p.id = s.pipeline_id
+WHERE `+where+`
+ORDER BY n.lead_id ASC, n.created_at ASC, n.id ASC
+`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n Note
+		var created, name string
+		if err := rows.Scan(&n.ID, &n.LeadID, &n.Body, &created, &name); err != nil {
+			return err
+		}
+		n.CreatedAt = mustParseTime(created)
+		if err := fn(n, name); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ExportTasks calls fn for each task, open or done, on a lead matching f,
+// by lead then due date.
+func (r *Repo) ExportTasks(ctx context.Context, f ExportFilter, fn func(Task) error) error {
+	where, args := f.where()
+	rows, err := r.db.QueryContext(ctx, taskSelect+`
+JOIN stages s ON s.id = l.stage_id
+JOIN pipelines p ON p.id = s.pipeline_id
+WHERE `+where+`
+ORDER BY t.lead_id ASC, COALESCE(t.due_date, '9999') ASC, t.id ASC
+`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
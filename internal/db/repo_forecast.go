@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"sort"
+)
+
+// -------- Forecast --------
+
+// Forecast returns every open deal on an active lead, weighted by the win
+// probability of the lead's current stage. Items follow board order
+// (pipeline, then stage sort, as ListStages), then expected close.
+func (r *Repo) Forecast(ctx context.Context) ([]ForecastItem, error) {
+	stages, err := r.ListStages(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	pipelines, err := r.ListPipelines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pipeName := make(map[int64]string, len(pipelines))
+	for _, p := range pipelines {
+		pipeName[p.ID] = p.Name
+	}
+	order := make(map[int64]int, len(stages))
+	byID := make(map[int64]Stage, len(stages))
+	for i, s := range stages {
+		order[s.ID] = i
+		byID[s.ID] = s
+	}
+
+	deals, err := r.ListDeals(ctx, "open")
+	if err != nil {
+		return nil, err
+	}
+	stageOf, err := r.leadStages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ForecastItem, 0, len(deals))
+	for _, d := range deals {
+		st := byID[stageOf[d.LeadID]]
+		out = append(out, ForecastItem{
+			Deal:     d,
+			StageID:  st.ID,
+			Stage:    st.Name,
+			Pipeline: pipeName[st.PipelineID],
+			WinProb:  st.WinProb,
+		})
+	}
+	// ListDeals already sorts by expected close; keep that within a stage.
+	sort.SliceStable(out, func(i, j int) bool { return order[out[i].StageID] < order[out[j].StageID] })
+	return out, nil
+}
+
+// ForecastByMonth totals forecast items per expected-close month, earliest
+// first, with undated deals last.
+func ForecastByMonth(items []ForecastItem) []ForecastMonth {
+	byMonth := map[string]*ForecastMonth{}
+	for _, it := range items {
+		month := ""
+		if it.Deal.ExpectedClose != nil {
+			month = it.Deal.ExpectedClose.Format("2006-01")
+		}
+		fm, ok := byMonth[month]
+		if !ok {
+			fm = &ForecastMonth{Month: month}
+			byMonth[month] = fm
+		}
+		fm.Deals++
+		fm.GCI += it.Deal.GrossCommission()
+		fm.Weighted += it.Weighted()
+	}
+
+	out := make([]ForecastMonth, 0, len(byMonth))
+	for _, fm := range byMonth {
+		out = append(out, *fm)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if (out[i].Month == "") != (out[j].Month == "") {
+			return out[j].Month == ""
+		}
+		return out[i].Month < out[j].Month
+	})
+	return out
+}
+
+// LeadExpectedValue is the weighted GCI of a lead's open deals at its
+// current stage.
+func (r *Repo) LeadExpectedValue(ctx context.Context, leadID int64) (float64, error) {
+	var v float64
+	err := r.db.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(d.price * d.commission_rate / 100.0 * s.win_probability / 100.0), 0)
+FROM deals d
+JOIN leads l ON l.id = d.lead_id
+JOIN stages s ON s.id = l.stage_id
+WHERE d.lead_id = ? AND d.status = 'open'
+`, leadID).Scan(&v)
+	return v, err
+}
+
+func (r *Repo) leadStages(ctx context.Context) (map[int64]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, stage_id FROM leads WHERE archived_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int64]int64)
+	for rows.Next() {
+		var id, stageID int64
+		if err := rows.Scan(&id, &stageID); err != nil {
+			return nil, err
+		}
+		out[id] = stageID
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// -------- Stage history --------
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// recordStageChange logs a lead entering toStageID. fromStageID is 0 for a
+// brand new lead.
+func recordStageChange(ctx context.Context, ex execer, leadID, fromStageID, toStageID int64) error {
+	var from any
+	if fromStageID != 0 {
+		from = fromStageID
+	}
+	_, err := ex.ExecContext(ctx, `
+INSERT INTO stage_history(lead_id, from_stage_id, from_stage, to_stage_id, to_stage)
+VALUES (?, ?,
+        COALESCE((SELECT name FROM stages WHERE id = ?), ''),
+        ?, (SELECT name FROM stages WHERE id = ?))
+`, leadID, from, fromStageID, toStageID, toStageID)
+	return err
+}
+
+// ListStageHistory returns a lead's stage timeline, oldest first.
+func (r *Repo) ListStageHistory(ctx context.Context, leadID int64) ([]StageChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, lead_id, COALESCE(from_stage_id, 0), from_stage, to_stage_id, to_stage, changed_at
+FROM stage_history
+WHERE lead_id = ?
+ORDER BY changed_at ASC, id ASC
+`, leadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []StageChange
+	for rows.Next() {
+		var c StageChange
+		var changed string
+		if err := rows.Scan(&c.ID, &c.LeadID, &c.FromStageID, &c.FromStage, &c.ToStageID, &c.ToStage, &changed); err != nil {
+			return nil, err
+		}
+		c.ChangedAt = mustParseTime(changed)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
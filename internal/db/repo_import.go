@@ -0,0 +1,739 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// -------- CSV import --------
+
+// ImportTargets are the lead attributes a CSV column can be mapped to.
+// Custom fields are mapped as "field:<name>".
+var ImportTargets = []string{
+	"name", "first_name", "last_name", "phone", "email", "type", "source",
+	"stage", "status", "follow_up", "notes", "tags",
+}
+
+// DuplicateActions say what an import does with a row that matches a lead
+// already on file: leave it, update that lead, or create another lead.
+var DuplicateActions = []string{"skip", "update", "create"}
+
+// importAliases are spreadsheet and CRM-export headers that map themselves
+// when no explicit mapping names the target.
+var importAliases = map[string]string{
+	"full name": "name", "contact": "name", "contact name": "name", "client": "name",
+	"first": "first_name", "firstname": "first_name", "given name": "first_name",
+	"last": "last_name", "lastname": "last_name", "surname": "last_name", "family name": "last_name",
+	"mobile": "phone", "cell": "phone", "phone number": "phone", "mobile phone": "phone", "telephone": "phone",
+	"e-mail": "email", "email address": "email",
+	"lead type": "type", "lead source": "source", "pipeline stage": "stage", "lead status": "status",
+	"follow up": "follow_up", "follow-up": "follow_up", "next follow up": "follow_up", "next follow-up": "follow_up",
+	"note": "notes", "comments": "notes", "tag": "tags", "labels": "tags",
+}
+
+// ImportSpec says how to read a CSV into leads.
+type ImportSpec struct {
+	Mapping     map[string]string // target -> CSV header; other targets are matched by header name
+	StageMap    map[string]string // CSV value -> stage name, case-insensitive
+	TypeMap     map[string]string // CSV value -> lead type, case-insensitive
+	OnDuplicate string            // skip|update|create; default skip
+	Tag         string            // added to every created or updated lead
+}
+
+// ImportRecord is one incoming lead: values keyed by import target, and
+// labelled phones and emails for sources that carry several. Without a
+// "phone" or "email" value the first contact of each kind is the primary.
+type ImportRecord struct {
+	Line     int // CSV row number (the header is row 1) or card number
+	Values   map[string]string
+	Contacts []ContactMethod
+}
+
+// ImportRow is what importing one record will do.
+type ImportRow struct {
+	Line     int
+	Action   string // create|update|skip|error
+	Lead     Lead   // the lead as it will be written
+	Match    Lead   // for update and skip: the lead on file
+	Tags     []string
+	Fields   map[int64]string
+	Contacts []ContactMethod // phones and emails to add, with their labels
+	Changes  []string        // what an update changes, for the preview
+	Reason   string          // why the row is skipped or rejected
+}
+
+// ImportPlan is the outcome of PlanImport: nothing has been written yet.
+type ImportPlan struct {
+	Columns  map[string]string // target -> header it was read from
+	Unmapped []string          // headers nothing was read from
+	Rows     []ImportRow
+}
+
+// Count returns how many rows will take action.
+func (p ImportPlan) Count(action string) int {
+	n := 0
+	for _, row := range p.Rows {
+		if row.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// PlanImport works out what importing records (the CSV rows after header)
+// would do, without writing anything. Rows that cannot be imported get
+// Action "error"; problems with the spec itself are returned as an error.
+func (r *Repo) PlanImport(ctx context.Context, spec ImportSpec, header []string, records [][]string) (ImportPlan, error) {
+	fields, err := r.ListCustomFields(ctx)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	cols, plan, err := mapImportColumns(spec.Mapping, header, fields)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+
+	recs := make([]ImportRecord, 0, len(records))
+	for i, rec := range records {
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		values := make(map[string]string, len(cols))
+		for target, col := range cols {
+			if col < len(rec) {
+				values[target] = rec[col]
+			}
+		}
+		recs = append(recs, ImportRecord{Line: i + 2, Values: values})
+	}
+	rows, err := r.PlanImportRecords(ctx, spec, recs)
+	plan.Rows = rows.Rows
+	return plan, err
+}
+
+// PlanImportRecords is PlanImport for records read by the caller, as from a
+// vCard file. Mapping in spec is not used.
+func (r *Repo) PlanImportRecords(ctx context.Context, spec ImportSpec, recs []ImportRecord) (ImportPlan, error) {
+	spec.OnDuplicate = strings.ToLower(defaultString(spec.OnDuplicate, "skip"))
+	if !oneOf(spec.OnDuplicate, DuplicateActions) {
+		return ImportPlan{}, fmt.Errorf("unknown duplicate action %q (use %s)", spec.OnDuplicate, strings.Join(DuplicateActions, "|"))
+	}
+	var tags []string
+	if spec.Tag != "" {
+		t, err := normalizeTag(spec.Tag)
+		if err != nil {
+			return ImportPlan{}, err
+		}
+		tags = append(tags, t)
+	}
+
+	fields, err := r.ListCustomFields(ctx)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	region, err := r.PhoneRegion(ctx)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	existing, err := r.activeDupeKeys(ctx)
+	if err != nil {
+		return ImportPlan{}, err
+	}
+
+	p := importPlanner{
+		r: r, ctx: ctx, spec: spec, fields: fields, region: region, tags: tags,
+		existing: existing, stages: map[string]importStage{}, updated: map[int64]int{},
+	}
+	var plan ImportPlan
+	for _, rec := range recs {
+		row := p.planRow(rec)
+		row.Line = rec.Line
+		if row.Action == "create" {
+			p.created = append(p.created, row.dupeKeys())
+			p.createdLines = append(p.createdLines, row.Line)
+		}
+		if row.Action == "update" {
+			p.updated[row.Match.ID] = row.Line
+		}
+		plan.Rows = append(plan.Rows, row)
+	}
+	return plan, nil
+}
+
+// mapImportColumns resolves explicit mappings, then matches the remaining
+// targets to headers by name, alias or custom field name.
+func mapImportColumns(mapping map[string]string, header []string, fields []CustomField) (map[string]int, ImportPlan, error) {
+	plan := ImportPlan{Columns: map[string]string{}}
+	index := map[string]int{}
+	for i, h := range header {
+		if _, dup := index[importKey(h)]; !dup {
+			index[importKey(h)] = i
+		}
+	}
+	fieldTarget := func(name string) (string, bool) {
+		for _, f := range fields {
+			if strings.EqualFold(f.Name, name) {
+				return "field:" + f.Name, true
+			}
+		}
+		return "", false
+	}
+
+	cols := map[string]int{}
+	used := map[int]bool{}
+	for target, h := range mapping {
+		target = strings.ToLower(strings.TrimSpace(target))
+		if name, ok := strings.CutPrefix(target, "field:"); ok {
+			t, found := fieldTarget(strings.TrimSpace(name))
+			if !found {
+				return nil, plan, fmt.Errorf("custom field %q not found", strings.TrimSpace(name))
+			}
+			target = t
+		} else if !oneOf(target, ImportTargets) {
+			return nil, plan, fmt.Errorf("unknown import target %q (use %s or field:<name>)", target, strings.Join(ImportTargets, "|"))
+		}
+		i, ok := index[importKey(h)]
+		if !ok {
+			return nil, plan, fmt.Errorf("column %q not found in the CSV header", h)
+		}
+		cols[target] = i
+		used[i] = true
+	}
+
+	for i, h := range header {
+		if used[i] {
+			continue
+		}
+		key := importKey(h)
+		target := strings.ReplaceAll(key, " ", "_")
+		if !oneOf(target, ImportTargets) {
+			target = importAliases[key]
+		}
+		if target == "" {
+			target, _ = fieldTarget(strings.TrimSpace(h))
+		}
+		if _, taken := cols[target]; target == "" || taken {
+			continue
+		}
+		cols[target] = i
+		used[i] = true
+	}
+
+	_, name := cols["name"]
+	_, first := cols["first_name"]
+	_, last := cols["last_name"]
+	if !name && !first && !last {
+		return nil, plan, fmt.Errorf("no name column found; map one with name=<header>")
+	}
+	for target, i := range cols {
+		plan.Columns[target] = header[i]
+	}
+	for i, h := range header {
+		if !used[i] {
+			plan.Unmapped = append(plan.Unmapped, h)
+		}
+	}
+	return cols, plan, nil
+}
+
+func importKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(s, "_", " "))), " ")
+}
+
+// importPlanner carries lookups shared by every row of one PlanImport.
+type importPlanner struct {
+	r        *Repo
+	ctx      context.Context
+	spec     ImportSpec
+	fields   []CustomField
+	region   string
+	tags     []string
+	existing []dupeKeys
+	stages   map[string]importStage // "type\x00name" -> stage
+
+	created      []dupeKeys // rows already planned for creation
+	createdLines []int
+	updated      map[int64]int // lead id -> line that updates it
+}
+
+func (p *importPlanner) cell(rec ImportRecord, target string) string {
+	return strings.TrimSpace(rec.Values[target])
+}
+
+func (p *importPlanner) planRow(rec ImportRecord) ImportRow {
+	row, err := p.readRow(rec)
+	if err == nil {
+		err = p.matchRow(&row)
+	}
+	if err != nil {
+		row.Action, row.Reason = "error", err.Error()
+	}
+	return row
+}
+
+// matchRow decides between create, update and skip by comparing the row
+// with leads on file and rows planned before it.
+func (p *importPlanner) matchRow(row *ImportRow) error {
+	keys := row.dupeKeys()
+	if p.spec.OnDuplicate != "create" {
+		for i, k := range p.created {
+			if why := keys.reasons(k); len(why) > 0 {
+				row.Action = "skip"
+				row.Reason = fmt.Sprintf("repeats row %d (same %s)", p.createdLines[i], strings.Join(why, ", "))
+				return nil
+			}
+		}
+	}
+	var matches []DuplicateMatch
+	for _, k := range p.existing {
+		if why := keys.reasons(k); len(why) > 0 {
+			matches = append(matches, DuplicateMatch{Lead: k.lead, Reasons: why})
+		}
+	}
+	if len(matches) == 0 || p.spec.OnDuplicate == "create" {
+		if len(matches) > 0 {
+			sort.SliceStable(matches, func(i, j int) bool { return strongerMatch(matches[i].Reasons, matches[j].Reasons) })
+			row.Reason = "possible duplicate of " + describeMatch(matches[0])
+		}
+		row.Action = "create"
+		return p.planCreate(row)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return strongerMatch(matches[i].Reasons, matches[j].Reasons) })
+	m := matches[0]
+	row.Match = m.Lead
+
+	if p.spec.OnDuplicate == "skip" {
+		row.Action, row.Reason = "skip", "duplicate of "+describeMatch(m)
+		return nil
+	}
+	if line, ok := p.updated[m.Lead.ID]; ok {
+		row.Action = "skip"
+		row.Reason = fmt.Sprintf("#%d %s is already updated by row %d", m.Lead.ID, m.Lead.FullName, line)
+		return nil
+	}
+	row.Action = "update"
+	return p.planUpdate(row)
+}
+
+// dupeKeys covers every phone and email on the row, not just the primaries.
+func (row ImportRow) dupeKeys() dupeKeys {
+	var phones, emails []string
+	for _, c := range row.Contacts {
+		if c.Kind == "phone" {
+			phones = append(phones, c.Value)
+		} else {
+			emails = append(emails, c.Value)
+		}
+	}
+	return newDupeKeys(row.Lead, phones, emails)
+}
+
+func describeMatch(m DuplicateMatch) string {
+	return fmt.Sprintf("#%d %s (same %s)", m.Lead.ID, m.Lead.FullName, strings.Join(m.Reasons, ", "))
+}
+
+// readRow turns one record into a lead with its tags and custom fields,
+// applying the spec's transforms. Cells left blank stay blank; the stage
+// is only named, not looked up, until the lead type is known.
+func (p *importPlanner) readRow(rec ImportRecord) (ImportRow, error) {
+	row := ImportRow{Fields: map[int64]string{}}
+	l := &row.Lead
+
+	l.FullName = p.cell(rec, "name")
+	if l.FullName == "" {
+		l.FullName = strings.Join(nonEmpty(p.cell(rec, "first_name"), p.cell(rec, "last_name")), " ")
+	}
+	l.FullName = strings.Join(strings.Fields(l.FullName), " ")
+	if l.FullName == "" {
+		return row, fmt.Errorf("name is empty")
+	}
+
+	contacts := append([]ContactMethod{
+		{Kind: "phone", Value: p.cell(rec, "phone")},
+		{Kind: "email", Value: p.cell(rec, "email")},
+	}, rec.Contacts...)
+	for _, c := range contacts {
+		v, err := NormalizeContact(c.Kind, c.Value, p.region)
+		if err != nil {
+			return row, err
+		}
+		if v == "" || p.listed(row.Contacts, c.Kind, v) {
+			continue
+		}
+		c.Value, c.Label = v, strings.TrimSpace(c.Label)
+		row.Contacts = append(row.Contacts, c)
+		if c.Kind == "phone" && l.Phone == "" {
+			l.Phone = v
+		}
+		if c.Kind == "email" && l.Email == "" {
+			l.Email = v
+		}
+	}
+
+	var err error
+	l.LeadType = strings.ToLower(mapValue(p.spec.TypeMap, p.cell(rec, "type")))
+	l.StageName = mapValue(p.spec.StageMap, p.cell(rec, "stage"))
+	l.Source = p.cell(rec, "source")
+	l.Status = strings.ToLower(p.cell(rec, "status"))
+	l.Notes = p.cell(rec, "notes")
+	if l.NextFollowUp, err = parseImportDate(p.cell(rec, "follow_up")); err != nil {
+		return row, err
+	}
+
+	for _, t := range strings.FieldsFunc(p.cell(rec, "tags"), func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		if t, err := normalizeTag(t); err == nil && !containsFold(row.Tags, t) {
+			row.Tags = append(row.Tags, t)
+		}
+	}
+	for _, t := range p.tags {
+		if !containsFold(row.Tags, t) {
+			row.Tags = append(row.Tags, t)
+		}
+	}
+
+	for _, f := range p.fields {
+		v := p.cell(rec, "field:"+f.Name)
+		if v == "" {
+			continue
+		}
+		if v, err = ParseFieldValue(f, v); err != nil {
+			return row, err
+		}
+		row.Fields[f.ID] = v
+	}
+	return row, nil
+}
+
+// planCreate fills in the defaults CreateLead would use.
+func (p *importPlanner) planCreate(row *ImportRow) error {
+	l := &row.Lead
+	l.LeadType = defaultString(l.LeadType, "buyer")
+	l.Status = defaultString(l.Status, "new")
+	st, err := p.stage(l.LeadType, 0, l.StageName)
+	if err != nil {
+		return err
+	}
+	l.StageID, l.StageName, l.PipelineName = st.ID, st.Name, st.pipelineName
+	return nil
+}
+
+// planUpdate turns row into an update of row.Match: non-empty cells replace
+// the lead's values, notes are appended, new phones and emails are added
+// alongside the ones on file, and tags and custom fields are added. Rows
+// that would change nothing are skipped.
+func (p *importPlanner) planUpdate(row *ImportRow) error {
+	cur, in := row.Match, row.Lead
+	row.Lead = cur
+	l := &row.Lead
+	change := func(what, from, to string) {
+		row.Changes = append(row.Changes, fmt.Sprintf("%s %s → %s", what, defaultString(from, "—"), to))
+	}
+
+	if in.LeadType != "" && in.LeadType != cur.LeadType {
+		l.LeadType = in.LeadType
+		change("type", cur.LeadType, in.LeadType)
+	}
+	// A new type moves the lead to that type's pipeline, keeping its stage
+	// where the names match; otherwise the lead stays in its pipeline.
+	if in.StageName != "" || l.LeadType != cur.LeadType {
+		pipelineID := cur.PipelineID
+		if l.LeadType != cur.LeadType {
+			pipelineID = 0
+		}
+		st, err := p.stage(l.LeadType, pipelineID, defaultString(in.StageName, cur.StageName))
+		if err != nil && in.StageName == "" {
+			st, err = p.stage(l.LeadType, 0, "")
+		}
+		if err != nil {
+			return err
+		}
+		if st.ID != cur.StageID {
+			l.StageID, l.StageName, l.PipelineID, l.PipelineName = st.ID, st.Name, st.PipelineID, st.pipelineName
+			if st.PipelineID != cur.PipelineID {
+				change("stage", cur.PipelineName+"/"+cur.StageName, st.pipelineName+"/"+st.Name)
+			} else {
+				change("stage", cur.StageName, st.Name)
+			}
+		}
+	}
+	if in.Source != "" && in.Source != cur.Source {
+		l.Source = in.Source
+		change("source", cur.Source, in.Source)
+	}
+	if in.Status != "" && in.Status != cur.Status {
+		l.Status = in.Status
+		change("status", cur.Status, in.Status)
+	}
+	if in.NextFollowUp != nil && (cur.NextFollowUp == nil || !in.NextFollowUp.Equal(*cur.NextFollowUp)) {
+		l.NextFollowUp = in.NextFollowUp
+		from := ""
+		if cur.NextFollowUp != nil {
+			from = cur.NextFollowUp.Format("2006-01-02")
+		}
+		change("follow-up", from, in.NextFollowUp.Format("2006-01-02"))
+	}
+	if in.Notes != "" && !strings.Contains(cur.Notes, in.Notes) {
+		l.Notes = strings.Join(nonEmpty(cur.Notes, in.Notes), "\n\n")
+		row.Changes = append(row.Changes, "notes added")
+	}
+
+	var contacts []ContactMethod
+	for _, c := range row.Contacts {
+		if p.hasContact(cur.ID, c.Kind, c.Value) {
+			continue
+		}
+		contacts = append(contacts, c)
+		row.Changes = append(row.Changes, "+"+c.Kind+" "+c.Value)
+	}
+	row.Contacts = contacts
+
+	var tags []string
+	for _, t := range row.Tags {
+		if !containsFold(cur.Tags, t) {
+			tags = append(tags, t)
+			row.Changes = append(row.Changes, "+tag "+t)
+		}
+	}
+	row.Tags = tags
+
+	if len(row.Fields) > 0 {
+		values, err := p.r.LeadFieldValues(p.ctx, cur.ID)
+		if err != nil {
+			return err
+		}
+		for _, fv := range values {
+			v, ok := row.Fields[fv.Field.ID]
+			if !ok {
+				continue
+			}
+			if v == fv.Value {
+				delete(row.Fields, fv.Field.ID)
+				continue
+			}
+			change(fv.Field.Name, FormatFieldValue(fv.Field, fv.Value), FormatFieldValue(fv.Field, v))
+		}
+	}
+
+	if len(row.Changes) == 0 {
+		row.Action, row.Reason = "skip", fmt.Sprintf("#%d %s is already up to date", cur.ID, cur.FullName)
+	}
+	return nil
+}
+
+// importStage is a stage with the name of its pipeline, for previews.
+type importStage struct {
+	Stage
+	pipelineName string
+}
+
+// stage finds a stage by name in one pipeline: pipelineID, or the lead
+// type's default pipeline when it is 0. A stage of that name in another
+// pipeline does not count, so the row is reported rather than filed on the
+// wrong board. An empty name is the pipeline's first stage.
+func (p *importPlanner) stage(leadType string, pipelineID int64, name string) (importStage, error) {
+	key := fmt.Sprintf("%s\x00%d\x00%s", leadType, pipelineID, strings.ToLower(name))
+	if st, ok := p.stages[key]; ok {
+		return st, nil
+	}
+	var pl Pipeline
+	var err error
+	if pipelineID == 0 {
+		pl, err = p.r.DefaultPipeline(p.ctx, leadType)
+	} else {
+		pl, err = p.r.FindPipeline(p.ctx, fmt.Sprint(pipelineID))
+	}
+	if err != nil {
+		return importStage{}, err
+	}
+	st, err := p.r.FindStage(p.ctx, pl.ID, name)
+	if err != nil && name != "" {
+		err = fmt.Errorf("stage %q is not in the %s pipeline", name, pl.Name)
+	}
+	if err != nil {
+		return importStage{}, err
+	}
+	out := importStage{Stage: st, pipelineName: pl.Name}
+	p.stages[key] = out
+	return out, nil
+}
+
+// hasContact reports whether a lead on file already has value.
+func (p *importPlanner) hasContact(leadID int64, kind, value string) bool {
+	for _, k := range p.existing {
+		if k.lead.ID == leadID {
+			if kind == "phone" {
+				return oneOf(NormalizePhoneKey(value), k.phones)
+			}
+			return oneOf(strings.ToLower(value), k.emails)
+		}
+	}
+	return false
+}
+
+// listed reports whether contacts already holds value.
+func (p *importPlanner) listed(contacts []ContactMethod, kind, value string) bool {
+	for _, c := range contacts {
+		if c.Kind == kind && strings.EqualFold(c.Value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyImport writes a plan's create and update rows in one transaction;
+// skipped and rejected rows are left out. Any failure rolls the whole
+// import back. Cadences set to auto-apply are then applied to the new
+// leads. It returns the number of leads created and updated.
+func (r *Repo) ApplyImport(ctx context.Context, plan ImportPlan) (created, updated int, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	var newLeads []Lead
+	for _, row := range plan.Rows {
+		id, err := applyImportRow(ctx, tx, row)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, 0, fmt.Errorf("row %d: %w", row.Line, err)
+		}
+		switch row.Action {
+		case "create":
+			created++
+			l := row.Lead
+			l.ID = id
+			newLeads = append(newLeads, l)
+		case "update":
+			updated++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	// Auto-applied cadences create tasks through CreateTask, so they run
+	// once the leads are saved.
+	for _, l := range newLeads {
+		if err := r.applyAutoCadences(ctx, l); err != nil {
+			return created, updated, fmt.Errorf("leads were imported, but a follow-up cadence for #%d was not: %w", l.ID, err)
+		}
+	}
+	return created, updated, nil
+}
+
+// applyImportRow writes one row and returns the id of the lead it created
+// or updated.
+func applyImportRow(ctx context.Context, tx *sql.Tx, row ImportRow) (int64, error) {
+	id := row.Match.ID
+	switch row.Action {
+	case "create":
+		var err error
+		if id, err = insertLead(ctx, tx, row.Lead); err != nil {
+			return 0, err
+		}
+		for _, c := range row.Contacts {
+			if err := addImportContact(ctx, tx, id, c); err != nil {
+				return 0, err
+			}
+		}
+	case "update":
+		if err := updateLead(ctx, tx, row.Lead); err != nil {
+			return 0, err
+		}
+		if row.Lead.StageID != row.Match.StageID {
+			if _, err := tx.ExecContext(ctx, `UPDATE leads SET stage_id = ? WHERE id = ?`, row.Lead.StageID, id); err != nil {
+				return 0, err
+			}
+			if err := recordStageChange(ctx, tx, id, row.Match.StageID, row.Lead.StageID); err != nil {
+				return 0, err
+			}
+		}
+		for _, c := range row.Contacts {
+			if err := addImportContact(ctx, tx, id, c); err != nil {
+				return 0, err
+			}
+		}
+	default:
+		return 0, nil
+	}
+
+	for _, t := range row.Tags {
+		if err := tagLead(ctx, tx, id, t); err != nil {
+			return 0, err
+		}
+	}
+	for fieldID, v := range row.Fields {
+		if err := setLeadField(ctx, tx, id, fieldID, v); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// addImportContact adds a phone or email the lead does not have yet, and
+// labels an unlabelled one it does (such as the primary insertLead wrote).
+func addImportContact(ctx context.Context, tx *sql.Tx, leadID int64, c ContactMethod) error {
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO lead_contacts(lead_id, kind, label, value, is_primary)
+SELECT ?1, ?2, ?3, ?4, 0
+WHERE NOT EXISTS (SELECT 1 FROM lead_contacts WHERE lead_id = ?1 AND kind = ?2 AND lower(value) = lower(?4))
+`, leadID, c.Kind, c.Label, c.Value); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE lead_contacts SET label = ?3
+WHERE lead_id = ?1 AND kind = ?2 AND lower(value) = lower(?4) AND label = ''
+`, leadID, c.Kind, c.Label, c.Value); err != nil {
+		return err
+	}
+	return syncPrimaryContact(ctx, tx, leadID, c.Kind)
+}
+
+// mapValue applies a case-insensitive value transform; values not in m
+// pass through.
+func mapValue(m map[string]string, v string) string {
+	for from, to := range m {
+		if strings.EqualFold(strings.TrimSpace(from), v) {
+			return strings.TrimSpace(to)
+		}
+	}
+	return v
+}
+
+// importDateLayouts are the date formats spreadsheets commonly export.
+var importDateLayouts = []string{
+	"2006-01-02", "1/2/2006", "1/2/06", "2006/1/2", "Jan 2, 2006", "January 2, 2006", "2 Jan 2006",
+	time.RFC3339, "2006-01-02 15:04:05", "1/2/2006 15:04",
+}
+
+func parseImportDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			return &d, nil
+		}
+	}
+	return nil, fmt.Errorf("follow-up %q is not a date (use YYYY-MM-DD or M/D/YYYY)", s)
+}
+
+func defaultString(v, d string) string {
+	if v == "" {
+		return d
+	}
+	return v
+}
+
+func containsFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
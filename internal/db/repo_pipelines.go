@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// -------- Pipelines --------
+
+func (r *Repo) ListPipelines(ctx context.Context) ([]Pipeline, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, lead_type, sort FROM pipelines ORDER BY sort ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Pipeline
+	for rows.Next() {
+		var p Pipeline
+		if err := rows.Scan(&p.ID, &p.Name, &p.LeadType, &p.Sort); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// FindPipeline looks a pipeline up by id or name (case-insensitive).
+func (r *Repo) FindPipeline(ctx context.Context, nameOrID string) (Pipeline, error) {
+	var p Pipeline
+	err := r.db.QueryRowContext(ctx, `
+SELECT id, name, lead_type, sort FROM pipelines
+WHERE CAST(id AS TEXT) = ? OR lower(name) = lower(?)
+ORDER BY sort ASC, id ASC
+LIMIT 1
+`, nameOrID, nameOrID).Scan(&p.ID, &p.Name, &p.LeadType, &p.Sort)
+	if err == sql.ErrNoRows {
+		return Pipeline{}, fmt.Errorf("pipeline %q not found", nameOrID)
+	}
+	return p, err
+}
+
+// DefaultPipeline is the pipeline new leads of leadType land in: the one
+// claiming that lead type, else the first pipeline.
+func (r *Repo) DefaultPipeline(ctx context.Context, leadType string) (Pipeline, error) {
+	var p Pipeline
+	err := r.db.QueryRowContext(ctx, `
+SELECT id, name, lead_type, sort FROM pipelines
+ORDER BY CASE WHEN lead_type <> '' AND lead_type = lower(?) THEN 0 ELSE 1 END, sort ASC, id ASC
+LIMIT 1
+`, leadType).Scan(&p.ID, &p.Name, &p.LeadType, &p.Sort)
+	if err == sql.ErrNoRows {
+		return Pipeline{}, fmt.Errorf("no pipelines configured")
+	}
+	return p, err
+}
+
+// CreatePipeline adds a pipeline with a single "New" stage to start from.
+func (r *Repo) CreatePipeline(ctx context.Context, name, leadType string) (int64, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("pipeline name is required")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO pipelines(name, lead_type, sort)
+VALUES (?, ?, (SELECT COALESCE(MAX(sort), 0) + 10 FROM pipelines))
+`, name, strings.ToLower(strings.TrimSpace(leadType)))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO stages(pipeline_id, name, sort) VALUES (?, 'New', 10)`, id); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
+}
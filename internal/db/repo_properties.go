@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// -------- Properties --------
+
+var (
+	PropertyStatuses = []string{"coming-soon", "active", "pending", "sold", "off-market"}
+	PropertyRoles    = []string{"listing", "interested", "toured", "offered"}
+)
+
+const propertyCols = `p.id, p.address, p.price, p.beds, p.baths, p.sqft, p.mls_number, p.status, p.created_at, p.updated_at`
+
+// ListProperties returns properties, newest first. An empty status lists all.
+func (r *Repo) ListProperties(ctx context.Context, status string) ([]Property, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+propertyCols+`
+FROM properties p
+WHERE (? = '' OR p.status = ?)
+ORDER BY p.updated_at DESC, p.id DESC
+`, status, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Property
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) GetProperty(ctx context.Context, id int64) (Property, error) {
+	p, err := scanProperty(r.db.QueryRowContext(ctx, `SELECT `+propertyCols+` FROM properties p WHERE p.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return Property{}, fmt.Errorf("property #%d not found", id)
+	}
+	return p, err
+}
+
+func (r *Repo) CreateProperty(ctx context.Context, p Property) (int64, error) {
+	if err := validateProperty(&p); err != nil {
+		return 0, err
+	}
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO properties(address, price, beds, baths, sqft, mls_number, status)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`, p.Address, p.Price, p.Beds, p.Baths, p.Sqft, p.MLSNumber, p.Status)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// UpdateProperty saves every field of p and bumps updated_at.
+func (r *Repo) UpdateProperty(ctx context.Context, p Property) error {
+	if err := validateProperty(&p); err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx, `
+UPDATE properties
+SET address = ?, price = ?, beds = ?, baths = ?, sqft = ?, mls_number = ?, status = ?,
+    updated_at = datetime('now')
+WHERE id = ?
+`, p.Address, p.Price, p.Beds, p.Baths, p.Sqft, p.MLSNumber, p.Status, p.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("property #%d not found", p.ID)
+	}
+	return nil
+}
+
+// DeleteProperty removes a property and its links to leads.
+func (r *Repo) DeleteProperty(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM properties WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("property #%d not found", id)
+	}
+	return nil
+}
+
+// LinkProperty ties a lead to a property, or changes the role of an existing
+// link (a buyer who toured a house and then offered on it).
+func (r *Repo) LinkProperty(ctx context.Context, leadID, propertyID int64, role string) error {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !oneOf(role, PropertyRoles) {
+		return fmt.Errorf("unknown role %q (use %s)", role, strings.Join(PropertyRoles, "|"))
+	}
+	if _, err := r.GetLead(ctx, leadID); err == sql.ErrNoRows {
+		return fmt.Errorf("lead #%d not found", leadID)
+	} else if err != nil {
+		return err
+	}
+	if _, err := r.GetProperty(ctx, propertyID); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO lead_properties(lead_id, property_id, role) VALUES (?, ?, ?)
+ON CONFLICT(lead_id, property_id) DO UPDATE SET role = excluded.role
+`, leadID, propertyID, role)
+	return err
+}
+
+func (r *Repo) UnlinkProperty(ctx context.Context, leadID, propertyID int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM lead_properties WHERE lead_id = ? AND property_id = ?`, leadID, propertyID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("lead #%d is not linked to property #%d", leadID, propertyID)
+	}
+	return nil
+}
+
+// ListLeadProperties returns the properties linked to a lead.
+func (r *Repo) ListLeadProperties(ctx context.Context, leadID int64) ([]PropertyLink, error) {
+	return r.queryPropertyLinks(ctx, `lp.lead_id = ?`, leadID)
+}
+
+// ListPropertyLeads returns the leads linked to a property.
+func (r *Repo) ListPropertyLeads(ctx context.Context, propertyID int64) ([]PropertyLink, error) {
+	return r.queryPropertyLinks(ctx, `lp.property_id = ?`, propertyID)
+}
+
+func (r *Repo) queryPropertyLinks(ctx context.Context, where string, arg int64) ([]PropertyLink, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT lp.lead_id, l.full_name, lp.role, `+propertyCols+`
+FROM lead_properties lp
+JOIN leads l ON l.id = lp.lead_id
+JOIN properties p ON p.id = lp.property_id
+WHERE `+where+`
+ORDER BY lp.created_at ASC, p.id ASC
+`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []PropertyLink
+	for rows.Next() {
+		var pl PropertyLink
+		var created, updated string
+		p := &pl.Property
+		if err := rows.Scan(&pl.LeadID, &pl.LeadName, &pl.Role,
+			&p.ID, &p.Address, &p.Price, &p.Beds, &p.Baths, &p.Sqft, &p.MLSNumber, &p.Status, &created, &updated); err != nil {
+			return nil, err
+		}
+		p.CreatedAt = mustParseTime(created)
+		p.UpdatedAt = mustParseTime(updated)
+		out = append(out, pl)
+	}
+	return out, rows.Err()
+}
+
+func validateProperty(p *Property) error {
+	p.Address = strings.TrimSpace(p.Address)
+	p.MLSNumber = strings.TrimSpace(p.MLSNumber)
+	p.Status = strings.ToLower(strings.TrimSpace(p.Status))
+	if p.Status == "" {
+		p.Status = "active"
+	}
+	if p.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+	if !oneOf(p.Status, PropertyStatuses) {
+		return fmt.Errorf("unknown property status %q (use %s)", p.Status, strings.Join(PropertyStatuses, "|"))
+	}
+	if p.Price < 0 || p.Beds < 0 || p.Baths < 0 || p.Sqft < 0 {
+		return fmt.Errorf("price, beds, baths and sqft cannot be negative")
+	}
+	return nil
+}
+
+func scanProperty(row rowScanner) (Property, error) {
+	var p Property
+	var created, updated string
+	if err := row.Scan(&p.ID, &p.Address, &p.Price, &p.Beds, &p.Baths, &p.Sqft, &p.MLSNumber, &p.Status, &created, &updated); err != nil {
+		return Property{}, err
+	}
+	p.CreatedAt = mustParseTime(created)
+	p.UpdatedAt = mustParseTime(updated)
+	return p, nil
+}
+
+func oneOf(s string, options []string) bool {
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
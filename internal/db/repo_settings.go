@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// -------- Settings --------
+
+// SettingKeys lists the settings `pipelinepal config` knows, with help text.
+var SettingKeys = map[string]string{
+	"phone_region": "region for phone numbers typed without +country code (" + strings.Join(PhoneRegions(), ", ") + ")",
+}
+
+// GetSetting returns a setting's value, or def when it was never set.
+func (r *Repo) GetSetting(ctx context.Context, key, def string) (string, error) {
+	var v string
+	err := r.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = ?`, key).Scan(&v)
+	if err == sql.ErrNoRows {
+		return def, nil
+	}
+	return v, err
+}
+
+// SetSetting validates and stores a setting.
+func (r *Repo) SetSetting(ctx context.Context, key, value string) error {
+	if _, ok := SettingKeys[key]; !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	value = strings.TrimSpace(value)
+	if key == "phone_region" {
+		value = strings.ToUpper(value)
+		if _, ok := phoneRegions[value]; !ok {
+			return fmt.Errorf("unknown phone region %q (use one of %s)", value, strings.Join(PhoneRegions(), ", "))
+		}
+	}
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO settings(key, value) VALUES (?, ?)
+ON CONFLICT(key) DO UPDATE SET value = excluded.value
+`, key, value)
+	return err
+}
+
+// PhoneRegion is the region phone numbers without a country code are read in.
+func (r *Repo) PhoneRegion(ctx context.Context) (string, error) {
+	return r.GetSetting(ctx, "phone_region", "US")
+}
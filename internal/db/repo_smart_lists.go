@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// -------- Smart lists --------
+
+func (r *Repo) ListSmartLists(ctx context.Context) ([]SmartList, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, query, sort FROM smart_lists ORDER BY sort ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []SmartList
+	for rows.Next() {
+		var s SmartList
+		if err := rows.Scan(&s.ID, &s.Name, &s.Query, &s.Sort); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// FindSmartList looks a smart list up by name (case-insensitive).
+func (r *Repo) FindSmartList(ctx context.Context, name string) (SmartList, error) {
+	var s SmartList
+	err := r.db.QueryRowContext(ctx, `
+SELECT id, name, query, sort FROM smart_lists WHERE lower(name) = lower(?)
+`, strings.TrimSpace(name)).Scan(&s.ID, &s.Name, &s.Query, &s.Sort)
+	if err == sql.ErrNoRows {
+		return SmartList{}, fmt.Errorf("smart list %q not found", name)
+	}
+	return s, err
+}
+
+// SaveSmartList creates a smart list, or replaces the query of the one with
+// the same name. The query must parse.
+func (r *Repo) SaveSmartList(ctx context.Context, name, query string) (int64, error) {
+	name, query = strings.TrimSpace(name), strings.TrimSpace(query)
+	if name == "" {
+		return 0, fmt.Errorf("smart list name is required")
+	}
+	if _, err := ParseFilter(query); err != nil {
+		return 0, err
+	}
+
+	if existing, err := r.FindSmartList(ctx, name); err == nil {
+		_, err := r.db.ExecContext(ctx, `UPDATE smart_lists SET query = ? WHERE id = ?`, query, existing.ID)
+		return existing.ID, err
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO smart_lists(name, query, sort)
+VALUES (?, ?, (SELECT COALESCE(MAX(sort), 0) + 10 FROM smart_lists))
+`, name, query)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *Repo) DeleteSmartList(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM smart_lists WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("smart list #%d not found", id)
+	}
+	return nil
+}
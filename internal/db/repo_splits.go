@@ -0,0 +1,319 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -------- Commission split plans --------
+
+const splitPlanCols = `id, name, agent_split, annual_cap, cap_start_month, transaction_fee, team_override, is_default`
+
+func (r *Repo) ListSplitPlans(ctx context.Context) ([]SplitPlan, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+splitPlanCols+` FROM split_plans ORDER BY is_default DESC, name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []SplitPlan
+	for rows.Next() {
+		p, err := scanSplitPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) GetSplitPlan(ctx context.Context, id int64) (SplitPlan, error) {
+	p, err := scanSplitPlan(r.db.QueryRowContext(ctx, `SELECT `+splitPlanCols+` FROM split_plans WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return SplitPlan{}, fmt.Errorf("split plan #%d not found", id)
+	}
+	return p, err
+}
+
+// FindSplitPlan looks a plan up by id or name (case-insensitive).
+func (r *Repo) FindSplitPlan(ctx context.Context, nameOrID string) (SplitPlan, error) {
+	nameOrID = strings.TrimSpace(nameOrID)
+	id, _ := strconv.ParseInt(nameOrID, 10, 64)
+	p, err := scanSplitPlan(r.db.QueryRowContext(ctx, `
+SELECT `+splitPlanCols+` FROM split_plans
+WHERE id = ? OR name = ?
+ORDER BY id = ? DESC
+LIMIT 1
+`, id, nameOrID, id))
+	if err == sql.ErrNoRows {
+		return SplitPlan{}, fmt.Errorf("split plan %q not found", nameOrID)
+	}
+	return p, err
+}
+
+// CreateSplitPlan adds a plan. The first plan, or one with IsDefault set,
+// becomes the default for deals without a plan of their own.
+func (r *Repo) CreateSplitPlan(ctx context.Context, p SplitPlan) (int64, error) {
+	if err := validateSplitPlan(&p); err != nil {
+		return 0, err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO split_plans(name, agent_split, annual_cap, cap_start_month, transaction_fee, team_override, is_default)
+VALUES (?, ?, ?, ?, ?, ?, NOT EXISTS (SELECT 1 FROM split_plans))
+`, p.Name, p.AgentSplit, p.AnnualCap, p.CapStartMonth, p.TransactionFee, p.TeamOverride)
+	if err != nil {
+		_ = tx.Rollback()
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return 0, fmt.Errorf("split plan %q already exists", p.Name)
+		}
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if p.IsDefault {
+		if err := setDefaultSplitPlan(ctx, tx, id); err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// UpdateSplitPlan saves every field of p except the default flag.
+func (r *Repo) UpdateSplitPlan(ctx context.Context, p SplitPlan) error {
+	if err := validateSplitPlan(&p); err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx, `
+UPDATE split_plans
+SET name = ?, agent_split = ?, annual_cap = ?, cap_start_month = ?, transaction_fee = ?, team_override = ?
+WHERE id = ?
+`, p.Name, p.AgentSplit, p.AnnualCap, p.CapStartMonth, p.TransactionFee, p.TeamOverride, p.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("split plan #%d not found", p.ID)
+	}
+	return nil
+}
+
+// SetDefaultSplitPlan makes id the plan used by deals without one.
+func (r *Repo) SetDefaultSplitPlan(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := setDefaultSplitPlan(ctx, tx, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func setDefaultSplitPlan(ctx context.Context, ex execer, id int64) error {
+	res, err := ex.ExecContext(ctx, `UPDATE split_plans SET is_default = (id = ?)`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("split plan #%d not found", id)
+	}
+	return nil
+}
+
+// DeleteSplitPlan removes a plan; its deals fall back to the default plan.
+// The default plan can only go once it is the last one, so deals never
+// lose their default to a plan picked behind the user's back.
+func (r *Repo) DeleteSplitPlan(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var name string
+	var isDefault bool
+	if err := tx.QueryRowContext(ctx, `SELECT name, is_default FROM split_plans WHERE id = ?`, id).Scan(&name, &isDefault); err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("split plan #%d not found", id)
+		}
+		return err
+	}
+	if isDefault {
+		var others int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM split_plans WHERE id <> ?`, id).Scan(&others); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if others > 0 {
+			_ = tx.Rollback()
+			return fmt.Errorf("split plan %s is the default; make another plan the default first", name)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM split_plans WHERE id = ?`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Income computes net income for deals closed in the current cap year of
+// each split plan, as of asOf. Deals without a plan (and no default plan)
+// count from January 1 and only lose their referral fee.
+func (r *Repo) Income(ctx context.Context, asOf time.Time) (IncomeReport, error) {
+	plans, err := r.ListSplitPlans(ctx)
+	if err != nil {
+		return IncomeReport{}, err
+	}
+	byID := make(map[int64]SplitPlan, len(plans))
+	var def SplitPlan
+	for _, p := range plans {
+		byID[p.ID] = p
+		if p.IsDefault {
+			def = p
+		}
+	}
+
+	// Archiving a client after closing is routine; the commission was
+	// still earned, so archived leads' deals count too.
+	deals, err := r.queryDeals(ctx, `WHERE d.status = 'closed'`)
+	if err != nil {
+		return IncomeReport{}, err
+	}
+	sort.SliceStable(deals, func(i, j int) bool { return closedOn(deals[i]).Before(closedOn(deals[j])) })
+
+	// Group deals by the plan that applies, keeping first-seen order.
+	var order []int64
+	grouped := map[int64][]Deal{}
+	for _, d := range deals {
+		p := def
+		if d.SplitPlanID != 0 {
+			p = byID[d.SplitPlanID]
+		}
+		if _, ok := grouped[p.ID]; !ok {
+			order = append(order, p.ID)
+		}
+		grouped[p.ID] = append(grouped[p.ID], d)
+	}
+
+	rep := IncomeReport{AsOf: asOf}
+	for _, id := range order {
+		p := byID[id] // zero plan for id 0
+		since := time.Date(asOf.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		if id != 0 {
+			since = p.CapYearStart(asOf)
+		}
+
+		var inYear []Deal
+		for _, d := range grouped[id] {
+			if c := closedOn(d); !c.Before(since) && !c.After(asOf) {
+				inYear = append(inYear, d)
+			}
+		}
+		if len(inYear) == 0 {
+			continue
+		}
+
+		var planPtr *SplitPlan
+		if id != 0 {
+			planPtr = &p
+		}
+		nets := ComputeNets(planPtr, inYear)
+
+		ytd := PlanYTD{Plan: p, Since: since, Deals: len(nets)}
+		for _, n := range nets {
+			ytd.GCI += n.GCI
+			ytd.Net += n.Net
+			ytd.CapPaid = n.CapPaid
+			if n.CapHit && ytd.CapHitOn == nil {
+				c := closedOn(n.Deal)
+				ytd.CapHitOn = &c
+			}
+		}
+		rep.Plans = append(rep.Plans, ytd)
+		rep.Deals = append(rep.Deals, nets...)
+	}
+	sort.SliceStable(rep.Deals, func(i, j int) bool {
+		return closedOn(rep.Deals[i].Deal).Before(closedOn(rep.Deals[j].Deal))
+	})
+	return rep, nil
+}
+
+// ComputeNets works out each deal's net in closing order within one cap
+// year: referral fee off the top, then the brokerage split until the cap is
+// paid, the transaction fee, and the team lead's override of what is left.
+// A nil plan only deducts referrals.
+func ComputeNets(plan *SplitPlan, deals []Deal) []DealNet {
+	out := make([]DealNet, 0, len(deals))
+	var paid float64
+	for _, d := range deals {
+		n := DealNet{Deal: d, GCI: d.GrossCommission()}
+		n.Referral = n.GCI * d.ReferralPct / 100
+		adjusted := n.GCI - n.Referral
+
+		if plan != nil {
+			n.Plan = plan.Name
+			n.Brokerage = adjusted * (100 - plan.AgentSplit) / 100
+			if plan.AnnualCap > 0 {
+				n.Brokerage = math.Min(n.Brokerage, math.Max(plan.AnnualCap-paid, 0))
+			}
+			paid += n.Brokerage
+			n.Fees = plan.TransactionFee
+			n.Team = (adjusted - n.Brokerage) * plan.TeamOverride / 100
+			n.CapHit = plan.AnnualCap > 0 && paid >= plan.AnnualCap-0.005
+		}
+		n.CapPaid = paid
+		n.Net = adjusted - n.Brokerage - n.Fees - n.Team
+		out = append(out, n)
+	}
+	return out
+}
+
+// closedOn is when a closed deal counts toward income; deals closed without
+// a date fall back to their last update.
+func closedOn(d Deal) time.Time {
+	if d.ClosedDate != nil {
+		return *d.ClosedDate
+	}
+	return d.UpdatedAt
+}
+
+func validateSplitPlan(p *SplitPlan) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("split plan name is required")
+	}
+	if p.CapStartMonth == 0 {
+		p.CapStartMonth = 1
+	}
+	switch {
+	case p.AgentSplit < 0 || p.AgentSplit > 100:
+		return fmt.Errorf("agent split %.2f%% is out of range (0-100)", p.AgentSplit)
+	case p.TeamOverride < 0 || p.TeamOverride > 100:
+		return fmt.Errorf("team override %.2f%% is out of range (0-100)", p.TeamOverride)
+	case p.CapStartMonth < 1 || p.CapStartMonth > 12:
+		return fmt.Errorf("cap start month %d is out of range (1-12)", p.CapStartMonth)
+	case p.AnnualCap < 0 || p.TransactionFee < 0:
+		return fmt.Errorf("cap and transaction fee cannot be negative")
+	}
+	return nil
+}
+
+func scanSplitPlan(row rowScanner) (SplitPlan, error) {
+	var p SplitPlan
+	err := row.Scan(&p.ID, &p.Name, &p.AgentSplit, &p.AnnualCap, &p.CapStartMonth, &p.TransactionFee, &p.TeamOverride, &p.IsDefault)
+	return p, err
+}
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// -------- Stage management --------
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ValidateStageColor accepts "", a hex color (#RGB / #RRGGBB) or an ANSI
+// color number 0-255, the forms lipgloss.Color understands.
+func ValidateStageColor(c string) error {
+	c = strings.TrimSpace(c)
+	if c == "" || hexColor.MatchString(c) {
+		return nil
+	}
+	if n, err := strconv.Atoi(c); err == nil && n >= 0 && n <= 255 {
+		return nil
+	}
+	return fmt.Errorf("bad color %q (use #RRGGBB or 0-255)", c)
+}
+
+func (r *Repo) GetStage(ctx context.Context, id int64) (Stage, error) {
+	s, err := scanStage(r.db.QueryRowContext(ctx, `SELECT `+stageCols+` FROM stages s WHERE s.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return Stage{}, fmt.Errorf("stage #%d not found", id)
+	}
+	return s, err
+}
+
+// CreateStage appends a stage to the end of a pipeline's board.
+func (r *Repo) CreateStage(ctx context.Context, pipelineID int64, name, color string) (int64, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("stage name is required")
+	}
+	if err := ValidateStageColor(color); err != nil {
+		return 0, err
+	}
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO stages(pipeline_id, name, sort, color)
+VALUES (?, ?, (SELECT COALESCE(MAX(sort), 0) + 10 FROM stages WHERE pipeline_id = ?), ?)
+`, pipelineID, name, pipelineID, strings.TrimSpace(color))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *Repo) RenameStage(ctx context.Context, id int64, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("stage name is required")
+	}
+	return r.execStage(ctx, id, `UPDATE stages SET name = ? WHERE id = ?`, name, id)
+}
+
+func (r *Repo) SetStageColor(ctx context.Context, id int64, color string) error {
+	if err := ValidateStageColor(color); err != nil {
+		return err
+	}
+	return r.execStage(ctx, id, `UPDATE stages SET color = ? WHERE id = ?`, strings.TrimSpace(color), id)
+}
+
+// SetStageDealPrompt turns the "create a deal?" prompt on or off for leads
+// moved into the stage.
+func (r *Repo) SetStageDealPrompt(ctx context.Context, id int64, on bool) error {
+	return r.execStage(ctx, id, `UPDATE stages SET deal_prompt = ? WHERE id = ?`, on, id)
+}
+
+// SetStageWinProb sets the percent chance that a deal in the stage closes.
+func (r *Repo) SetStageWinProb(ctx context.Context, id int64, pct int) error {
+	if pct < 0 || pct > 100 {
+		return fmt.Errorf("win probability %d%% is out of range (0-100)", pct)
+	}
+	return r.execStage(ctx, id, `UPDATE stages SET win_probability = ? WHERE id = ?`, pct, id)
+}
+
+// MoveStage puts a stage at the 0-based position pos in its pipeline's
+// board order (clamped) and renumbers sort in steps of 10.
+func (r *Repo) MoveStage(ctx context.Context, id int64, pos int) error {
+	st, err := r.GetStage(ctx, id)
+	if err != nil {
+		return err
+	}
+	stages, err := r.ListStages(ctx, st.PipelineID)
+	if err != nil {
+		return err
+	}
+
+	from := -1
+	for i, s := range stages {
+		if s.ID == id {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		return fmt.Errorf("stage #%d not found", id)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(stages)-1 {
+		pos = len(stages) - 1
+	}
+
+	moved := stages[from]
+	stages = append(stages[:from], stages[from+1:]...)
+	stages = append(stages[:pos], append([]Stage{moved}, stages[pos:]...)...)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for i, s := range stages {
+		if _, err := tx.ExecContext(ctx, `UPDATE stages SET sort = ? WHERE id = ?`, (i+1)*10, s.ID); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CountStageLeads counts every lead in a stage, archived ones included,
+// since they all hold the stage's foreign key.
+func (r *Repo) CountStageLeads(ctx context.Context, id int64) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM leads WHERE stage_id = ?`, id).Scan(&n)
+	return n, err
+}
+
+// DeleteStage removes a stage. A stage that still has leads is refused
+// unless moveTo names another stage of the same pipeline to migrate them
+// into first. A pipeline's last stage cannot be deleted, since new leads
+// of its type need a stage to start in.
+func (r *Repo) DeleteStage(ctx context.Context, id, moveTo int64) error {
+	if moveTo == id {
+		return fmt.Errorf("cannot move leads into the stage being deleted")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var pipelineID int64
+	var name string
+	err = tx.QueryRowContext(ctx, `SELECT pipeline_id, name FROM stages WHERE id = ?`, id).Scan(&pipelineID, &name)
+	if err == sql.ErrNoRows {
+		_ = tx.Rollback()
+		return fmt.Errorf("stage #%d not found", id)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	var stages, n int
+	if err := tx.QueryRowContext(ctx, `
+SELECT (SELECT COUNT(*) FROM stages WHERE pipeline_id = ?),
+       (SELECT COUNT(*) FROM leads WHERE stage_id = ?)
+`, pipelineID, id).Scan(&stages, &n); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if stages < 2 {
+		_ = tx.Rollback()
+		return fmt.Errorf("cannot delete %s: it is the pipeline's only stage", name)
+	}
+	if n > 0 && moveTo == 0 {
+		_ = tx.Rollback()
+		return fmt.Errorf("stage still has %d lead(s); choose a stage to move them to", n)
+	}
+	if moveTo != 0 {
+		var destPipeline int64
+		err := tx.QueryRowContext(ctx, `SELECT pipeline_id FROM stages WHERE id = ?`, moveTo).Scan(&destPipeline)
+		if err == sql.ErrNoRows {
+			_ = tx.Rollback()
+			return fmt.Errorf("stage #%d not found", moveTo)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if destPipeline != pipelineID {
+			_ = tx.Rollback()
+			return fmt.Errorf("cannot move leads out of %s into a stage of another pipeline", name)
+		}
+	}
+
+	if n > 0 {
+		// History first, while the stage's name can still be snapshotted.
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO stage_history(lead_id, from_stage_id, from_stage, to_stage_id, to_stage)
+SELECT l.id, l.stage_id, s.name, ?, (SELECT name FROM stages WHERE id = ?)
+FROM leads l
+JOIN stages s ON s.id = l.stage_id
+WHERE l.stage_id = ?
+`, moveTo, moveTo, id); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE leads SET stage_id = ?, updated_at = datetime('now') WHERE stage_id = ?
+`, moveTo, id); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM stages WHERE id = ?`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repo) execStage(ctx context.Context, id int64, query string, args ...any) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("stage #%d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// -------- Tags --------
+
+// normalizeTag trims a tag name and rejects ones that cannot round-trip
+// through the comma-joined list on Lead.
+func normalizeTag(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.TrimPrefix(name, "#")
+	if name == "" {
+		return "", fmt.Errorf("tag name is required")
+	}
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("tag %q: commas are not allowed", name)
+	}
+	return name, nil
+}
+
+// TagLead adds a tag to a lead, creating the tag on first use.
+func (r *Repo) TagLead(ctx context.Context, leadID int64, name string) error {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE leads SET updated_at = datetime('now') WHERE id = ?`, leadID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		_ = tx.Rollback()
+		return fmt.Errorf("lead #%d not found", leadID)
+	}
+	if err := tagLead(ctx, tx, leadID, name); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// tagLead attaches an already normalized tag, creating it on first use.
+func tagLead(ctx context.Context, ex execer, leadID int64, name string) error {
+	if _, err := ex.ExecContext(ctx, `INSERT INTO tags(name) VALUES (?) ON CONFLICT(name) DO NOTHING`, name); err != nil {
+		return err
+	}
+	_, err := ex.ExecContext(ctx, `
+INSERT INTO lead_tags(lead_id, tag_id)
+SELECT ?, id FROM tags WHERE name = ?
+ON CONFLICT DO NOTHING
+`, leadID, name)
+	return err
+}
+
+// UntagLead removes a tag from a lead. Tags nobody uses any more are dropped.
+func (r *Repo) UntagLead(ctx context.Context, leadID int64, name string) error {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx, `
+DELETE FROM lead_tags
+WHERE lead_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)
+`, leadID, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("lead #%d is not tagged %q", leadID, name)
+	}
+	_, _ = r.db.ExecContext(ctx, `DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM lead_tags)`)
+	_, _ = r.db.ExecContext(ctx, `UPDATE leads SET updated_at = datetime('now') WHERE id = ?`, leadID)
+	return nil
+}
+
+// ListTags returns every tag with how many active leads carry it.
+func (r *Repo) ListTags(ctx context.Context) ([]Tag, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT t.id, t.name, COUNT(l.id)
+FROM tags t
+LEFT JOIN lead_tags lt ON lt.tag_id = t.id
+LEFT JOIN leads l ON l.id = lt.lead_id AND l.archived_at IS NULL
+GROUP BY t.id
+ORDER BY t.name COLLATE NOCASE
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Tag
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.LeadCount); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// LeadIDsWithTag returns the set of leads tagged name.
+func (r *Repo) LeadIDsWithTag(ctx context.Context, name string) (map[int64]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT lt.lead_id FROM lead_tags lt JOIN tags t ON t.id = lt.tag_id WHERE t.name = ?
+`, strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
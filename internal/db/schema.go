@@ -1,26 +1,312 @@
 package db
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Pipeline struct {
+	ID       int64
+	Name     string
+	LeadType string // new leads of this type default to this pipeline
+	Sort     int
+}
 
 type Stage struct {
-	ID    int64
-	Name  string
-	Sort  int
-	Color string // not used yet, but future-friendly
+	ID         int64
+	PipelineID int64
+	Name       string
+	Sort       int
+	Color      string // hex (#RRGGBB) or ANSI 0-255; empty uses the theme color
+	DealPrompt bool   // moving a lead here offers to create a deal
+	WinProb    int    // percent chance a deal in this stage closes
 }
 
+// LeadTypes are the kinds of lead.
+var LeadTypes = []string{"buyer", "seller", "rental", "other"}
+
 type Lead struct {
 	ID            int64
 	FullName      string
 	Phone         string
 	Email         string
-	LeadType      string // "buyer" | "seller" | "other"
+	LeadType      string // one of LeadTypes
 	Source        string
 	StageID       int64
 	StageName     string
+	PipelineID    int64 // derived from the stage
+	PipelineName  string
+	Status        string // new|contacted|nurture|hot|cold|closed|dead
+	NextFollowUp  *time.Time
+	Notes         string // free-text background; dated entries live in the notes table
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	LastContacted *time.Time
+	ArchivedAt    *time.Time // set while the lead is in the trash
+	StageSince    time.Time  // when the lead entered its current stage
+	Tags          []string   // sorted, case as first typed
+}
+
+type Tag struct {
+	ID        int64
+	Name      string
+	LeadCount int // active leads only
+}
+
+// ContactMethod is one labelled phone number or email address of a lead.
+// The primary one of each kind is mirrored into Lead.Phone / Lead.Email.
+type ContactMethod struct {
+	ID      int64
+	LeadID  int64
+	Kind    string // phone|email
+	Label   string // free text: cell, work, home…
+	Value   string
+	Primary bool
+}
+
+// Relationship links two leads. Seen from LeadID it reads "LeadID's Kind is
+// RelatedID"; Inverse is set when the row was stored the other way round.
+type Relationship struct {
+	ID          int64
+	Kind        string // spouse|co-buyer|referred-by|attorney
+	LeadID      int64
+	RelatedID   int64
+	RelatedName string
+	Inverse     bool
+}
+
+// Label describes the related lead from LeadID's side, e.g. "spouse",
+// "referred by" or, for an inverse referred-by link, "referred".
+func (r Relationship) Label() string {
+	if !r.Inverse {
+		return strings.ReplaceAll(r.Kind, "-", " ")
+	}
+	switch r.Kind {
+	case "referred-by":
+		return "referred"
+	case "attorney":
+		return "client"
+	}
+	return strings.ReplaceAll(r.Kind, "-", " ")
+}
+
+// CustomField is a user-defined lead attribute.
+type CustomField struct {
+	ID      int64
+	Name    string
+	Kind    string   // text|number|money|date|choice
+	Choices []string // allowed values for choice fields
+	Sort    int
+}
+
+// FieldValue is a custom field and its value on one lead ("" when unset).
+type FieldValue struct {
+	Field CustomField
+	Value string
+}
+
+// Property is a house a lead is selling, or one a buyer looked at.
+type Property struct {
+	ID        int64
+	Address   string
+	Price     float64
+	Beds      int
+	Baths     float64
+	Sqft      int
+	MLSNumber string
+	Status    string // coming-soon|active|pending|sold|off-market
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PropertyLink ties a lead to a property with a role.
+type PropertyLink struct {
+	LeadID   int64
+	LeadName string
+	Property Property
+	Role     string // listing|interested|toured|offered
+}
+
+// Summary is a one-line description: "$450,000 • 3bd/2ba • 1,850 sqft • MLS 123 • active".
+func (p Property) Summary() string {
+	parts := make([]string, 0, 5)
+	if p.Price > 0 {
+		parts = append(parts, FormatMoney(p.Price))
+	}
+	if p.Beds > 0 || p.Baths > 0 {
+		parts = append(parts, fmt.Sprintf("%dbd/%sba", p.Beds, strconv.FormatFloat(p.Baths, 'f', -1, 64)))
+	}
+	if p.Sqft > 0 {
+		parts = append(parts, strings.TrimPrefix(FormatMoney(float64(p.Sqft)), "$")+" sqft")
+	}
+	if p.MLSNumber != "" {
+		parts = append(parts, "MLS "+p.MLSNumber)
+	}
+	parts = append(parts, p.Status)
+	return strings.Join(parts, " • ")
+}
+
+// Deal is a transaction on a lead: what it sells for and what it pays.
+type Deal struct {
+	ID              int64
+	LeadID          int64
+	LeadName        string
+	PropertyID      int64 // 0 when not tied to a property record
+	PropertyAddress string
+	Price           float64
+	CommissionRate  float64 // percent: 3 means 3%
+	ReferralPct     float64 // percent of GCI paid as a referral fee
+	SplitPlanID     int64   // 0 uses the default split plan
+	SplitPlanName   string
+	Side            string // buyer|listing|dual
+	Status          string // open|closed|fell-through
+	ContractDate    *time.Time
+	ExpectedClose   *time.Time
+	ClosedDate      *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// GrossCommission is the deal's GCI: price times commission rate.
+func (d Deal) GrossCommission() float64 {
+	return d.Price * d.CommissionRate / 100
+}
+
+// Cadence is a reusable follow-up plan: a task per step, due Day days after
+// the plan is applied to a lead. AutoSource and AutoType, when set, apply it
+// to new leads that match every one that is set.
+type Cadence struct {
+	ID         int64
+	Name       string
+	AutoSource string
+	AutoType   string
+	Steps      []CadenceStep // in order of Day
+}
+
+type CadenceStep struct {
+	Day   int    // days after the start; 0 = the day it is applied
+	Title string // task title
+	Type  string // task type, or empty
+}
+
+// SplitPlan describes how an agent's GCI is shared with the brokerage.
+type SplitPlan struct {
+	ID             int64
+	Name           string
+	AgentSplit     float64 // agent's percent of GCI before the cap
+	AnnualCap      float64 // company dollar per cap year; 0 means no cap
+	CapStartMonth  int     // 1-12; cap years start on the 1st of this month
+	TransactionFee float64 // flat fee per closed deal
+	TeamOverride   float64 // team lead's percent of the agent's share
+	IsDefault      bool
+}
+
+// CapYearStart is the first day of the cap year containing t.
+func (p SplitPlan) CapYearStart(t time.Time) time.Time {
+	month := time.Month(p.CapStartMonth)
+	if month < time.January || month > time.December {
+		month = time.January
+	}
+	start := time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+	if start.After(t) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start
+}
+
+// DealNet is what a closed deal pays the agent under a split plan.
+type DealNet struct {
+	Deal      Deal
+	Plan      string // "" when no plan applies
+	GCI       float64
+	Referral  float64
+	Brokerage float64 // company dollar, limited by the cap
+	Fees      float64
+	Team      float64
+	Net       float64
+	CapPaid   float64 // company dollar paid in the cap year through this deal
+	CapHit    bool    // the cap was reached on or before this deal
+}
+
+// PlanYTD totals one split plan's closed deals for the current cap year.
+type PlanYTD struct {
+	Plan     SplitPlan // zero when deals had no plan
+	Since    time.Time
+	Deals    int
+	GCI      float64
+	Net      float64
+	CapPaid  float64
+	CapHitOn *time.Time
+}
+
+// IncomeReport is year-to-date income from closed deals.
+type IncomeReport struct {
+	AsOf  time.Time
+	Deals []DealNet // by closing date
+	Plans []PlanYTD
+}
+
+// ForecastItem is one open deal weighted by its lead's stage.
+type ForecastItem struct {
+	Deal     Deal
+	StageID  int64
+	Stage    string
+	Pipeline string
+	WinProb  int
+}
+
+// Weighted is the deal's expected GCI: gross commission times win probability.
+func (f ForecastItem) Weighted() float64 {
+	return f.Deal.GrossCommission() * float64(f.WinProb) / 100
+}
+
+// ForecastMonth totals the forecast for one expected-close month ("" when
+// the deal has no expected close date).
+type ForecastMonth struct {
+	Month    string // YYYY-MM
+	Deals    int
+	GCI      float64
+	Weighted float64
+}
+
+// DaysInStage is how many whole days the lead has sat in its current stage.
+func (l Lead) DaysInStage(now time.Time) int {
+	if l.StageSince.IsZero() {
+		return 0
+	}
+	d := int(now.Sub(l.StageSince).Hours() / 24)
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// SmartList is a saved Filter query.
+type SmartList struct {
+	ID    int64
+	Name  string
+	Query string
+	Sort  int
+}
+
+// SearchHit is one lead matched by SearchLeads.
+type SearchHit struct {
+	Lead    Lead
+	Kind    string // lead|contact|note|task: where the best match was found
+	Snippet string // match context; hits wrapped in HighlightStart/End
+	Rank    int    // 0 = best
+}
+
+type StageChange struct {
+	ID          int64
+	LeadID      int64
+	FromStageID int64 // 0 when the lead was created
+	FromStage   string
+	ToStageID   int64
+	ToStage     string
+	ChangedAt   time.Time
 }
 
 type Note struct {
@@ -36,7 +322,11 @@ type Task struct {
 	LeadName    string // convenient for “All Tasks” view
 	Title       string
 	DueDate     *time.Time
+	DueTime     string // HH:MM; empty when due any time that day
+	Type        string // call|text|email|showing|meeting, or empty
+	Priority    int    // PriorityHigh, PriorityNormal or PriorityLow
 	Status      string // open|done
+	Recurrence  string // RRULE, e.g. FREQ=WEEKLY;INTERVAL=2; empty for one-off tasks
 	CreatedAt   time.Time
 	CompletedAt *time.Time
 }
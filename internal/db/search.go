@@ -0,0 +1,341 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Snippet highlight markers. Front ends swap them for their own styling.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// contactBody is what search_index holds for a contact method: the label,
+// the value as typed and, for phones, its bare digits so "5551234567" finds
+// "(555) 123-4567".
+const contactBody = `label || ' ' || value || CASE kind WHEN 'phone' THEN ' ' || ` + contactDigitsSQL + ` ELSE '' END`
+
+// searchTriggers keep search_index in sync with leads, notes, tasks and
+// contact methods.
+// Lead deletes clear every row for the lead, which also covers the notes
+// and tasks removed by ON DELETE CASCADE.
+var searchTriggers = []string{`
+CREATE TRIGGER IF NOT EXISTS search_leads_ai AFTER INSERT ON leads BEGIN
+  INSERT INTO search_index(kind, lead_id, ref_id, body)
+  VALUES ('lead', new.id, new.id, new.full_name || ' ' || new.phone || ' ' || new.email || ' ' || new.source || ' ' || new.notes);
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_leads_au AFTER UPDATE OF full_name, phone, email, source, notes ON leads BEGIN
+  DELETE FROM search_index WHERE kind = 'lead' AND ref_id = old.id;
+  INSERT INTO search_index(kind, lead_id, ref_id, body)
+  VALUES ('lead', new.id, new.id, new.full_name || ' ' || new.phone || ' ' || new.email || ' ' || new.source || ' ' || new.notes);
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_leads_ad AFTER DELETE ON leads BEGIN
+  DELETE FROM search_index WHERE lead_id = old.id;
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_notes_ai AFTER INSERT ON notes BEGIN
+  INSERT INTO search_index(kind, lead_id, ref_id, body) VALUES ('note', new.lead_id, new.id, new.body);
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_notes_au AFTER UPDATE ON notes BEGIN
+  DELETE FROM search_index WHERE kind = 'note' AND ref_id = old.id;
+  INSERT INTO search_index(kind, lead_id, ref_id, body) VALUES ('note', new.lead_id, new.id, new.body);
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_notes_ad AFTER DELETE ON notes BEGIN
+  DELETE FROM search_index WHERE kind = 'note' AND ref_id = old.id;
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_tasks_ai AFTER INSERT ON tasks BEGIN
+  INSERT INTO search_index(kind, lead_id, ref_id, body) VALUES ('task', new.lead_id, new.id, new.title);
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_tasks_au AFTER UPDATE OF title, lead_id ON tasks BEGIN
+  DELETE FROM search_index WHERE kind = 'task' AND ref_id = old.id;
+  INSERT INTO search_index(kind, lead_id, ref_id, body) VALUES ('task', new.lead_id, new.id, new.title);
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_tasks_ad AFTER DELETE ON tasks BEGIN
+  DELETE FROM search_index WHERE kind = 'task' AND ref_id = old.id;
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_contacts_ai AFTER INSERT ON lead_contacts BEGIN
+  INSERT INTO search_index(kind, lead_id, ref_id, body) SELECT 'contact', new.lead_id, new.id, ` + contactBody + ` FROM lead_contacts WHERE id = new.id;
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_contacts_au AFTER UPDATE OF label, value ON lead_contacts BEGIN
+  DELETE FROM search_index WHERE kind = 'contact' AND ref_id = old.id;
+  INSERT INTO search_index(kind, lead_id, ref_id, body) SELECT 'contact', new.lead_id, new.id, ` + contactBody + ` FROM lead_contacts WHERE id = new.id;
+END;`, `
+CREATE TRIGGER IF NOT EXISTS search_contacts_ad AFTER DELETE ON lead_contacts BEGIN
+  DELETE FROM search_index WHERE kind = 'contact' AND ref_id = old.id;
+END;`,
+}
+
+var searchTriggerNames = []string{
+	"search_leads_ai", "search_leads_au", "search_leads_ad",
+	"search_notes_ai", "search_notes_au", "search_notes_ad",
+	"search_tasks_ai", "search_tasks_au", "search_tasks_ad",
+	"search_contacts_ai", "search_contacts_au", "search_contacts_ad",
+}
+
+// hasFTS5 reports whether the linked SQLite was compiled with FTS5
+// (go-sqlite3 needs the sqlite_fts5 build tag).
+func hasFTS5(ctx context.Context, db *sql.DB) bool {
+	var on int
+	err := db.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&on)
+	return err == nil && on == 1
+}
+
+// SearchFallbackNote tells users of a build without FTS5 why search only
+// matches plain substrings.
+const SearchFallbackNote = "Full-text search is off in this build (rebuild with -tags sqlite_fts5); search matches plain substrings."
+
+// FullTextSearch reports whether SearchLeads uses the FTS5 index rather
+// than the plain LIKE fallback.
+func (r *Repo) FullTextSearch(ctx context.Context) bool {
+	return hasFTS5(ctx, r.db.DB)
+}
+
+// setupSearch installs the FTS5 index and its triggers, rebuilding the
+// index whenever the triggers were missing. Without FTS5 the triggers are
+// dropped so writes never touch a table this build cannot open.
+func setupSearch(ctx context.Context, db *sql.DB) error {
+	if !hasFTS5(ctx, db) {
+		return dropSearchTriggers(ctx, db)
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM sqlite_master
+WHERE type = 'trigger' AND name IN (`+strings.Repeat("?, ", len(searchTriggerNames)-1)+`?)
+`, stringArgs(searchTriggerNames)...).Scan(&n); err != nil {
+		return err
+	}
+	if n == len(searchTriggerNames) {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmts := []string{`
+CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+  kind UNINDEXED, lead_id UNINDEXED, ref_id UNINDEXED, body,
+  tokenize = 'unicode61 remove_diacritics 2'
+);`,
+		`DELETE FROM search_index;`,
+		`INSERT INTO search_index(kind, lead_id, ref_id, body)
+SELECT 'lead', id, id, full_name || ' ' || phone || ' ' || email || ' ' || source || ' ' || notes FROM leads;`,
+		`INSERT INTO search_index(kind, lead_id, ref_id, body) SELECT 'note', lead_id, id, body FROM notes;`,
+		`INSERT INTO search_index(kind, lead_id, ref_id, body) SELECT 'task', lead_id, id, title FROM tasks;`,
+		`INSERT INTO search_index(kind, lead_id, ref_id, body) SELECT 'contact', lead_id, id, ` + contactBody + ` FROM lead_contacts;`,
+	}
+	stmts = append(stmts, searchTriggers...)
+	for _, s := range stmts {
+		if _, err := tx.ExecContext(ctx, s); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("search index: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// dropSearchTriggers removes the index triggers when this build has no
+// FTS5. Migrate runs it before migrating too, since data migrations would
+// otherwise fire triggers left behind by an FTS5 build.
+func dropSearchTriggers(ctx context.Context, db *sql.DB) error {
+	if hasFTS5(ctx, db) {
+		return nil
+	}
+	for _, name := range searchTriggerNames {
+		if _, err := db.ExecContext(ctx, `DROP TRIGGER IF EXISTS `+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stringArgs(ss []string) []any {
+	out := make([]any, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
+
+// ftsQuery turns free text into an FTS5 query: every term becomes a quoted
+// prefix phrase, so "pre-approval" and half-typed words just work.
+func ftsQuery(q string) string {
+	var parts []string
+	for _, term := range strings.Fields(q) {
+		parts = append(parts, `"`+strings.ReplaceAll(term, `"`, `""`)+`"*`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchLeads ranks active leads by how well q matches their details,
+// notes and task titles. Each hit carries the best-matching snippet.
+func (r *Repo) SearchLeads(ctx context.Context, q string) ([]SearchHit, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, nil
+	}
+
+	type match struct {
+		leadID  int64
+		kind    string
+		snippet string
+	}
+	var matches []match
+
+	// Phone numbers match on digits alone, whatever punctuation either side
+	// was typed with.
+	if digits := phoneDigits(q); digits != "" {
+		rows, err := r.db.QueryContext(ctx, `
+SELECT lead_id, value FROM lead_contacts
+WHERE kind = 'phone' AND `+contactDigitsSQL+` LIKE ?
+ORDER BY is_primary DESC, id ASC
+`, "%"+digits+"%")
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m match
+			var value string
+			if err := rows.Scan(&m.leadID, &value); err != nil {
+				return nil, err
+			}
+			m.kind, m.snippet = "contact", HighlightStart+value+HighlightEnd
+			matches = append(matches, m)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if hasFTS5(ctx, r.db.DB) {
+		rows, err := r.db.QueryContext(ctx, `
+SELECT lead_id, kind, snippet(search_index, 3, ?, ?, '…', 12)
+FROM search_index
+WHERE search_index MATCH ?
+ORDER BY bm25(search_index)
+LIMIT 500
+`, HighlightStart, HighlightEnd, ftsQuery(q))
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m match
+			if err := rows.Scan(&m.leadID, &m.kind, &m.snippet); err != nil {
+				return nil, err
+			}
+			matches = append(matches, m)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		// Plain LIKE fallback: lead fields first, then contacts, notes and tasks.
+		like := "%" + q + "%"
+		rows, err := r.db.QueryContext(ctx, `
+SELECT id, 'lead', full_name || ' ' || phone || ' ' || email || ' ' || source || ' ' || notes AS body
+FROM leads WHERE body LIKE ?
+UNION ALL
+SELECT lead_id, 'contact', label || ' ' || value AS body FROM lead_contacts WHERE body LIKE ?
+UNION ALL
+SELECT lead_id, 'note', body FROM notes WHERE body LIKE ?
+UNION ALL
+SELECT lead_id, 'task', title FROM tasks WHERE title LIKE ?
+LIMIT 500
+`, like, like, like, like)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m match
+			var body string
+			if err := rows.Scan(&m.leadID, &m.kind, &body); err != nil {
+				return nil, err
+			}
+			m.snippet = likeSnippet(body, q)
+			matches = append(matches, m)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Best match per lead, in rank order.
+	var hits []SearchHit
+	seen := make(map[int64]bool)
+	for _, m := range matches {
+		if seen[m.leadID] {
+			continue
+		}
+		seen[m.leadID] = true
+		l, err := r.GetLead(ctx, m.leadID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if l.ArchivedAt != nil {
+			continue
+		}
+		hits = append(hits, SearchHit{Lead: l, Kind: m.kind, Snippet: m.snippet, Rank: len(hits)})
+	}
+	return hits, nil
+}
+
+// contactDigitsSQL strips the usual phone punctuation from lead_contacts.value.
+const contactDigitsSQL = `replace(replace(replace(replace(replace(replace(value, ' ', ''), '-', ''), '(', ''), ')', ''), '.', ''), '+', '')`
+
+// phoneDigits returns q's digits when q looks like (part of) a phone
+// number: at least four digits and nothing but phone punctuation.
+func phoneDigits(q string) string {
+	var b strings.Builder
+	for _, r := range q {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune(" -().+", r):
+		default:
+			return ""
+		}
+	}
+	if b.Len() < 4 {
+		return ""
+	}
+	return b.String()
+}
+
+// likeSnippet cuts a window around the first case-insensitive match of q
+// and marks it the way FTS5's snippet() would.
+func likeSnippet(body, q string) string {
+	lower := strings.ToLower(body)
+	if len(lower) != len(body) {
+		return body // case folding changed byte offsets; skip highlighting
+	}
+	i := strings.Index(lower, strings.ToLower(q))
+	if i < 0 {
+		return body
+	}
+	const ctxChars = 40
+	start, end := i-ctxChars, i+len(q)+ctxChars
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(body) {
+		end, suffix = len(body), ""
+	}
+	for start > 0 && !utf8.RuneStart(body[start]) {
+		start--
+	}
+	for end < len(body) && !utf8.RuneStart(body[end]) {
+		end++
+	}
+	return prefix + body[start:i] + HighlightStart + body[i:i+len(q)] + HighlightEnd + body[i+len(q):end] + suffix
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -------- Task types, priorities & times --------
+
+// TaskTypes are the kinds of task; a task may also have none.
+var TaskTypes = []string{"call", "text", "email", "showing", "meeting"}
+
+// Task priorities. Normal is the zero value so older tasks need no backfill;
+// higher sorts first.
+const (
+	PriorityLow    = -1
+	PriorityNormal = 0
+	PriorityHigh   = 1
+)
+
+var priorityNames = map[int]string{PriorityLow: "low", PriorityNormal: "normal", PriorityHigh: "high"}
+
+// PriorityName is "low", "normal" or "high".
+func PriorityName(p int) string {
+	if n, ok := priorityNames[p]; ok {
+		return n
+	}
+	return "normal"
+}
+
+// ParsePriority reads "high", "normal" or "low" (or h/n/l); empty is normal.
+func ParsePriority(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return PriorityNormal, nil
+	}
+	for p, name := range priorityNames {
+		if s == name || s == name[:1] {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("priority %q: use high|normal|low", s)
+}
+
+// ParseTaskType checks a task type; empty means none.
+func ParseTaskType(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || oneOf(s, TaskTypes) {
+		return s, nil
+	}
+	return "", fmt.Errorf("task type %q: use %s", s, strings.Join(TaskTypes, "|"))
+}
+
+// ParseDueTime reads a time of day ("15:30", "9:00", "3pm", "3:30 pm") as
+// HH:MM; empty means the task is due some time that day.
+func ParseDueTime(s string) (string, error) {
+	in := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	if in == "" {
+		return "", nil
+	}
+	for _, layout := range []string{"15:04", "3:04pm", "3pm", "1504"} {
+		if t, err := time.Parse(layout, in); err == nil {
+			return t.Format("15:04"), nil
+		}
+	}
+	return "", fmt.Errorf("time %q: use HH:MM or e.g. 3pm", s)
+}
+
+// ParseSnooze reads how far to push a task: "30m", "2h", "1d", "3 days",
+// "1w".
+func ParseSnooze(s string) (time.Duration, error) {
+	in := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	i := strings.IndexFunc(in, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return 0, fmt.Errorf("snooze %q: use e.g. 2h, 1d, 3d or 1w", s)
+	}
+	n, _ := strconv.Atoi(in[:i])
+	unit := map[string]time.Duration{
+		"m": time.Minute, "min": time.Minute, "mins": time.Minute,
+		"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+		"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	}[in[i:]]
+	if unit == 0 || n < 1 {
+		return 0, fmt.Errorf("snooze %q: use e.g. 2h, 1d, 3d or 1w", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// Due is when the task is due in local time: midnight for all-day tasks.
+// ok is false when the task has no due date.
+func (t Task) Due() (due time.Time, ok bool) {
+	if t.DueDate == nil {
+		return time.Time{}, false
+	}
+	d := *t.DueDate
+	due = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.Local)
+	if hm, err := time.Parse("15:04", t.DueTime); err == nil {
+		due = due.Add(time.Duration(hm.Hour())*time.Hour + time.Duration(hm.Minute())*time.Minute)
+	}
+	return due, true
+}
+
+// validateTask normalizes a task's title, type and time before it is saved.
+func validateTask(t *Task) error {
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		return fmt.Errorf("task title is empty")
+	}
+	var err error
+	if t.Type, err = ParseTaskType(t.Type); err != nil {
+		return err
+	}
+	if t.DueTime, err = ParseDueTime(t.DueTime); err != nil {
+		return err
+	}
+	if t.DueTime != "" && t.DueDate == nil {
+		return fmt.Errorf("a due time needs a due date")
+	}
+	if _, ok := priorityNames[t.Priority]; !ok {
+		return fmt.Errorf("priority %d: use %d (low), %d (normal) or %d (high)", t.Priority, PriorityLow, PriorityNormal, PriorityHigh)
+	}
+	return nil
+}
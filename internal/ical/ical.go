@@ -0,0 +1,113 @@
+// Package ical writes tasks as an iCalendar (RFC 5545) calendar that
+// calendar apps can import or subscribe to.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mike-keough/pipelinepal/internal/contentline"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+// Writer streams a calendar: NewWriter writes the header, Task one entry
+// and Close the footer.
+type Writer struct {
+	lw     *contentline.Writer
+	events bool
+	stamp  string
+}
+
+// NewWriter starts a calendar named name. Tasks are written as to-dos, or
+// with events set as all-day events on their due date, for calendars that
+// do not show to-dos.
+func NewWriter(w io.Writer, name string, events bool) *Writer {
+	cw := &Writer{lw: contentline.NewWriter(w), events: events, stamp: time.Now().UTC().Format(stampLayout)}
+	cw.lw.Line("BEGIN:VCALENDAR")
+	cw.lw.Line("VERSION:2.0")
+	cw.lw.Line("PRODID:-//PipelinePal//EN")
+	cw.lw.Line("CALSCALE:GREGORIAN")
+	cw.lw.Line("X-WR-CALNAME:" + contentline.Escape(name))
+	// Hints for subscribed calendars: check back hourly.
+	cw.lw.Line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	cw.lw.Line("X-PUBLISHED-TTL:PT1H")
+	return cw
+}
+
+const (
+	stampLayout = "20060102T150405Z"
+	localLayout = "20060102T150405" // floating: the viewer's time zone
+	dateLayout  = "20060102"
+)
+
+// priorities maps task priority to iCalendar's 1 (highest) to 9 scale.
+var priorities = map[int]string{db.PriorityHigh: "1", db.PriorityNormal: "5", db.PriorityLow: "9"}
+
+// UID is a task's calendar id. It only depends on the task id, so a
+// re-import or feed refresh updates the entry instead of adding another.
+func UID(t db.Task) string {
+	return fmt.Sprintf("pipelinepal-task-%d@pipelinepal", t.ID)
+}
+
+// Task writes one task. Events need a date, so in an event calendar tasks
+// without a due date are left out.
+func (cw *Writer) Task(t db.Task) error {
+	comp := "VTODO"
+	if cw.events {
+		if t.DueDate == nil {
+			return cw.lw.Err()
+		}
+		comp = "VEVENT"
+	}
+	lw := cw.lw
+	lw.Line("BEGIN:" + comp)
+	lw.Line("UID:" + UID(t))
+	lw.Line("DTSTAMP:" + cw.stamp)
+	lw.Line("CREATED:" + t.CreatedAt.UTC().Format(stampLayout))
+	summary := t.Title + " – " + t.LeadName
+	if t.Type != "" {
+		summary = strings.ToUpper(t.Type[:1]) + t.Type[1:] + ": " + summary
+		lw.Line("CATEGORIES:" + strings.ToUpper(t.Type))
+	}
+	lw.Line("SUMMARY:" + contentline.Escape(summary))
+	lw.Line("DESCRIPTION:" + contentline.Escape(fmt.Sprintf("Lead #%d %s", t.LeadID, t.LeadName)))
+	if p, ok := priorities[t.Priority]; ok {
+		lw.Line("PRIORITY:" + p)
+	}
+	if due, ok := t.Due(); ok {
+		switch {
+		case t.DueTime != "" && cw.events:
+			// Timed tasks are half-hour events in the calendar's local time.
+			lw.Line("DTSTART:" + due.Format(localLayout))
+			lw.Line("DTEND:" + due.Add(30*time.Minute).Format(localLayout))
+		case t.DueTime != "":
+			lw.Line("DUE:" + due.Format(localLayout))
+		case cw.events:
+			lw.Line("DTSTART;VALUE=DATE:" + due.Format(dateLayout))
+			lw.Line("DTEND;VALUE=DATE:" + due.AddDate(0, 0, 1).Format(dateLayout))
+			lw.Line("TRANSP:TRANSPARENT")
+		default:
+			lw.Line("DUE;VALUE=DATE:" + due.Format(dateLayout))
+		}
+	}
+	if comp == "VTODO" {
+		if t.Status == "done" {
+			lw.Line("STATUS:COMPLETED")
+			if t.CompletedAt != nil {
+				lw.Line("COMPLETED:" + t.CompletedAt.UTC().Format(stampLayout))
+			}
+		} else {
+			lw.Line("STATUS:NEEDS-ACTION")
+		}
+	}
+	lw.Line("END:" + comp)
+	return lw.Err()
+}
+
+// Close ends the calendar.
+func (cw *Writer) Close() error {
+	cw.lw.Line("END:VCALENDAR")
+	return cw.lw.Err()
+}
@@ -17,14 +17,44 @@ type keyMap struct {
 	MoveL   key.Binding
 	MoveR   key.Binding
 
-	Notes key.Binding
-	Help  key.Binding
+	NextPipeline key.Binding
+
+	PrevTab  key.Binding
+	NextTab  key.Binding
+	SaveList key.Binding
+
+	TagFilter key.Binding
+	AddTag    key.Binding
+	RemoveTag key.Binding
+
+	Notes  key.Binding
+	Edit   key.Binding
+	Fields key.Binding
+	Deal   key.Binding
+	Merge  key.Binding
+	VCard  key.Binding
+	Help   key.Binding
+
+	Archive   key.Binding
+	TrashView key.Binding
+	Restore   key.Binding
+	Purge     key.Binding
+
+	StagesView key.Binding
+	MoveUp     key.Binding
+	MoveDown   key.Binding
+	Color      key.Binding
+	WinProb    key.Binding
 
 	Tab key.Binding
 
+	Forecast key.Binding
+
 	TasksView key.Binding
 	FollowUp  key.Binding
 	Complete  key.Binding
+	Snooze    key.Binding
+	Cadence   key.Binding
 }
 
 func keys() keyMap {
@@ -43,11 +73,38 @@ func keys() keyMap {
 		MoveL:   key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "move lead left")),
 		MoveR:   key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "move lead right")),
 
-		Notes:     key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add note")),
-		Help:      key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
-		Tab:       key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "switch view")),
-		TasksView: key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "tasks")),
-		FollowUp:  key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "new follow-up")),
-		Complete:  key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "complete task")),
+		NextPipeline: key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "next pipeline")),
+
+		PrevTab:  key.NewBinding(key.WithKeys("["), key.WithHelp("[", "previous smart list")),
+		NextTab:  key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next smart list")),
+		SaveList: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "save smart list")),
+
+		TagFilter: key.NewBinding(key.WithKeys("#"), key.WithHelp("#", "filter by tag")),
+		AddTag:    key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "add tag")),
+		RemoveTag: key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "remove tag")),
+
+		Notes:      key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add note")),
+		Edit:       key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit lead")),
+		Fields:     key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "edit custom fields")),
+		Deal:       key.NewBinding(key.WithKeys("$"), key.WithHelp("$", "deal")),
+		Merge:      key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "merge a duplicate in")),
+		VCard:      key.NewBinding(key.WithKeys("V"), key.WithHelp("V", "save as vCard")),
+		Archive:    key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "archive lead")),
+		TrashView:  key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "trash")),
+		Restore:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restore lead")),
+		Purge:      key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "delete forever")),
+		StagesView: key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "edit stages")),
+		MoveUp:     key.NewBinding(key.WithKeys("K"), key.WithHelp("K", "move up")),
+		MoveDown:   key.NewBinding(key.WithKeys("J"), key.WithHelp("J", "move down")),
+		Color:      key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "color")),
+		WinProb:    key.NewBinding(key.WithKeys("%"), key.WithHelp("%", "win probability")),
+		Forecast:   key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "forecast report")),
+		Help:       key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Tab:        key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "switch view")),
+		TasksView:  key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "tasks")),
+		FollowUp:   key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "new follow-up")),
+		Complete:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "complete task")),
+		Snooze:     key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "snooze task")),
+		Cadence:    key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "apply cadence")),
 	}
 }
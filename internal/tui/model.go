@@ -11,8 +11,9 @@ import (
 )
 
 type Model struct {
-	repo *db.Repo
-	ctx  context.Context
+	repo    *db.Repo
+	ctx     context.Context
+	dataDir string // where vCards are saved
 
 	w, h int
 
@@ -31,8 +32,28 @@ type Model struct {
 
 	leads leadsState
 	tasks tasksState
+	trash trashState
+
+	stages stagesState
+
+	// tagFilter narrows the board, leads list and tasks to one tag.
+	tagFilter string
+	tagForm   tagForm
 
 	addTask addTaskForm
+	snooze  snoozeForm
+
+	fieldsForm  fieldsForm
+	dealForm    dealForm
+	mergeForm   mergeForm
+	cadenceForm cadenceForm
+
+	// phoneRegion reads phone numbers typed without a country code.
+	phoneRegion string
+	dealPrompt  dealPromptMsg // set while asking to open a deal after a move
+
+	forecast []db.ForecastItem
+	income   db.IncomeReport
 
 	pending pendingSelection
 
@@ -40,9 +61,10 @@ type Model struct {
 	err    error
 }
 
-func New(repo *db.Repo) Model {
+func New(repo *db.Repo, dataDir string) Model {
 	m := Model{
 		repo:    repo,
+		dataDir: dataDir,
 		ctx:     context.Background(),
 		view:    ViewPipeline,
 		keys:    keys(),
@@ -51,7 +73,13 @@ func New(repo *db.Repo) Model {
 		addNote: newAddNoteForm(),
 		leads:   newLeadsState(),
 		tasks:   newTasksState(),
+		trash:   newTrashState(),
+		stages:  newStagesState(),
+		tagForm: newTagForm(),
 		addTask: newAddTaskForm(),
+		snooze:  newSnoozeForm(),
+
+		phoneRegion: "US", // until settings load
 	}
 	return m
 }
@@ -61,6 +89,7 @@ func (m Model) Init() tea.Cmd {
 		m.cmdLoadPipeline(),
 		m.cmdLoadLeads(""),
 		m.cmdLoadTasks(),
+		m.cmdLoadSettings(),
 	)
 }
 
@@ -80,6 +109,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.tasks.list.SetSize(m.w-4, m.h-8)
 		}
+		m.trash.list.SetSize(m.w-4, m.h-8)
 		return m, nil
 
 	case errMsg:
@@ -90,7 +120,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status = string(msg)
 		return m, nil
 
+	case mergeCandidatesMsg:
+		m.mergeForm.candidates = msg.matches
+		return m, nil
+
+	case mergeConfirmMsg:
+		m.mergeForm.confirm = msg.lead
+		return m, nil
+
+	case cadencesLoadedMsg:
+		m.cadenceForm.cadences = msg.cadences
+		return m, nil
+
+	case leadUpdatedMsg:
+		m.status = "Lead updated."
+		return m, tea.Batch(m.cmdLoadLeadDetail(msg.id), m.cmdLoadPipeline())
+
+	case newLeadInvalidMsg:
+		m.newLead.err, m.newLead.errStep = msg.err, msg.step
+		m.newLead.focusStep(msg.step)
+		m.view = ViewNewLead
+		return m, nil
+
+	case settingsLoadedMsg:
+		m.phoneRegion = msg.phoneRegion
+		if !msg.fullText {
+			m.status = db.SearchFallbackNote
+		}
+		return m, nil
+
+	case newLeadDupesMsg:
+		m.newLead.dupes = msg.matches
+		m.newLead.source.Focus()
+		m.view = ViewNewLead
+		return m, nil
+
+	case dealPromptMsg:
+		m.dealPrompt = msg
+		return m, nil
+
+	case forecastLoadedMsg:
+		m.forecast = msg.items
+		m.income = msg.income
+		return m, nil
+
 	case pipelineLoadedMsg:
+		m.pipe.Pipelines = msg.pipelines
+		m.pipe.Pipeline = msg.pipeline
 		m.pipe.Stages = msg.stages
 		m.pipe.ByStage = msg.byStage
 
@@ -119,6 +195,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// normal clamps
 		m.pipe.StageIndex = clamp(m.pipe.StageIndex, 0, len(m.pipe.Stages)-1)
 		m.pipe.LeadIndex = clamp(m.pipe.LeadIndex, 0, m.maxLeadIndexInStage())
+		m.stages.index = clamp(m.stages.index, 0, len(m.pipe.Stages)-1)
 		m.err = nil
 		return m, nil
 
@@ -130,15 +207,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case leadsLoadedMsg:
-		m.leads.items = msg.leads
-		items := make([]list.Item, 0, len(msg.leads))
-		for _, l := range msg.leads {
-			items = append(items, leadItem(l))
+		m.leads.items = make([]db.Lead, 0, len(msg.hits))
+		items := make([]list.Item, 0, len(msg.hits))
+		for _, h := range msg.hits {
+			m.leads.items = append(m.leads.items, h.Lead)
+			items = append(items, leadItem{L: h.Lead, Kind: h.Kind, Snippet: h.Snippet, match: m.s.Match})
 		}
 		m.leads.list.SetItems(items)
 		m.leads.loaded = true
 		return m, nil
 
+	case smartListsLoadedMsg:
+		m.leads.smartLists = msg.lists
+		m.leads.tab = clamp(m.leads.tab, 0, len(msg.lists))
+		for i, sl := range msg.lists {
+			if msg.selectName != "" && strings.EqualFold(sl.Name, msg.selectName) {
+				m.leads.tab = i + 1
+			}
+		}
+		return m, nil
+
 	case tasksLoadedMsg:
 		m.tasks.items = msg.tasks
 		items := make([]list.Item, 0, len(msg.tasks))
@@ -149,8 +237,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.tasks.loaded = true
 		return m, nil
 
+	case trashLoadedMsg:
+		m.trash.items = msg.leads
+		items := make([]list.Item, 0, len(msg.leads))
+		for _, l := range msg.leads {
+			items = append(items, trashItem{L: l})
+		}
+		m.trash.list.SetItems(items)
+		m.trash.loaded = true
+		return m, nil
+
+	case stageDeleteCheckMsg:
+		return m.handleStageDeleteCheck(msg)
+
 	case tea.KeyMsg:
 
+		if m.tagForm.active {
+			return m.updateTagForm(msg)
+		}
+		if m.dealPrompt.lead.ID != 0 {
+			return m.updateDealPrompt(msg)
+		}
+
 		// Global keys (ONLY when not typing)
 		if !m.isTyping() {
 			switch {
@@ -161,6 +269,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.view = ViewTasks
 				return m, m.cmdLoadTasks()
 
+			case key.Matches(msg, m.keys.TagFilter):
+				if m.view == ViewPipeline || m.view == ViewLeads || m.view == ViewTasks {
+					m.tagForm.open(tagModeFilter, "Filter by tag (empty clears)…", m.tagFilter)
+					return m, nil
+				}
+
+			case key.Matches(msg, m.keys.TrashView):
+				m.view = ViewTrash
+				m.trash.confirmPurge = 0
+				return m, m.cmdLoadTrash()
+
+			case key.Matches(msg, m.keys.StagesView):
+				m.view = ViewStages
+				m.stages.mode = stageModeList
+				m.stages.index = m.pipe.StageIndex
+				return m, m.cmdLoadPipeline()
+
+			case key.Matches(msg, m.keys.Forecast):
+				m.view = ViewForecast
+				return m, m.cmdLoadForecast()
+
 			case key.Matches(msg, m.keys.Help):
 				if m.view == ViewHelp {
 					m.view = ViewPipeline
@@ -173,7 +302,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.view == ViewPipeline {
 					m.view = ViewLeads
 					m.leads.search.Focus()
-					return m, m.cmdLoadLeads(strings.TrimSpace(m.leads.search.Value()))
+					return m, tea.Batch(m.cmdLoadLeads(strings.TrimSpace(m.leads.search.Value())), m.cmdLoadSmartLists(""))
 				}
 				if m.view == ViewLeads {
 					m.leads.search.Blur()
@@ -195,6 +324,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateNewLead(msg)
 		case ViewTasks:
 			return m.updateTasks(msg)
+		case ViewTrash:
+			return m.updateTrash(msg)
+		case ViewStages:
+			return m.updateStages(msg)
+		case ViewForecast:
+			return m.updateForecast(msg)
 		case ViewHelp:
 			return m, nil
 		}
@@ -212,23 +347,35 @@ func (m Model) View() string {
 
 	var body string
 	switch m.view {
+	case ViewPipeline, ViewLeads, ViewTasks:
+		body = m.viewTagFilterBar()
+	}
+	switch m.view {
 	case ViewPipeline:
-		body = m.viewPipeline()
+		body += m.viewPipeline()
 	case ViewTasks:
-		body = m.viewTasks()
+		body += m.viewTasks()
 
 	case ViewLeads:
-		body = m.viewLeads()
+		body += m.viewLeads()
 	case ViewLeadDetail:
 		body = m.viewLeadDetail()
 	case ViewNewLead:
 		body = m.viewNewLead()
+	case ViewTrash:
+		body = m.viewTrash()
+	case ViewStages:
+		body = m.viewStages()
+	case ViewForecast:
+		body = m.viewForecast()
 	case ViewHelp:
 		body = m.viewHelp()
 	}
 
 	foot := ""
-	if m.err != nil {
+	if m.dealPrompt.lead.ID != 0 {
+		foot = "\n" + m.viewDealPrompt()
+	} else if m.err != nil {
 		foot = "\n" + m.s.Error.Render("Error: "+m.err.Error())
 	} else if m.status != "" {
 		foot = "\n" + m.s.Subtle.Render(m.status)
@@ -245,7 +392,15 @@ func (m Model) isTyping() bool {
 		m.newLead.source.Focused() ||
 		m.addNote.active ||
 		m.addTask.active ||
-		m.leads.search.Focused()
+		m.snooze.active ||
+		m.stages.input.Focused() ||
+		m.leads.search.Focused() ||
+		m.leads.saveName.Focused() ||
+		m.tagForm.active ||
+		m.fieldsForm.active ||
+		m.dealForm.active ||
+		m.mergeForm.active ||
+		m.cadenceForm.active
 }
 
 // ---------- Commands + messages ----------
@@ -254,8 +409,10 @@ type errMsg struct{ err error }
 type statusMsg string
 
 type pipelineLoadedMsg struct {
-	stages  []db.Stage
-	byStage map[int64][]db.Lead
+	pipelines []db.Pipeline
+	pipeline  db.Pipeline
+	stages    []db.Stage
+	byStage   map[int64][]db.Lead
 }
 
 type leadDetailLoadedMsg struct {
@@ -263,13 +420,22 @@ type leadDetailLoadedMsg struct {
 }
 
 type leadsLoadedMsg struct {
-	leads []db.Lead
+	hits []db.SearchHit // Kind/Snippet are empty when listing without a query
+}
+
+type smartListsLoadedMsg struct {
+	lists      []db.SmartList
+	selectName string // name of the list to make the active tab, if any
 }
 
 type tasksLoadedMsg struct {
 	tasks []db.Task
 }
 
+type trashLoadedMsg struct {
+	leads []db.Lead
+}
+
 type pendingSelection struct {
 	leadID  int64
 	stageID int64
@@ -277,29 +443,80 @@ type pendingSelection struct {
 }
 
 func (m Model) cmdLoadPipeline() tea.Cmd {
+	current := m.pipe.Pipeline.ID
+	tag := m.tagFilter
 	return func() tea.Msg {
-		stages, err := m.repo.ListStages(m.ctx)
+		pipelines, err := m.repo.ListPipelines(m.ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		if len(pipelines) == 0 {
+			return errMsg{errString("no pipelines configured")}
+		}
+		pipeline := pipelines[0]
+		for _, p := range pipelines {
+			if p.ID == current {
+				pipeline = p
+				break
+			}
+		}
+		stages, err := m.repo.ListStages(m.ctx, pipeline.ID)
 		if err != nil {
 			return errMsg{err}
 		}
-		byStage, err := m.repo.ListLeadsByStage(m.ctx)
+		byStage, err := m.repo.ListLeadsByStage(m.ctx, pipeline.ID)
 		if err != nil {
 			return errMsg{err}
 		}
-		return pipelineLoadedMsg{stages: stages, byStage: byStage}
+		if tag != "" {
+			for id, leads := range byStage {
+				kept := leads[:0]
+				for _, l := range leads {
+					if hasTag(l.Tags, tag) {
+						kept = append(kept, l)
+					}
+				}
+				byStage[id] = kept
+			}
+		}
+		return pipelineLoadedMsg{pipelines: pipelines, pipeline: pipeline, stages: stages, byStage: byStage}
 	}
 }
 
 func (m Model) cmdLoadTasks() tea.Cmd {
+	tag := m.tagFilter
 	return func() tea.Msg {
 		tasks, err := m.repo.ListOpenTasks(m.ctx)
 		if err != nil {
 			return errMsg{err}
 		}
+		if tag != "" {
+			ids, err := m.repo.LeadIDsWithTag(m.ctx, tag)
+			if err != nil {
+				return errMsg{err}
+			}
+			kept := tasks[:0]
+			for _, t := range tasks {
+				if ids[t.LeadID] {
+					kept = append(kept, t)
+				}
+			}
+			tasks = kept
+		}
 		return tasksLoadedMsg{tasks: tasks}
 	}
 }
 
+func (m Model) cmdLoadTrash() tea.Cmd {
+	return func() tea.Msg {
+		leads, err := m.repo.ListArchivedLeads(m.ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return trashLoadedMsg{leads: leads}
+	}
+}
+
 func (m Model) cmdLoadLeadDetail(id int64) tea.Cmd {
 	return func() tea.Msg {
 		lead, err := m.repo.GetLead(m.ctx, id)
@@ -314,21 +531,95 @@ func (m Model) cmdLoadLeadDetail(id int64) tea.Cmd {
 		if err != nil {
 			return errMsg{err}
 		}
+		history, err := m.repo.ListStageHistory(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		fields, err := m.repo.LeadFieldValues(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		props, err := m.repo.ListLeadProperties(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		contacts, err := m.repo.ListLeadContacts(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		related, err := m.repo.ListLeadRelationships(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		deals, err := m.repo.ListLeadDeals(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		expected, err := m.repo.LeadExpectedValue(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
 		return leadDetailLoadedMsg{detail: LeadDetailState{
-			LeadID: id,
-			Lead:   lead,
-			Tasks:  tasks,
-			Notes:  notes,
+			LeadID:     id,
+			Lead:       lead,
+			Tasks:      tasks,
+			Notes:      notes,
+			History:    history,
+			Fields:     fields,
+			Properties: props,
+			Contacts:   contacts,
+			Related:    related,
+			Deals:      deals,
+			Expected:   expected,
 		}}
 	}
 }
 
+type settingsLoadedMsg struct {
+	phoneRegion string
+	fullText    bool // search uses FTS5 rather than the LIKE fallback
+}
+
+func (m Model) cmdLoadSettings() tea.Cmd {
+	return func() tea.Msg {
+		region, err := m.repo.PhoneRegion(m.ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return settingsLoadedMsg{phoneRegion: region, fullText: m.repo.FullTextSearch(m.ctx)}
+	}
+}
+
+func (m Model) cmdLoadSmartLists(selectName string) tea.Cmd {
+	return func() tea.Msg {
+		lists, err := m.repo.ListSmartLists(m.ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return smartListsLoadedMsg{lists: lists, selectName: selectName}
+	}
+}
+
 func (m Model) cmdLoadLeads(q string) tea.Cmd {
+	if m.tagFilter != "" {
+		q = strings.TrimSpace(q + ` tag:"` + m.tagFilter + `"`)
+	}
 	return func() tea.Msg {
-		leads, err := m.repo.ListLeads(m.ctx, q)
+		if strings.TrimSpace(q) != "" {
+			hits, err := m.repo.QueryLeads(m.ctx, q)
+			if err != nil {
+				return errMsg{err}
+			}
+			return leadsLoadedMsg{hits: hits}
+		}
+		leads, err := m.repo.ListLeads(m.ctx, "")
 		if err != nil {
 			return errMsg{err}
 		}
-		return leadsLoadedMsg{leads: leads}
+		hits := make([]db.SearchHit, 0, len(leads))
+		for _, l := range leads {
+			hits = append(hits, db.SearchHit{Lead: l})
+		}
+		return leadsLoadedMsg{hits: hits}
 	}
 }
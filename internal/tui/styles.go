@@ -19,6 +19,8 @@ type styles struct {
 
 	Badge lipgloss.Style
 	Error lipgloss.Style
+	Match lipgloss.Style
+	Chip  lipgloss.Style
 }
 
 func makeStyles() styles {
@@ -97,6 +99,18 @@ func makeStyles() styles {
 			MarginLeft(1).
 			Bold(true),
 
+		// Search hits inside snippets
+		Match: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.AdaptiveColor{Light: "#B45309", Dark: "#FCD34D"}),
+
+		// Tag chips
+		Chip: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#065F46", Dark: "#6EE7B7"}).
+			Background(lipgloss.AdaptiveColor{Light: "#D1FAE5", Dark: "#064E3B"}).
+			MarginRight(1),
+
 		// Error messages
 		Error: lipgloss.NewStyle().
 			Bold(true).
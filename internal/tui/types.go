@@ -11,9 +11,14 @@ const (
 	ViewNewLead
 	ViewTasks
 	ViewHelp
+	ViewTrash
+	ViewStages
+	ViewForecast
 )
 
 type PipelineState struct {
+	Pipelines  []db.Pipeline
+	Pipeline   db.Pipeline // the board being shown; zero means the first pipeline
 	Stages     []db.Stage
 	ByStage    map[int64][]db.Lead
 	StageIndex int
@@ -21,9 +26,16 @@ type PipelineState struct {
 }
 
 type LeadDetailState struct {
-	LeadID    int64
-	Lead      db.Lead
-	Tasks     []db.Task
-	TaskIndex int
-	Notes     []db.Note
+	LeadID     int64
+	Lead       db.Lead
+	Tasks      []db.Task
+	TaskIndex  int
+	Notes      []db.Note
+	History    []db.StageChange
+	Fields     []db.FieldValue
+	Properties []db.PropertyLink
+	Contacts   []db.ContactMethod
+	Related    []db.Relationship
+	Deals      []db.Deal
+	Expected   float64 // weighted GCI of open deals at the current stage
 }
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 func clamp(v, lo, hi int) int {
@@ -32,3 +33,25 @@ func fmtLeadLine(name, leadType, source string) string {
 	}
 	return fmt.Sprintf("%s [%s] • %s", name, leadType, source)
 }
+
+// fileName turns s into a lower-case file name of letters, digits and
+// dashes, or fallback if nothing is left.
+func fileName(s, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
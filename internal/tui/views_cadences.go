@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+// cadenceForm applies a follow-up cadence to the lead open in the detail
+// view: pick one of the cadences on file and its tasks are created from
+// today.
+type cadenceForm struct {
+	active   bool
+	cadences []db.Cadence
+	index    int
+}
+
+type cadencesLoadedMsg struct{ cadences []db.Cadence }
+
+func (f *cadenceForm) open() {
+	f.active = true
+	f.cadences = nil
+	f.index = 0
+}
+
+func (f *cadenceForm) close() {
+	f.active = false
+}
+
+func (m Model) cmdLoadCadences() tea.Cmd {
+	return func() tea.Msg {
+		all, err := m.repo.ListCadences(m.ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return cadencesLoadedMsg{cadences: all}
+	}
+}
+
+func (m Model) updateCadenceForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &m.cadenceForm
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		f.close()
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		f.index = clamp(f.index-1, 0, max(len(f.cadences)-1, 0))
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		f.index = clamp(f.index+1, 0, max(len(f.cadences)-1, 0))
+		return m, nil
+	case msg.String() == "enter":
+		if len(f.cadences) == 0 {
+			f.close()
+			return m, nil
+		}
+		c, leadID := f.cadences[f.index], m.dtl.LeadID
+		f.close()
+		cmd := func() tea.Msg {
+			n, err := m.repo.ApplyCadence(m.ctx, c, leadID, time.Now())
+			if err != nil {
+				return errMsg{err}
+			}
+			return statusMsg(fmt.Sprintf("Applied cadence %s: %d follow-up(s) added.", c.Name, n))
+		}
+		return m, tea.Sequence(cmd, tea.Batch(m.cmdLoadLeadDetail(leadID), m.cmdLoadPipeline(), m.cmdLoadTasks()))
+	}
+	return m, nil
+}
+
+// viewCadenceForm renders the cadence picker at the top of the lead detail.
+func (m Model) viewCadenceForm() []string {
+	f := m.cadenceForm
+	out := []string{m.s.Header.Render("Apply a follow-up cadence to " + m.dtl.Lead.FullName)}
+	if len(f.cadences) == 0 {
+		out = append(out,
+			m.s.Subtle.Render("(no cadences yet: pipelinepal cadence add)"),
+			m.s.Subtle.Render("esc: cancel"))
+		return out
+	}
+	for i, c := range f.cadences {
+		line := fmt.Sprintf("%s (%d step(s))", c.Name, len(c.Steps))
+		if i != f.index {
+			out = append(out, m.s.Card.Render(line))
+			continue
+		}
+		out = append(out, m.s.CardSel.Render(line))
+		for _, s := range c.Steps {
+			step := fmt.Sprintf("  day %d: %s", s.Day, s.Title)
+			if s.Type != "" {
+				step = fmt.Sprintf("  day %d %s: %s", s.Day, s.Type, s.Title)
+			}
+			out = append(out, m.s.Subtle.Render(step))
+		}
+	}
+	out = append(out, m.s.Subtle.Render("j/k: choose • enter: add its tasks from today • esc: cancel"))
+	return out
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+// fieldsForm edits every custom field of the open lead at once, one input
+// per field definition.
+type fieldsForm struct {
+	active bool
+	fields []db.CustomField
+	inputs []textinput.Model
+	focus  int
+	err    string
+}
+
+func (f *fieldsForm) open(values []db.FieldValue) {
+	f.active = true
+	f.focus = 0
+	f.err = ""
+	f.fields = make([]db.CustomField, 0, len(values))
+	f.inputs = make([]textinput.Model, 0, len(values))
+	for _, v := range values {
+		ti := textinput.New()
+		ti.Width = 40
+		ti.Placeholder = fieldPlaceholder(v.Field)
+		ti.SetValue(v.Value)
+		f.fields = append(f.fields, v.Field)
+		f.inputs = append(f.inputs, ti)
+	}
+	f.focusInput(0)
+}
+
+func (f *fieldsForm) close() {
+	f.active = false
+	for i := range f.inputs {
+		f.inputs[i].Blur()
+	}
+}
+
+func (f *fieldsForm) focusInput(i int) {
+	if len(f.inputs) == 0 {
+		return
+	}
+	f.inputs[f.focus].Blur()
+	f.focus = (i + len(f.inputs)) % len(f.inputs)
+	f.inputs[f.focus].Focus()
+}
+
+func fieldPlaceholder(f db.CustomField) string {
+	switch f.Kind {
+	case "number":
+		return "number"
+	case "money":
+		return "amount, e.g. 450k"
+	case "date":
+		return "YYYY-MM-DD"
+	case "choice":
+		return strings.Join(f.Choices, " | ")
+	}
+	return "text"
+}
+
+func (m Model) updateFieldsForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.fieldsForm.close()
+		return m, nil
+	case "tab", "down":
+		m.fieldsForm.focusInput(m.fieldsForm.focus + 1)
+		return m, nil
+	case "shift+tab", "up":
+		m.fieldsForm.focusInput(m.fieldsForm.focus - 1)
+		return m, nil
+	case "enter":
+		values := make(map[int64]string, len(m.fieldsForm.fields))
+		for i, f := range m.fieldsForm.fields {
+			raw := m.fieldsForm.inputs[i].Value()
+			if _, err := db.ParseFieldValue(f, raw); err != nil {
+				m.fieldsForm.err = err.Error()
+				m.fieldsForm.focusInput(i)
+				return m, nil
+			}
+			values[f.ID] = raw
+		}
+
+		leadID := m.dtl.LeadID
+		m.fieldsForm.close()
+		cmd := func() tea.Msg {
+			if err := m.repo.SetLeadFields(m.ctx, leadID, values); err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Custom fields saved.")
+		}
+		return m, tea.Batch(cmd, m.cmdLoadLeadDetail(leadID))
+	}
+
+	var c tea.Cmd
+	m.fieldsForm.inputs[m.fieldsForm.focus], c = m.fieldsForm.inputs[m.fieldsForm.focus].Update(msg)
+	return m, c
+}
+
+// viewCustomFields renders the lead's custom fields, or the edit form.
+func (m Model) viewCustomFields() []string {
+	if m.fieldsForm.active {
+		out := make([]string, 0, len(m.fieldsForm.inputs)+2)
+		for i, f := range m.fieldsForm.fields {
+			in := m.fieldsForm.inputs[i].View()
+			if i == m.fieldsForm.focus {
+				in = m.s.BorderFocus.Render(in)
+			} else {
+				in = m.s.Border.Render(in)
+			}
+			out = append(out, m.s.Subtle.Render(fmt.Sprintf("%s (%s)", f.Name, f.Kind)), in)
+		}
+		if m.fieldsForm.err != "" {
+			out = append(out, m.s.Error.Render(m.fieldsForm.err))
+		}
+		return append(out, m.s.Subtle.Render("tab: next field • enter: save • esc: cancel"))
+	}
+
+	if len(m.dtl.Fields) == 0 {
+		return []string{m.s.Subtle.Render("(no custom fields defined — see `pipelinepal field add`)")}
+	}
+	out := make([]string, 0, len(m.dtl.Fields))
+	for _, fv := range m.dtl.Fields {
+		out = append(out, fmt.Sprintf("%s %s",
+			m.s.Subtle.Render(fv.Field.Name+":"),
+			emptyDash(db.FormatFieldValue(fv.Field, fv.Value)),
+		))
+	}
+	return out
+}
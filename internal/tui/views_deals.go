@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+// dealForm creates or edits one deal on a lead.
+type dealForm struct {
+	active bool
+	deal   db.Deal // ID 0 when creating
+	inputs []textinput.Model
+	focus  int
+	err    string
+}
+
+var dealFormLabels = []string{"Price", "Commission %", "Referral %", "Side", "Status", "Contract date", "Expected close"}
+
+const (
+	dealPrice = iota
+	dealRate
+	dealReferral
+	dealSide
+	dealStatus
+	dealContract
+	dealClose
+)
+
+// open fills the form from d. A zero d.ID means a new deal for d.LeadID.
+func (f *dealForm) open(d db.Deal) {
+	f.active = true
+	f.deal = d
+	f.err = ""
+	f.focus = 0
+
+	placeholders := []string{
+		"e.g. 450k",
+		"e.g. 3",
+		"referral fee, % of GCI",
+		strings.Join(db.DealSides, " | "),
+		strings.Join(db.DealStatuses, " | "),
+		"YYYY-MM-DD",
+		"YYYY-MM-DD",
+	}
+	values := []string{"", "", "", d.Side, d.Status, fmtOptionalDate(d.ContractDate), fmtOptionalDate(d.ExpectedClose)}
+	if d.Price > 0 {
+		values[dealPrice] = strconv.FormatFloat(d.Price, 'f', -1, 64)
+	}
+	if d.CommissionRate > 0 {
+		values[dealRate] = strconv.FormatFloat(d.CommissionRate, 'f', -1, 64)
+	}
+	if d.ReferralPct > 0 {
+		values[dealReferral] = strconv.FormatFloat(d.ReferralPct, 'f', -1, 64)
+	}
+
+	f.inputs = make([]textinput.Model, len(dealFormLabels))
+	for i := range f.inputs {
+		ti := textinput.New()
+		ti.Width = 30
+		ti.Placeholder = placeholders[i]
+		if values[i] != "—" {
+			ti.SetValue(values[i])
+		}
+		f.inputs[i] = ti
+	}
+	f.inputs[0].Focus()
+}
+
+func (f *dealForm) close() {
+	f.active = false
+	for i := range f.inputs {
+		f.inputs[i].Blur()
+	}
+}
+
+func (f *dealForm) focusInput(i int) {
+	f.inputs[f.focus].Blur()
+	f.focus = (i + len(f.inputs)) % len(f.inputs)
+	f.inputs[f.focus].Focus()
+}
+
+// parse reads the inputs back into a deal, or reports the first bad field.
+func (f *dealForm) parse() (db.Deal, int, error) {
+	d := f.deal
+	val := func(i int) string { return strings.TrimSpace(f.inputs[i].Value()) }
+
+	var err error
+	d.Price = 0
+	if v := val(dealPrice); v != "" {
+		if d.Price, err = db.ParseAmount(v); err != nil {
+			return d, dealPrice, fmt.Errorf("price: %q is not an amount", v)
+		}
+	}
+	d.CommissionRate = 0
+	if v := strings.TrimSuffix(val(dealRate), "%"); v != "" {
+		if d.CommissionRate, err = strconv.ParseFloat(v, 64); err != nil {
+			return d, dealRate, fmt.Errorf("commission: %q is not a percentage", v)
+		}
+	}
+	d.ReferralPct = 0
+	if v := strings.TrimSuffix(val(dealReferral), "%"); v != "" {
+		if d.ReferralPct, err = strconv.ParseFloat(v, 64); err != nil {
+			return d, dealReferral, fmt.Errorf("referral: %q is not a percentage", v)
+		}
+	}
+	d.Side = strings.ToLower(val(dealSide))
+	d.Status = strings.ToLower(val(dealStatus))
+	if d.ContractDate, err = parseOptionalDue(val(dealContract)); err != nil {
+		return d, dealContract, fmt.Errorf("contract date: use YYYY-MM-DD")
+	}
+	if d.ExpectedClose, err = parseOptionalDue(val(dealClose)); err != nil {
+		return d, dealClose, fmt.Errorf("expected close: use YYYY-MM-DD")
+	}
+	if d.Status != "closed" {
+		d.ClosedDate = nil
+	}
+	return d, 0, nil
+}
+
+// newDealFor is the starting point for a lead's first deal.
+func newDealFor(l db.Lead) db.Deal {
+	side := "buyer"
+	if l.LeadType == "seller" {
+		side = "listing"
+	}
+	return db.Deal{LeadID: l.ID, LeadName: l.FullName, Side: side, Status: "open", CommissionRate: 3}
+}
+
+// openDealForm edits the lead's open deal, or starts a new one.
+func (m *Model) openDealForm() {
+	for _, d := range m.dtl.Deals {
+		if d.Status == "open" {
+			m.dealForm.open(d)
+			return
+		}
+	}
+	m.dealForm.open(newDealFor(m.dtl.Lead))
+}
+
+func (m Model) updateDealForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.dealForm.close()
+		return m, nil
+	case "tab", "down":
+		m.dealForm.focusInput(m.dealForm.focus + 1)
+		return m, nil
+	case "shift+tab", "up":
+		m.dealForm.focusInput(m.dealForm.focus - 1)
+		return m, nil
+	case "enter":
+		d, bad, err := m.dealForm.parse()
+		if err != nil {
+			m.dealForm.err = err.Error()
+			m.dealForm.focusInput(bad)
+			return m, nil
+		}
+		m.dealForm.close()
+
+		cmd := func() tea.Msg {
+			var err error
+			if d.ID == 0 {
+				_, err = m.repo.CreateDeal(m.ctx, d)
+			} else {
+				err = m.repo.UpdateDeal(m.ctx, d)
+			}
+			if err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Deal saved.")
+		}
+		return m, tea.Batch(cmd, m.cmdLoadLeadDetail(d.LeadID))
+	}
+
+	var c tea.Cmd
+	m.dealForm.inputs[m.dealForm.focus], c = m.dealForm.inputs[m.dealForm.focus].Update(msg)
+	return m, c
+}
+
+// viewDeals renders the deal panel of the lead detail view.
+func (m Model) viewDeals() []string {
+	if m.dealForm.active {
+		title := "New deal"
+		if m.dealForm.deal.ID != 0 {
+			title = fmt.Sprintf("Edit deal #%d", m.dealForm.deal.ID)
+		}
+		out := []string{m.s.Subtle.Render(title)}
+		for i, label := range dealFormLabels {
+			in := m.dealForm.inputs[i].View()
+			if i == m.dealForm.focus {
+				in = m.s.BorderFocus.Render(in)
+			} else {
+				in = m.s.Border.Render(in)
+			}
+			out = append(out, m.s.Subtle.Render(label), in)
+		}
+		if m.dealForm.err != "" {
+			out = append(out, m.s.Error.Render(m.dealForm.err))
+		}
+		return append(out, m.s.Subtle.Render("tab: next field • enter: save • esc: cancel"))
+	}
+
+	if len(m.dtl.Deals) == 0 {
+		return []string{m.s.Subtle.Render("(no deals yet — $: new deal)")}
+	}
+	out := make([]string, 0, len(m.dtl.Deals)+1)
+	for _, d := range m.dtl.Deals {
+		out = append(out, fmt.Sprintf("%s %s", m.s.Badge.Render(strings.ToUpper(d.Status)), fmtDeal(d)))
+	}
+	if m.dtl.Expected > 0 {
+		out = append(out, m.s.Subtle.Render(fmt.Sprintf("Expected value: %s at %s", db.FormatMoney(m.dtl.Expected), m.dtl.Lead.StageName)))
+	}
+	return out
+}
+
+// fmtDeal is the one-line deal summary used in the detail panel.
+func fmtDeal(d db.Deal) string {
+	parts := []string{
+		d.Side + " side",
+		fmt.Sprintf("%s @ %s%% → GCI %s", db.FormatMoney(d.Price),
+			strconv.FormatFloat(d.CommissionRate, 'f', -1, 64), db.FormatMoney(d.GrossCommission())),
+	}
+	if d.ReferralPct > 0 {
+		parts = append(parts, fmt.Sprintf("referral %s%%", strconv.FormatFloat(d.ReferralPct, 'f', -1, 64)))
+	}
+	if d.SplitPlanName != "" {
+		parts = append(parts, "plan "+d.SplitPlanName)
+	}
+	if d.ContractDate != nil {
+		parts = append(parts, "contract "+fmtOptionalDate(d.ContractDate))
+	}
+	if d.ClosedDate != nil {
+		parts = append(parts, "closed "+fmtOptionalDate(d.ClosedDate))
+	} else if d.ExpectedClose != nil {
+		parts = append(parts, "closes "+fmtOptionalDate(d.ExpectedClose))
+	}
+	if d.PropertyAddress != "" {
+		parts = append(parts, d.PropertyAddress)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// dealPromptMsg asks whether to open a deal for a lead that was just moved
+// into a stage with DealPrompt set.
+type dealPromptMsg struct {
+	lead  db.Lead
+	stage string
+}
+
+// updateDealPrompt answers the "create a deal?" question on the board.
+func (m Model) updateDealPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	l := m.dealPrompt.lead
+	m.dealPrompt = dealPromptMsg{}
+	if msg.String() != "y" {
+		return m, nil
+	}
+	m.dealForm.open(newDealFor(l))
+	return m, m.cmdLoadLeadDetail(l.ID)
+}
+
+func (m Model) viewDealPrompt() string {
+	return m.s.Badge.Render(fmt.Sprintf("%s moved to %s. Create a deal? (y/n)", m.dealPrompt.lead.FullName, m.dealPrompt.stage))
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+type forecastLoadedMsg struct {
+	items  []db.ForecastItem
+	income db.IncomeReport
+}
+
+func (m Model) cmdLoadForecast() tea.Cmd {
+	return func() tea.Msg {
+		items, err := m.repo.Forecast(m.ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		income, err := m.repo.Income(m.ctx, time.Now().UTC())
+		if err != nil {
+			return errMsg{err}
+		}
+		return forecastLoadedMsg{items: items, income: income}
+	}
+}
+
+func (m Model) updateForecast(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Back) {
+		m.view = ViewPipeline
+		return m, m.cmdLoadPipeline()
+	}
+	return m, nil
+}
+
+func (m Model) viewForecast() string {
+	lines := []string{
+		m.s.Header.Render("Forecast — weighted GCI of open deals"),
+		m.s.Subtle.Render("weighted = price × commission × stage win probability • set probabilities in S with % • esc: back"),
+		"",
+	}
+	if len(m.forecast) == 0 {
+		lines = append(lines, m.s.Subtle.Render("(no open deals — add one from a lead with $)"))
+		lines = append(lines, m.viewIncome()...)
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	row := func(label string, deals int, gci, weighted float64) string {
+		return fmt.Sprintf("%-28s %5d  %14s  %14s", label, deals, db.FormatMoney(gci), db.FormatMoney(weighted))
+	}
+	head := m.s.Subtle.Render(fmt.Sprintf("%-28s %5s  %14s  %14s", "", "deals", "GCI", "weighted"))
+
+	lines = append(lines, m.s.Header.Render("By expected close"), head)
+	var total db.ForecastMonth
+	for _, fm := range db.ForecastByMonth(m.forecast) {
+		label := "no close date"
+		if t, err := time.Parse("2006-01", fm.Month); err == nil {
+			label = t.Format("Jan 2006")
+		}
+		lines = append(lines, row(label, fm.Deals, fm.GCI, fm.Weighted))
+		total.Deals += fm.Deals
+		total.GCI += fm.GCI
+		total.Weighted += fm.Weighted
+	}
+	lines = append(lines, m.s.Header.Render(row("Total", total.Deals, total.GCI, total.Weighted)), "")
+
+	// Items arrive in board order, so each stage's deals are contiguous.
+	lines = append(lines, m.s.Header.Render("By stage"), head)
+	for i := 0; i < len(m.forecast); {
+		it := m.forecast[i]
+		var n int
+		var gci, weighted float64
+		for ; i < len(m.forecast) && m.forecast[i].StageID == it.StageID; i++ {
+			n++
+			gci += m.forecast[i].Deal.GrossCommission()
+			weighted += m.forecast[i].Weighted()
+		}
+		label := fmt.Sprintf("%s/%s (%d%%)", it.Pipeline, it.Stage, it.WinProb)
+		lines = append(lines, row(ellipsize(label, 28), n, gci, weighted))
+	}
+
+	lines = append(lines, m.viewIncome()...)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// viewIncome summarises closed deals for the current cap year of each split
+// plan: GCI, net after splits and fees, and progress toward the cap.
+func (m Model) viewIncome() []string {
+	if len(m.income.Plans) == 0 {
+		return nil
+	}
+	lines := []string{"", m.s.Header.Render("Year to date — closed deals"),
+		m.s.Subtle.Render(fmt.Sprintf("%-28s %5s  %14s  %14s  %s", "", "deals", "GCI", "net", "cap"))}
+	for _, y := range m.income.Plans {
+		name := y.Plan.Name
+		if name == "" {
+			name = "(no split plan)"
+		}
+		capText := ""
+		switch {
+		case y.Plan.AnnualCap <= 0:
+		case y.CapHitOn != nil:
+			capText = m.s.Badge.Render("capped " + y.CapHitOn.Format("Jan 2"))
+		default:
+			capText = fmt.Sprintf("%s of %s", db.FormatMoney(y.CapPaid), db.FormatMoney(y.Plan.AnnualCap))
+		}
+		lines = append(lines, fmt.Sprintf("%-28s %5d  %14s  %14s  %s",
+			ellipsize(name+" since "+y.Since.Format("Jan 2"), 28), y.Deals, db.FormatMoney(y.GCI), db.FormatMoney(y.Net), capText))
+	}
+	return lines
+}
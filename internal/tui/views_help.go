@@ -9,16 +9,54 @@ func (m Model) viewHelp() string {
 		m.s.Header.Render("Pipeline view"),
 		"- arrows / h j k l: navigate",
 		"- enter: open lead",
-		"- n: new lead",
+		"- n: new lead (lands in the pipeline for its type)",
+		"- p: switch pipeline (buyer, seller, rental…)",
 		"- t: tasks",
 		"- H / L: move lead left/right (between stages)",
+		"- x: archive lead (moves it to the trash)",
+		"- V: save lead as a vCard (.vcf) in the data directory",
 		"",
 		m.s.Header.Render("Lead detail"),
 		"- a: add note",
+		"- f: new follow-up task: due date and time, repeat (weekly, every 30 days, quarterly…), type, priority",
+		"- c: complete selected task (a repeating one schedules its next occurrence)",
+		"- z: snooze selected task (2h, 1d, 1w…)",
+		"- A: apply a follow-up cadence (its tasks are added from today; manage them with pipelinepal cadence)",
+		"- e: edit lead",
+		"- F: edit custom fields (tab between fields)",
+		"- $: new deal, or edit the open one (price, commission, side, dates)",
+		"- + / -: add / remove tag",
+		"- M: merge a duplicate into this lead (notes, tasks, tags, history move over)",
+		"- V: save as a vCard (.vcf) in the data directory",
+		"- x: archive lead",
 		"- esc: back",
 		"",
+		m.s.Header.Render("Leads (tab)"),
+		"- /: search or filter, e.g. type:buyer source:zillow stage:Contacted stale:>14d has:open-task",
+		"  custom fields: field:\"pre-approval>=400k\" field:district=Lincoln field:move-by (is set)",
+		"- [ / ]: switch smart list tabs • s: save query as smart list • D: delete smart list",
+		"",
+		m.s.Header.Render("Tasks (t)"),
+		"- sorted by priority (‼ high, ↓ low), then due date and time",
+		"- enter: open lead • c: complete • z: snooze (2h, 1d, 1w…)",
+		"",
+		m.s.Header.Render("Stages (S)"),
+		"- n: add • e: rename • C: color",
+		"- K / J: move stage up/down",
+		"- %: set win probability (weights the forecast)",
+		"- $: toggle the \"create a deal?\" prompt when leads move into the stage",
+		"- D: delete (asks where its leads should go)",
+		"",
+		m.s.Header.Render("Trash"),
+		"- r: restore lead",
+		"- D D: delete forever (notes and tasks too)",
+		"",
 		m.s.Header.Render("Global"),
 		"- t: tasks",
+		"- T: trash",
+		"- #: filter board, leads and tasks by tag",
+		"- S: edit stages",
+		"- R: forecast report (weighted GCI by expected close month, YTD net income)",
 		"- tab: switch Pipeline/Leads",
 		"- ?: help",
 		"- q: quit",
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -9,6 +12,8 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mike-keough/pipelinepal/internal/db"
+	"github.com/mike-keough/pipelinepal/internal/vcard"
 )
 
 type addNoteForm struct {
@@ -35,10 +40,18 @@ func (f *addNoteForm) close() {
 }
 
 type addTaskForm struct {
-	active bool
-	step   int // 0=title, 1=due
-	title  textinput.Model
-	due    textinput.Model
+	active   bool
+	step     int // 0=title, 1=due, 2=repeat, 3=type, 4=priority
+	title    textinput.Model
+	due      textinput.Model
+	repeat   textinput.Model
+	ttype    textinput.Model
+	priority textinput.Model
+}
+
+// inputs are the form's fields in step order.
+func (f *addTaskForm) inputs() []*textinput.Model {
+	return []*textinput.Model{&f.title, &f.due, &f.repeat, &f.ttype, &f.priority}
 }
 
 func newAddTaskForm() addTaskForm {
@@ -47,24 +60,49 @@ func newAddTaskForm() addTaskForm {
 	t.Width = 60
 
 	d := textinput.New()
-	d.Placeholder = "Due date (YYYY-MM-DD) optional"
-	d.Width = 30
+	d.Placeholder = "Due (YYYY-MM-DD, optionally a time: 2006-01-02 15:30 or 3pm) optional"
+	d.Width = 60
+
+	r := textinput.New()
+	r.Placeholder = "Repeat (weekly, every 30 days, quarterly…) optional"
+	r.Width = 50
 
-	return addTaskForm{title: t, due: d}
+	ty := textinput.New()
+	ty.Placeholder = "Type (" + strings.Join(db.TaskTypes, ", ") + ") optional"
+	ty.Width = 50
+
+	p := textinput.New()
+	p.Placeholder = "Priority (high, normal, low) default normal"
+	p.Width = 50
+
+	return addTaskForm{title: t, due: d, repeat: r, ttype: ty, priority: p}
 }
 
 func (f *addTaskForm) open() {
 	f.active = true
 	f.step = 0
-	f.title.SetValue("")
-	f.due.SetValue("")
+	for _, in := range f.inputs() {
+		in.SetValue("")
+		in.Blur()
+	}
 	f.title.Focus()
-	f.due.Blur()
 }
 func (f *addTaskForm) close() {
 	f.active = false
-	f.title.Blur()
-	f.due.Blur()
+	for _, in := range f.inputs() {
+		in.Blur()
+	}
+}
+
+// parseTaskDue reads "YYYY-MM-DD" with an optional time after it.
+func parseTaskDue(s string) (*time.Time, string, error) {
+	date, clock, _ := strings.Cut(strings.TrimSpace(s), " ")
+	due, err := parseOptionalDue(date)
+	if err != nil || due == nil {
+		return due, "", err
+	}
+	hm, err := db.ParseDueTime(clock)
+	return due, hm, err
 }
 
 func parseOptionalDue(s string) (*time.Time, error) {
@@ -116,10 +154,10 @@ func (m Model) updateLeadDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.addTask.close()
 			return m, nil
 		case "enter":
-			if m.addTask.step == 0 {
-				m.addTask.title.Blur()
-				m.addTask.step = 1
-				m.addTask.due.Focus()
+			if inputs := m.addTask.inputs(); m.addTask.step < len(inputs)-1 {
+				inputs[m.addTask.step].Blur()
+				m.addTask.step++
+				inputs[m.addTask.step].Focus()
 				return m, nil
 			}
 
@@ -129,8 +167,23 @@ func (m Model) updateLeadDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			due, err := parseOptionalDue(m.addTask.due.Value())
-			if err != nil {
+			task := db.Task{LeadID: m.dtl.LeadID, Title: title}
+			var err error
+			if task.DueDate, task.DueTime, err = parseTaskDue(m.addTask.due.Value()); err != nil {
+				m.err = err
+				return m, nil
+			}
+			repeat := strings.TrimSpace(m.addTask.repeat.Value())
+			if _, err := db.ParseRecurrence(repeat); err != nil {
+				m.err = err
+				return m, nil
+			}
+			task.Recurrence = repeat
+			if task.Type, err = db.ParseTaskType(m.addTask.ttype.Value()); err != nil {
+				m.err = err
+				return m, nil
+			}
+			if task.Priority, err = db.ParsePriority(m.addTask.priority.Value()); err != nil {
 				m.err = err
 				return m, nil
 			}
@@ -139,23 +192,39 @@ func (m Model) updateLeadDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.addTask.close()
 
 			cmd := func() tea.Msg {
-				if _, err := m.repo.CreateTask(m.ctx, leadID, title, due); err != nil {
+				if _, err := m.repo.CreateTask(m.ctx, task); err != nil {
 					return errMsg{err}
 				}
+				if repeat != "" {
+					return statusMsg("Repeating follow-up created.")
+				}
 				return statusMsg("Follow-up created.")
 			}
 			return m, tea.Batch(cmd, m.cmdLoadLeadDetail(leadID), m.cmdLoadPipeline(), m.cmdLoadTasks())
 		}
 
+		in := m.addTask.inputs()[m.addTask.step]
 		var c tea.Cmd
-		if m.addTask.step == 0 {
-			m.addTask.title, c = m.addTask.title.Update(msg)
-		} else {
-			m.addTask.due, c = m.addTask.due.Update(msg)
-		}
+		*in, c = in.Update(msg)
 		return m, c
 	}
 
+	if m.snooze.active {
+		return m.updateSnoozeForm(msg)
+	}
+	if m.fieldsForm.active {
+		return m.updateFieldsForm(msg)
+	}
+	if m.dealForm.active {
+		return m.updateDealForm(msg)
+	}
+	if m.mergeForm.active {
+		return m.updateMergeForm(msg)
+	}
+	if m.cadenceForm.active {
+		return m.updateCadenceForm(msg)
+	}
+
 	// normal mode
 	switch {
 	case key.Matches(msg, m.keys.Back):
@@ -166,10 +235,53 @@ func (m Model) updateLeadDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.addNote.open()
 		return m, nil
 
+	case key.Matches(msg, m.keys.Archive):
+		m.view = ViewPipeline
+		return m, m.archiveLeadCmd(m.dtl.Lead)
+
+	case key.Matches(msg, m.keys.AddTag):
+		m.tagForm.open(tagModeAdd, "Add tag (e.g. first-time-buyer)…", "")
+		return m, nil
+
+	case key.Matches(msg, m.keys.RemoveTag):
+		if len(m.dtl.Lead.Tags) == 0 {
+			return m, nil
+		}
+		m.tagForm.open(tagModeRemove, "Remove tag…", m.dtl.Lead.Tags[len(m.dtl.Lead.Tags)-1])
+		return m, nil
+
+	case key.Matches(msg, m.keys.Fields):
+		if len(m.dtl.Fields) == 0 {
+			m.status = "No custom fields defined yet (pipelinepal field add)."
+			return m, nil
+		}
+		m.fieldsForm.open(m.dtl.Fields)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Deal):
+		m.openDealForm()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Merge):
+		m.mergeForm.open()
+		return m, m.cmdLoadMergeCandidates(m.dtl.Lead)
+
+	case key.Matches(msg, m.keys.VCard):
+		return m, m.saveVCardCmd(m.dtl.Lead)
+
+	case key.Matches(msg, m.keys.Edit):
+		m.newLead.edit(m.dtl.Lead)
+		m.view = ViewNewLead
+		return m, nil
+
 	case key.Matches(msg, m.keys.FollowUp):
 		m.addTask.open()
 		return m, nil
 
+	case key.Matches(msg, m.keys.Cadence):
+		m.cadenceForm.open()
+		return m, m.cmdLoadCadences()
+
 	case key.Matches(msg, m.keys.Up):
 		if len(m.dtl.Tasks) > 0 {
 			m.dtl.TaskIndex = clamp(m.dtl.TaskIndex-1, 0, len(m.dtl.Tasks)-1)
@@ -187,13 +299,16 @@ func (m Model) updateLeadDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		t := m.dtl.Tasks[m.dtl.TaskIndex]
-		cmd := func() tea.Msg {
-			if err := m.repo.CompleteTask(m.ctx, t.ID); err != nil {
-				return errMsg{err}
-			}
-			return statusMsg("Task completed.")
+		return m, tea.Batch(m.completeTaskCmd(t.ID), m.cmdLoadLeadDetail(m.dtl.LeadID), m.cmdLoadTasks())
+
+	case key.Matches(msg, m.keys.Snooze):
+		if len(m.dtl.Tasks) == 0 {
+			return m, nil
 		}
-		return m, tea.Batch(cmd, m.cmdLoadLeadDetail(m.dtl.LeadID), m.cmdLoadTasks())
+		if t := m.dtl.Tasks[m.dtl.TaskIndex]; t.Status == "open" {
+			m.snooze.open(t)
+		}
+		return m, nil
 	}
 
 	return m, nil
@@ -205,32 +320,75 @@ func (m Model) viewLeadDetail() string {
 	lines := []string{
 		m.s.Header.Render("Lead Detail"),
 		"",
+	}
+	if m.mergeForm.active {
+		lines = append(lines, m.viewMergeForm()...)
+		lines = append(lines, "")
+	}
+	lines = append(lines,
 		fmt.Sprintf("%s %s", m.s.Badge.Render(strings.ToUpper(l.LeadType)), m.s.Header.Render(l.FullName)),
-		m.s.Subtle.Render(fmt.Sprintf("Stage: %s • Source: %s", l.StageName, emptyDash(l.Source))),
+		m.s.Subtle.Render(fmt.Sprintf("Stage: %s (%dd) • Status: %s • Source: %s", l.StageName, l.DaysInStage(time.Now().UTC()), emptyDash(l.Status), emptyDash(l.Source))),
 		m.s.Subtle.Render(fmt.Sprintf("Phone: %s • Email: %s", emptyDash(l.Phone), emptyDash(l.Email))),
-		m.s.Subtle.Render(fmt.Sprintf("Updated: %s", l.UpdatedAt.Format("2006-01-02 15:04"))),
+		m.s.Subtle.Render(fmt.Sprintf("Next follow-up: %s • Updated: %s", fmtOptionalDate(l.NextFollowUp), l.UpdatedAt.Format("2006-01-02 15:04"))),
+	)
+
+	tagLine := m.viewTagChips(l.Tags)
+	if tagLine == "" {
+		tagLine = m.s.Subtle.Render("(no tags)")
+	}
+	lines = append(lines, tagLine+m.s.Subtle.Render("  +/-: add/remove tag"))
+	if m.tagForm.active && m.tagForm.mode != tagModeFilter {
+		lines = append(lines, m.s.BorderFocus.Render(m.tagForm.input.View()),
+			m.s.Subtle.Render("enter: save • esc: cancel"))
+	}
+
+	if strings.TrimSpace(l.Notes) != "" {
+		lines = append(lines, "", ellipsize(l.Notes, 400))
+	}
+
+	lines = append(lines, "", m.s.Header.Render("Contact & household"))
+	lines = append(lines, m.viewContacts()...)
+
+	lines = append(lines, "", m.s.Header.Render("Custom fields"))
+	lines = append(lines, m.viewCustomFields()...)
+
+	lines = append(lines, "", m.s.Header.Render("Deals"))
+	lines = append(lines, m.viewDeals()...)
+
+	lines = append(lines, "", m.s.Header.Render("Properties"))
+	lines = append(lines, m.viewLeadProperties()...)
+
+	lines = append(lines,
 		"",
 		m.s.Header.Render("Follow-ups (tasks)"),
-		m.s.Subtle.Render("f: new follow-up • c: complete selected • j/k: select"),
+		m.s.Subtle.Render("f: new follow-up • A: apply cadence • c: complete selected • z: snooze selected • j/k: select"),
 		"",
+	)
+
+	if m.cadenceForm.active {
+		lines = append(lines, m.viewCadenceForm()...)
+		lines = append(lines, "")
 	}
 
 	if m.addTask.active {
-		lines = append(lines, m.s.BorderFocus.Render(m.addTask.title.View()))
-		lines = append(lines, m.s.BorderFocus.Render(m.addTask.due.View()))
+		for _, in := range m.addTask.inputs() {
+			lines = append(lines, m.s.BorderFocus.Render(in.View()))
+		}
 		lines = append(lines, m.s.Subtle.Render("enter: next/save • esc: cancel"))
 		lines = append(lines, "")
 	}
+	if m.snooze.active {
+		lines = append(lines, m.viewSnoozeForm(), "")
+	}
 
 	if len(m.dtl.Tasks) == 0 {
 		lines = append(lines, m.s.Subtle.Render("(no follow-ups yet)"))
 	} else {
 		for i, t := range m.dtl.Tasks {
-			due := "—"
-			if t.DueDate != nil && !t.DueDate.IsZero() {
-				due = t.DueDate.Format("2006-01-02")
+			row := fmt.Sprintf("%s  [%s]  %s", ellipsize(taskSummary(t), 52), emptyDash(fmtTaskDue(t)), strings.ToUpper(t.Status))
+			if t.Recurrence != "" && t.Status == "open" {
+				row += "  ↻ " + db.DescribeRecurrence(t.Recurrence)
 			}
-			row := fmt.Sprintf("%s  [%s]  %s", ellipsize(t.Title, 52), due, strings.ToUpper(t.Status))
 			if i == m.dtl.TaskIndex {
 				lines = append(lines, m.s.CardSel.Render(row))
 			} else {
@@ -239,6 +397,9 @@ func (m Model) viewLeadDetail() string {
 		}
 	}
 
+	lines = append(lines, "", m.s.Header.Render("Stage timeline"))
+	lines = append(lines, m.viewStageTimeline()...)
+
 	lines = append(lines, "", m.s.Header.Render("Notes"))
 
 	if m.addNote.active {
@@ -260,13 +421,115 @@ func (m Model) viewLeadDetail() string {
 		}
 	}
 
-	lines = append(lines, "", m.s.Subtle.Render("a: add note • esc: back • q: quit"))
+	lines = append(lines, "", m.s.Subtle.Render("a: add note • e: edit lead • F: custom fields • $: deal • M: merge • V: vCard • x: archive • esc: back • q: quit"))
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
+// viewStageTimeline lists each stage the lead entered and how long it stayed.
+func (m Model) viewStageTimeline() []string {
+	h := m.dtl.History
+	if len(h) == 0 {
+		return []string{m.s.Subtle.Render("(no stage changes recorded)")}
+	}
+
+	now := time.Now().UTC()
+	out := make([]string, 0, len(h))
+	for i, c := range h {
+		until := now
+		if i+1 < len(h) {
+			until = h[i+1].ChangedAt
+		}
+		days := int(until.Sub(c.ChangedAt).Hours() / 24)
+
+		move := "→ " + c.ToStage
+		if c.FromStage != "" {
+			move = c.FromStage + " → " + c.ToStage
+		}
+		row := fmt.Sprintf("%s  %s  %s",
+			m.s.Subtle.Render(c.ChangedAt.Format("2006-01-02")),
+			move,
+			m.s.Subtle.Render(fmt.Sprintf("(%dd)", days)),
+		)
+		out = append(out, row)
+	}
+	return out
+}
+
+// viewContacts lists every phone and email (primary first) and the leads
+// this one is linked to.
+func (m Model) viewContacts() []string {
+	if len(m.dtl.Contacts) == 0 && len(m.dtl.Related) == 0 {
+		return []string{m.s.Subtle.Render("(none — see `pipelinepal lead contact add` and `lead link`)")}
+	}
+	out := make([]string, 0, len(m.dtl.Contacts)+len(m.dtl.Related))
+	for _, c := range m.dtl.Contacts {
+		label := c.Kind
+		if c.Label != "" {
+			label += " (" + c.Label + ")"
+		}
+		row := fmt.Sprintf("%-18s %s", label, c.Value)
+		if c.Primary {
+			row += m.s.Subtle.Render("  primary")
+		}
+		out = append(out, row)
+	}
+	for _, r := range m.dtl.Related {
+		out = append(out, fmt.Sprintf("%s %s", m.s.Badge.Render(strings.ToUpper(r.Label())), r.RelatedName))
+	}
+	return out
+}
+
+// viewLeadProperties lists the houses linked to the lead with their role.
+func (m Model) viewLeadProperties() []string {
+	if len(m.dtl.Properties) == 0 {
+		return []string{m.s.Subtle.Render("(none linked — see `pipelinepal property link`)")}
+	}
+	out := make([]string, 0, len(m.dtl.Properties))
+	for _, pl := range m.dtl.Properties {
+		out = append(out, fmt.Sprintf("%s %s  %s",
+			m.s.Badge.Render(strings.ToUpper(pl.Role)),
+			pl.Property.Address,
+			m.s.Subtle.Render(pl.Property.Summary()),
+		))
+	}
+	return out
+}
+
+func fmtOptionalDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "—"
+	}
+	return t.Format("2006-01-02")
+}
+
 func emptyDash(s string) string {
 	if strings.TrimSpace(s) == "" {
 		return "—"
 	}
 	return s
 }
+
+// saveVCardCmd writes the lead as <data dir>/<name>-<id>.vcf, replacing
+// an earlier save of the same lead. The id keeps leads that share a name
+// from overwriting each other's file.
+func (m Model) saveVCardCmd(l db.Lead) tea.Cmd {
+	return func() tea.Msg {
+		lead, err := m.repo.GetLead(m.ctx, l.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		contacts, err := m.repo.ListLeadContacts(m.ctx, l.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		var buf bytes.Buffer
+		if err := vcard.Write(&buf, vcard.FromLead(lead, contacts), "3.0"); err != nil {
+			return errMsg{err}
+		}
+		path := filepath.Join(m.dataDir, fmt.Sprintf("%s-%d.vcf", fileName(lead.FullName, "lead"), lead.ID))
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return errMsg{err}
+		}
+		return statusMsg("Saved " + path)
+	}
+}
@@ -12,14 +12,51 @@ import (
 	"github.com/mike-keough/pipelinepal/internal/db"
 )
 
-type leadItem db.Lead
+type leadItem struct {
+	L       db.Lead
+	Kind    string // where the search matched: lead|contact|note|task
+	Snippet string // search context with db.Highlight* markers
+	match   lipgloss.Style
+}
+
+func (i leadItem) tags() string {
+	if len(i.L.Tags) == 0 {
+		return ""
+	}
+	return " • #" + strings.Join(i.L.Tags, " #")
+}
 
-func (i leadItem) Title() string { return string(i.FullName) }
+func (i leadItem) Title() string { return i.L.FullName }
 func (i leadItem) Description() string {
-	return fmt.Sprintf("%s • %s", strings.ToUpper(i.LeadType), i.StageName)
+	desc := fmt.Sprintf("%s • %s%s", strings.ToUpper(i.L.LeadType), i.L.StageName, i.tags())
+	if i.Snippet == "" {
+		return desc
+	}
+	return fmt.Sprintf("%s • %s: %s", desc, i.Kind, highlightSnippet(i.Snippet, i.match))
 }
 func (i leadItem) FilterValue() string {
-	return fmt.Sprintf("%s %s %s %s", i.FullName, i.Phone, i.Email, i.Source)
+	return fmt.Sprintf("%s %s %s %s", i.L.FullName, i.L.Phone, i.L.Email, i.L.Source)
+}
+
+// highlightSnippet renders the marked search hits in a snippet with style.
+func highlightSnippet(snippet string, style lipgloss.Style) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, db.HighlightStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], db.HighlightEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(snippet[:start])
+		b.WriteString(style.Render(snippet[start+len(db.HighlightStart) : end]))
+		snippet = snippet[end+len(db.HighlightEnd):]
+	}
+	b.WriteString(snippet)
+	return strings.Join(strings.Fields(b.String()), " ")
 }
 
 type leadsState struct {
@@ -27,12 +64,22 @@ type leadsState struct {
 	list   list.Model
 	items  []db.Lead
 	loaded bool
+
+	// smart list tabs; tab 0 is "All", tab i is smartLists[i-1]
+	smartLists []db.SmartList
+	tab        int
+	saveName   textinput.Model
 }
 
 func newLeadsState() leadsState {
 	ti := textinput.New()
-	ti.Placeholder = "Search leads (name, phone, email, source)…"
-	ti.Width = 52
+	ti.Placeholder = "Search or filter: type:buyer stage:Contacted stale:>14d has:open-task…"
+	ti.Width = 72
+
+	name := textinput.New()
+	name.Placeholder = "Name this smart list…"
+	name.Width = 40
+	name.CharLimit = 40
 
 	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	l.SetShowHelp(false)
@@ -40,10 +87,47 @@ func newLeadsState() leadsState {
 	l.SetFilteringEnabled(false)
 	l.Title = "Leads"
 
-	return leadsState{search: ti, list: l}
+	return leadsState{search: ti, list: l, saveName: name}
+}
+
+// selectTab switches to smart list tab i and loads its leads.
+func (m Model) selectTab(i int) (tea.Model, tea.Cmd) {
+	m.leads.tab = clamp(i, 0, len(m.leads.smartLists))
+	q := ""
+	if m.leads.tab > 0 {
+		q = m.leads.smartLists[m.leads.tab-1].Query
+	}
+	m.leads.search.SetValue(q)
+	return m, m.cmdLoadLeads(q)
 }
 
 func (m Model) updateLeads(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// naming a smart list
+	if m.leads.saveName.Focused() {
+		switch msg.String() {
+		case "esc":
+			m.leads.saveName.Blur()
+			return m, nil
+		case "enter":
+			name := strings.TrimSpace(m.leads.saveName.Value())
+			q := strings.TrimSpace(m.leads.search.Value())
+			m.leads.saveName.Blur()
+			if name == "" {
+				return m, nil
+			}
+			cmd := func() tea.Msg {
+				if _, err := m.repo.SaveSmartList(m.ctx, name, q); err != nil {
+					return errMsg{err}
+				}
+				return statusMsg("Saved smart list " + name + ".")
+			}
+			return m, tea.Batch(cmd, m.cmdLoadSmartLists(name))
+		}
+		var cmd tea.Cmd
+		m.leads.saveName, cmd = m.leads.saveName.Update(msg)
+		return m, cmd
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Back):
 		m.leads.search.Blur()
@@ -76,10 +160,43 @@ func (m Model) updateLeads(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	// List navigation mode
 	switch {
+	case key.Matches(msg, m.keys.PrevTab):
+		return m.selectTab(m.leads.tab - 1)
+
+	case key.Matches(msg, m.keys.NextTab):
+		return m.selectTab(m.leads.tab + 1)
+
+	case key.Matches(msg, m.keys.SaveList):
+		if strings.TrimSpace(m.leads.search.Value()) == "" {
+			m.err = errString("type a search or filter first, then s to save it")
+			return m, nil
+		}
+		m.leads.saveName.SetValue("")
+		if m.leads.tab > 0 {
+			m.leads.saveName.SetValue(m.leads.smartLists[m.leads.tab-1].Name)
+		}
+		m.leads.saveName.CursorEnd()
+		m.leads.saveName.Focus()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Purge):
+		if m.leads.tab == 0 {
+			return m, nil
+		}
+		sl := m.leads.smartLists[m.leads.tab-1]
+		cmd := func() tea.Msg {
+			if err := m.repo.DeleteSmartList(m.ctx, sl.ID); err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Deleted smart list " + sl.Name + ".")
+		}
+		m.leads.tab = 0
+		m.leads.search.SetValue("")
+		return m, tea.Batch(cmd, m.cmdLoadSmartLists(""), m.cmdLoadLeads(""))
+
 	case key.Matches(msg, m.keys.Enter):
 		if it, ok := m.leads.list.SelectedItem().(leadItem); ok {
-			ld := db.Lead(it)
-			return m, m.cmdLoadLeadDetail(ld.ID)
+			return m, m.cmdLoadLeadDetail(it.L.ID)
 		}
 		return m, nil
 	}
@@ -100,13 +217,31 @@ func (m Model) viewLeads() string {
 		box = m.s.BorderFocus.Render(m.leads.search.View())
 	}
 
-	top := lipgloss.JoinVertical(lipgloss.Left,
+	tabs := []string{m.viewLeadsTab("All", m.leads.tab == 0)}
+	for i, sl := range m.leads.smartLists {
+		tabs = append(tabs, m.viewLeadsTab(sl.Name, m.leads.tab == i+1))
+	}
+
+	parts := []string{
 		m.s.Header.Render("Leads"),
-		m.s.Subtle.Render("Type / to search • Enter applies search • Enter on a lead opens details • esc back"),
+		m.s.Subtle.Render("/ search or filter • enter applies • [ ]: smart lists • s: save as smart list • D: delete smart list • esc back"),
 		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, tabs...),
 		box,
-		"",
-	)
+	}
+	if m.leads.saveName.Focused() {
+		parts = append(parts, m.s.BorderFocus.Render(m.leads.saveName.View()),
+			m.s.Subtle.Render("enter: save • esc: cancel"))
+	}
+	parts = append(parts, "")
+	top := lipgloss.JoinVertical(lipgloss.Left, parts...)
 
 	return top + m.leads.list.View()
 }
+
+func (m Model) viewLeadsTab(name string, active bool) string {
+	if active {
+		return m.s.Badge.Render(name)
+	}
+	return m.s.Subtle.Render(" " + name + " ")
+}
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+// mergeForm folds another lead into the one open in the detail view. The
+// likely duplicates are listed; entering a lead number asks for
+// confirmation before anything is changed.
+type mergeForm struct {
+	active     bool
+	input      textinput.Model
+	candidates []db.DuplicateMatch
+	confirm    db.Lead // the lead about to be merged in; zero until looked up
+}
+
+type mergeCandidatesMsg struct{ matches []db.DuplicateMatch }
+type mergeConfirmMsg struct{ lead db.Lead }
+
+func (f *mergeForm) open() {
+	f.active = true
+	f.candidates = nil
+	f.confirm = db.Lead{}
+	f.input = textinput.New()
+	f.input.Placeholder = "lead # to merge into this one"
+	f.input.Width = 30
+	f.input.Focus()
+}
+
+func (f *mergeForm) close() {
+	f.active = false
+	f.input.Blur()
+}
+
+func (m Model) cmdLoadMergeCandidates(l db.Lead) tea.Cmd {
+	return func() tea.Msg {
+		matches, err := m.repo.FindDuplicates(m.ctx, l)
+		if err != nil {
+			return errMsg{err}
+		}
+		return mergeCandidatesMsg{matches: matches}
+	}
+}
+
+func (m Model) updateMergeForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mergeForm.close()
+		return m, nil
+	case "enter":
+		raw := strings.TrimPrefix(strings.TrimSpace(m.mergeForm.input.Value()), "#")
+		if raw == "" && len(m.mergeForm.candidates) > 0 {
+			raw = strconv.FormatInt(m.mergeForm.candidates[0].Lead.ID, 10)
+			m.mergeForm.input.SetValue(raw)
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			m.err = errString("enter the number of the lead to merge in")
+			return m, nil
+		}
+		if id == m.dtl.LeadID {
+			m.err = errString("cannot merge a lead into itself")
+			return m, nil
+		}
+
+		if m.mergeForm.confirm.ID != id {
+			return m, func() tea.Msg {
+				l, err := m.repo.GetLead(m.ctx, id)
+				if err != nil {
+					return errMsg{fmt.Errorf("lead #%d not found", id)}
+				}
+				return mergeConfirmMsg{lead: l}
+			}
+		}
+
+		keep, drop := m.dtl.LeadID, m.mergeForm.confirm
+		m.mergeForm.close()
+		cmd := func() tea.Msg {
+			if err := m.repo.MergeLeads(m.ctx, keep, drop.ID); err != nil {
+				return errMsg{err}
+			}
+			return statusMsg(fmt.Sprintf("Merged #%d %s into this lead.", drop.ID, drop.FullName))
+		}
+		// Reload only once the merge has committed; the other lead is gone.
+		return m, tea.Sequence(cmd, tea.Batch(m.cmdLoadLeadDetail(keep), m.cmdLoadPipeline(), m.cmdLoadTasks()))
+	}
+
+	var c tea.Cmd
+	m.mergeForm.input, c = m.mergeForm.input.Update(msg)
+	return m, c
+}
+
+// viewMergeForm renders the merge prompt at the top of the lead detail.
+func (m Model) viewMergeForm() []string {
+	out := []string{m.s.Header.Render("Merge another lead into " + m.dtl.Lead.FullName)}
+	if len(m.mergeForm.candidates) == 0 {
+		out = append(out, m.s.Subtle.Render("(no likely duplicates found)"))
+	}
+	for _, c := range m.mergeForm.candidates {
+		out = append(out, fmt.Sprintf("#%d %s  %s", c.Lead.ID, c.Lead.FullName,
+			m.s.Subtle.Render("same "+strings.Join(c.Reasons, ", "))))
+	}
+	out = append(out, m.s.BorderFocus.Render(m.mergeForm.input.View()))
+	if l := m.mergeForm.confirm; l.ID != 0 {
+		out = append(out, m.s.Error.Render(fmt.Sprintf(
+			"Merge #%d %s? Its notes, tasks, tags and history move here and it is deleted. enter: merge • esc: cancel",
+			l.ID, l.FullName)))
+	} else {
+		out = append(out, m.s.Subtle.Render("enter: pick (blank = first match) • esc: cancel"))
+	}
+	return out
+}
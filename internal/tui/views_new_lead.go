@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -14,6 +16,17 @@ type newLeadForm struct {
 	step    int
 	stageID int64
 
+	// editing is the lead being edited; zero ID means the form creates a new lead.
+	editing db.Lead
+
+	// dupes are the likely duplicates CreateLead reported; enter again
+	// creates the lead anyway.
+	dupes []db.DuplicateMatch
+
+	// err is shown under the input at errStep until that input changes.
+	err     string
+	errStep int
+
 	name   textinput.Model
 	phone  textinput.Model
 	email  textinput.Model
@@ -36,7 +49,7 @@ func newNewLeadForm() newLeadForm {
 		name:   mk("Full name", 40),
 		phone:  mk("Phone", 24),
 		email:  mk("Email", 40),
-		ltype:  mk("Lead type: buyer/seller/other", 30),
+		ltype:  mk("Lead type: buyer/seller/rental/other", 36),
 		source: mk("Source: Zillow, referral, sign call…", 40),
 	}
 	f.ltype.SetValue("buyer")
@@ -50,21 +63,99 @@ func (f *newLeadForm) reset() {
 	f.email.SetValue("")
 	f.ltype.SetValue("buyer")
 	f.source.SetValue("")
+	f.editing = db.Lead{}
+	f.dupes = nil
+	f.err = ""
 	f.name.Focus()
 }
 
+// edit prefills the form with l so saving updates it instead of creating.
+func (f *newLeadForm) edit(l db.Lead) {
+	f.reset()
+	f.editing = l
+	f.stageID = l.StageID
+	f.name.SetValue(l.FullName)
+	f.phone.SetValue(l.Phone)
+	f.email.SetValue(l.Email)
+	f.ltype.SetValue(l.LeadType)
+	f.source.SetValue(l.Source)
+	f.name.CursorEnd()
+}
+
+// Steps of the form, in the order enter walks through them.
+const (
+	newLeadName = iota
+	newLeadPhone
+	newLeadEmail
+	newLeadType
+	newLeadSource
+)
+
+// focusStep moves the cursor to step, e.g. to show a validation error.
+func (f *newLeadForm) focusStep(step int) {
+	f.blurAll()
+	f.step = step
+	[]*textinput.Model{&f.name, &f.phone, &f.email, &f.ltype, &f.source}[step].Focus()
+}
+
+// validateStep checks the input at step and normalizes phone and email in
+// place, so the box shows what will be saved. It sets f.err on failure.
+// When editing, a phone or email left as stored is not checked, like
+// UpdateLead, so an old unparseable value does not block other edits.
+func (f *newLeadForm) validateStep(step int, region string) bool {
+	var err error
+	switch {
+	case step == newLeadName:
+		if strings.TrimSpace(f.name.Value()) == "" {
+			err = errString("name is required")
+		}
+	case step == newLeadPhone && f.editing.ID != 0 && f.phone.Value() == f.editing.Phone,
+		step == newLeadEmail && f.editing.ID != 0 && f.email.Value() == f.editing.Email:
+		// kept as stored
+	case step == newLeadPhone:
+		var v string
+		if v, err = db.NormalizePhone(f.phone.Value(), region); err == nil {
+			f.phone.SetValue(v)
+		}
+	case step == newLeadEmail:
+		var v string
+		if v, err = db.NormalizeEmail(f.email.Value()); err == nil {
+			f.email.SetValue(v)
+		}
+	}
+	if err != nil {
+		f.err, f.errStep = err.Error(), step
+		return false
+	}
+	f.err = ""
+	return true
+}
+
+func (f *newLeadForm) blurAll() {
+	for _, ti := range []*textinput.Model{&f.name, &f.phone, &f.email, &f.ltype, &f.source} {
+		ti.Blur()
+	}
+}
+
 func (m Model) updateNewLead(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	fields := []*textinput.Model{&m.newLead.name, &m.newLead.phone, &m.newLead.email, &m.newLead.ltype, &m.newLead.source}
 
 	// escape cancels
 	if key.Matches(msg, m.keys.Back) {
-
+		m.newLead.blurAll()
+		if m.newLead.editing.ID != 0 {
+			m.view = ViewLeadDetail
+			return m, nil
+		}
 		m.view = ViewPipeline
 		return m, nil
 	}
 
 	switch msg.String() {
 	case "enter":
+		if !m.newLead.validateStep(m.newLead.step, m.phoneRegion) {
+			return m, nil
+		}
 		// next step or save
 		if m.newLead.step < len(fields)-1 {
 			fields[m.newLead.step].Blur()
@@ -74,11 +165,13 @@ func (m Model) updateNewLead(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		// save
-		fullName := strings.TrimSpace(m.newLead.name.Value())
-		if fullName == "" {
-			m.err = errString("name is required")
-			return m, nil
+		for step := newLeadName; step <= newLeadEmail; step++ {
+			if !m.newLead.validateStep(step, m.phoneRegion) {
+				m.newLead.focusStep(step)
+				return m, nil
+			}
 		}
+		fullName := strings.TrimSpace(m.newLead.name.Value())
 
 		phone := strings.TrimSpace(m.newLead.phone.Value())
 		email := strings.TrimSpace(m.newLead.email.Value())
@@ -88,20 +181,70 @@ func (m Model) updateNewLead(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		source := strings.TrimSpace(m.newLead.source.Value())
 
+		m.newLead.blurAll()
+
+		if m.newLead.editing.ID != 0 {
+			lead := m.newLead.editing
+			lead.FullName = fullName
+			lead.Phone = phone
+			lead.Email = email
+			lead.LeadType = leadType
+			lead.Source = source
+
+			cmd := func() tea.Msg {
+				if err := m.repo.UpdateLead(m.ctx, lead); err != nil {
+					return newLeadErr(err)
+				}
+				return leadUpdatedMsg{id: lead.ID}
+			}
+			return m, cmd
+		}
+
+		// The selected column wins when the board already shows the lead
+		// type's pipeline; otherwise the lead starts that pipeline's first stage.
 		stageID := m.newLead.stageID
-		if stageID == 0 && len(m.pipe.Stages) > 0 {
+		if p := m.defaultPipeline(leadType); p.ID != m.pipe.Pipeline.ID {
+			m.pipe.Pipeline = p
+			m.pipe.StageIndex, m.pipe.LeadIndex = 0, 0
+			stageID = 0
+		} else if stageID == 0 && len(m.pipe.Stages) > 0 {
 			stageID = m.pipe.Stages[0].ID
 		}
 
+		lead := db.Lead{
+			FullName: fullName,
+			Phone:    phone,
+			Email:    email,
+			LeadType: leadType,
+			Source:   source,
+			StageID:  stageID,
+		}
+
+		create := m.repo.CreateLead
+		if len(m.newLead.dupes) > 0 {
+			create = m.repo.CreateLeadAllowDuplicate
+		}
 		cmd := func() tea.Msg {
-			if _, err := m.repo.CreateLead(m.ctx, fullName, phone, email, leadType, source, stageID); err != nil {
-				return errMsg{err}
+			_, err := create(m.ctx, lead)
+			var dup *db.DuplicateError
+			if errors.As(err, &dup) {
+				return newLeadDupesMsg{matches: dup.Matches}
+			}
+			if err != nil {
+				return newLeadErr(err)
 			}
 			return statusMsg("Lead created.")
 		}
 
 		m.view = ViewPipeline
-		return m, tea.Batch(cmd, m.cmdLoadPipeline())
+		// Reload after the save: auto-applied cadences may have added tasks.
+		return m, tea.Sequence(cmd, tea.Batch(m.cmdLoadPipeline(), m.cmdLoadTasks()))
+	}
+
+	// Any edit means the duplicate check runs again on save.
+	m.newLead.dupes = nil
+	if m.newLead.errStep == m.newLead.step {
+		m.newLead.err = ""
 	}
 
 	// text input update
@@ -111,13 +254,56 @@ func (m Model) updateNewLead(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, c
 }
 
+// defaultPipeline mirrors db.Repo.DefaultPipeline over the loaded pipelines.
+func (m Model) defaultPipeline(leadType string) db.Pipeline {
+	for _, p := range m.pipe.Pipelines {
+		if p.LeadType != "" && p.LeadType == leadType {
+			return p
+		}
+	}
+	if len(m.pipe.Pipelines) > 0 {
+		return m.pipe.Pipelines[0]
+	}
+	return m.pipe.Pipeline
+}
+
+// newLeadDupesMsg reopens the new lead form with CreateLead's warning.
+type newLeadDupesMsg struct{ matches []db.DuplicateMatch }
+
+// leadUpdatedMsg reports a saved edit. The lead is only reloaded then, so
+// the reload cannot close the form over a validation error.
+type leadUpdatedMsg struct{ id int64 }
+
+// newLeadInvalidMsg reopens the form at the field the repo rejected.
+type newLeadInvalidMsg struct {
+	step int
+	err  string
+}
+
+// newLeadErr maps a save error to the form field it belongs to, if any.
+func newLeadErr(err error) tea.Msg {
+	var fe *db.FieldError
+	if errors.As(err, &fe) {
+		step := newLeadPhone
+		if fe.Field == "email" {
+			step = newLeadEmail
+		}
+		return newLeadInvalidMsg{step: step, err: fe.Err.Error()}
+	}
+	return errMsg{err}
+}
+
 type errString string
 
 func (e errString) Error() string { return string(e) }
 
 func (m Model) viewNewLead() string {
+	title := "New Lead"
+	if m.newLead.editing.ID != 0 {
+		title = "Edit Lead"
+	}
 	lines := []string{
-		m.s.Header.Render("New Lead"),
+		m.s.Header.Render(title),
 		"",
 		m.s.Subtle.Render("enter: next/save • esc: cancel"),
 		"",
@@ -142,8 +328,20 @@ func (m Model) viewNewLead() string {
 			lipgloss.NewStyle().Width(8).Render(labels[i]+":"),
 			box,
 		))
+		if m.newLead.err != "" && m.newLead.errStep == i {
+			lines = append(lines, lipgloss.NewStyle().PaddingLeft(8).Render(m.s.Error.Render(m.newLead.err)))
+		}
 		lines = append(lines, "")
 	}
 
+	if len(m.newLead.dupes) > 0 {
+		lines = append(lines, m.s.Error.Render("Looks like a lead you already have:"))
+		for _, d := range m.newLead.dupes {
+			lines = append(lines, fmt.Sprintf("  #%d %s  %s", d.Lead.ID, d.Lead.FullName,
+				m.s.Subtle.Render("same "+strings.Join(d.Reasons, ", "))))
+		}
+		lines = append(lines, m.s.Subtle.Render("enter: create anyway • esc: cancel (merge later with M in lead detail)"))
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,6 +20,21 @@ func (m Model) updatePipeline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case key.Matches(msg, m.keys.NextPipeline):
+		if len(m.pipe.Pipelines) < 2 {
+			return m, nil
+		}
+		next := 0
+		for i, p := range m.pipe.Pipelines {
+			if p.ID == m.pipe.Pipeline.ID {
+				next = (i + 1) % len(m.pipe.Pipelines)
+				break
+			}
+		}
+		m.pipe.Pipeline = m.pipe.Pipelines[next]
+		m.pipe.StageIndex, m.pipe.LeadIndex = 0, 0
+		return m, m.cmdLoadPipeline()
+
 	case key.Matches(msg, m.keys.Left):
 		m.pipe.StageIndex = clamp(m.pipe.StageIndex-1, 0, len(m.pipe.Stages)-1)
 		m.pipe.LeadIndex = clamp(m.pipe.LeadIndex, 0, m.maxLeadIndexInStage())
@@ -44,6 +60,20 @@ func (m Model) updatePipeline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, m.cmdLoadLeadDetail(lead.ID)
 
+	case key.Matches(msg, m.keys.Archive):
+		lead, ok := m.selectedLead()
+		if !ok {
+			return m, nil
+		}
+		return m, m.archiveLeadCmd(lead)
+
+	case key.Matches(msg, m.keys.VCard):
+		lead, ok := m.selectedLead()
+		if !ok {
+			return m, nil
+		}
+		return m, m.saveVCardCmd(lead)
+
 	case key.Matches(msg, m.keys.MoveL):
 		return m.moveSelectedLead(-1)
 
@@ -61,11 +91,12 @@ func (m Model) viewPipeline() string {
 
 	cols := make([]string, 0, len(m.pipe.Stages))
 	colW := m.columnWidth()
+	now := time.Now().UTC()
 
 	for i, st := range m.pipe.Stages {
 		leads := m.pipe.ByStage[st.ID]
 
-		title := m.s.ColTitle.Render(st.Name) + m.s.Badge.Render(fmt.Sprintf("%d", len(leads)))
+		title := m.stageTitleStyle(st).Render(st.Name) + m.s.Badge.Render(fmt.Sprintf("%d", len(leads)))
 
 		var cards []string
 		for j, ld := range leads {
@@ -74,6 +105,10 @@ func (m Model) viewPipeline() string {
 				ld.LeadType,
 				ellipsize(ld.Source, 12),
 			)
+			line += "\n" + m.fmtDaysInStage(ld.DaysInStage(now))
+			if chips := m.viewTagChips(ld.Tags); chips != "" {
+				line += "\n" + chips
+			}
 
 			cardStyle := m.s.Card
 			if i == m.pipe.StageIndex && j == m.pipe.LeadIndex {
@@ -97,9 +132,40 @@ func (m Model) viewPipeline() string {
 		cols = append(cols, col)
 	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, cols...)
+	return m.viewPipelineTabs() + "\n\n" + lipgloss.JoinHorizontal(lipgloss.Top, cols...)
+}
+
+// viewPipelineTabs lists the pipelines with the current one highlighted.
+func (m Model) viewPipelineTabs() string {
+	tabs := make([]string, 0, len(m.pipe.Pipelines)+1)
+	for _, p := range m.pipe.Pipelines {
+		if p.ID == m.pipe.Pipeline.ID {
+			tabs = append(tabs, m.s.Badge.Render(p.Name))
+		} else {
+			tabs = append(tabs, m.s.Subtle.Render(" "+p.Name+" "))
+		}
+	}
+	tabs = append(tabs, m.s.Subtle.Render("  p: next pipeline"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
+
+// staleDays is when time-in-stage starts being flagged on cards.
+const staleDays = 14
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, cols...)
+func (m Model) fmtDaysInStage(days int) string {
+	s := fmt.Sprintf("%dd in stage", days)
+	if days >= staleDays {
+		return m.s.Error.Render(s)
+	}
+	return m.s.Subtle.Render(s)
+}
+
+// stageTitleStyle renders a column header in the stage's own color when set.
+func (m Model) stageTitleStyle(st db.Stage) lipgloss.Style {
+	if st.Color == "" {
+		return m.s.ColTitle
+	}
+	return m.s.ColTitle.Foreground(lipgloss.Color(st.Color))
 }
 
 func (m Model) maxLeadIndexInStage() int {
@@ -138,7 +204,8 @@ func (m Model) moveSelectedLead(dir int) (tea.Model, tea.Cmd) {
 	if newStageIdx < 0 || newStageIdx >= len(m.pipe.Stages) {
 		return m, nil
 	}
-	newStageID := m.pipe.Stages[newStageIdx].ID
+	newStage := m.pipe.Stages[newStageIdx]
+	newStageID := newStage.ID
 
 	m.pending = pendingSelection{
 		leadID:  lead.ID,
@@ -150,6 +217,15 @@ func (m Model) moveSelectedLead(dir int) (tea.Model, tea.Cmd) {
 		if err := m.repo.MoveLeadStage(m.ctx, lead.ID, newStageID); err != nil {
 			return errMsg{err}
 		}
+		if newStage.DealPrompt {
+			open, err := m.repo.HasOpenDeal(m.ctx, lead.ID)
+			if err != nil {
+				return errMsg{err}
+			}
+			if !open {
+				return dealPromptMsg{lead: lead, stage: newStage.Name}
+			}
+		}
 		return statusMsg("Moved lead.")
 	}
 	return m, tea.Batch(cmd, m.cmdLoadPipeline())
@@ -0,0 +1,307 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+type stageEditMode int
+
+const (
+	stageModeList stageEditMode = iota
+	stageModeAdd
+	stageModeRename
+	stageModeColor
+	stageModeWinProb
+	stageModeDelete // picking a destination for the deleted stage's leads
+)
+
+type stagesState struct {
+	index int
+	mode  stageEditMode
+	input textinput.Model
+
+	// delete flow
+	deleting  db.Stage
+	leadCount int
+	destIndex int
+}
+
+func newStagesState() stagesState {
+	ti := textinput.New()
+	ti.Width = 40
+	ti.CharLimit = 60
+	return stagesState{input: ti}
+}
+
+func (s *stagesState) openInput(mode stageEditMode, placeholder, value string) {
+	s.mode = mode
+	s.input.Placeholder = placeholder
+	s.input.SetValue(value)
+	s.input.CursorEnd()
+	s.input.Focus()
+}
+
+func (s *stagesState) closeInput() {
+	s.mode = stageModeList
+	s.input.Blur()
+}
+
+// stageDeleteCheckMsg carries how many leads block deleting a stage.
+type stageDeleteCheckMsg struct {
+	stage db.Stage
+	count int
+}
+
+func (m Model) selectedStage() (db.Stage, bool) {
+	if len(m.pipe.Stages) == 0 {
+		return db.Stage{}, false
+	}
+	return m.pipe.Stages[clamp(m.stages.index, 0, len(m.pipe.Stages)-1)], true
+}
+
+func (m Model) updateStages(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	st, hasSel := m.selectedStage()
+
+	switch m.stages.mode {
+	case stageModeAdd, stageModeRename, stageModeColor, stageModeWinProb:
+		switch msg.String() {
+		case "esc":
+			m.stages.closeInput()
+			return m, nil
+		case "enter":
+			val := strings.TrimSpace(m.stages.input.Value())
+			mode := m.stages.mode
+			m.stages.closeInput()
+			if mode != stageModeColor && val == "" {
+				return m, nil
+			}
+			pct := 0
+			if mode == stageModeWinProb {
+				n, err := strconv.Atoi(strings.TrimSuffix(val, "%"))
+				if err != nil {
+					m.err = fmt.Errorf("win probability: %q is not a whole percent", val)
+					return m, nil
+				}
+				pct = n
+			}
+
+			cmd := func() tea.Msg {
+				var err error
+				switch mode {
+				case stageModeAdd:
+					_, err = m.repo.CreateStage(m.ctx, m.pipe.Pipeline.ID, val, "")
+				case stageModeRename:
+					err = m.repo.RenameStage(m.ctx, st.ID, val)
+				case stageModeColor:
+					err = m.repo.SetStageColor(m.ctx, st.ID, val)
+				case stageModeWinProb:
+					err = m.repo.SetStageWinProb(m.ctx, st.ID, pct)
+				}
+				if err != nil {
+					return errMsg{err}
+				}
+				return statusMsg("Stage saved.")
+			}
+			if mode == stageModeAdd {
+				m.stages.index = len(m.pipe.Stages)
+			}
+			return m, tea.Batch(cmd, m.cmdLoadPipeline())
+		}
+
+		var c tea.Cmd
+		m.stages.input, c = m.stages.input.Update(msg)
+		return m, c
+
+	case stageModeDelete:
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.stages.mode = stageModeList
+			return m, nil
+		case key.Matches(msg, m.keys.Up):
+			m.stages.destIndex = m.nextDestIndex(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.Down):
+			m.stages.destIndex = m.nextDestIndex(+1)
+			return m, nil
+		case key.Matches(msg, m.keys.Enter):
+			dest := m.pipe.Stages[m.stages.destIndex]
+			del := m.stages.deleting
+			m.stages.mode = stageModeList
+			return m, tea.Batch(m.deleteStageCmd(del, dest.ID), m.cmdLoadPipeline())
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.view = ViewPipeline
+		return m, m.cmdLoadPipeline()
+
+	case key.Matches(msg, m.keys.Up):
+		m.stages.index = clamp(m.stages.index-1, 0, len(m.pipe.Stages)-1)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		m.stages.index = clamp(m.stages.index+1, 0, len(m.pipe.Stages)-1)
+		return m, nil
+
+	case key.Matches(msg, m.keys.NewLead):
+		m.stages.openInput(stageModeAdd, "New stage name (e.g. Pre-Approved)…", "")
+		return m, nil
+
+	case key.Matches(msg, m.keys.Edit):
+		if hasSel {
+			m.stages.openInput(stageModeRename, "Stage name", st.Name)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Color):
+		if hasSel {
+			m.stages.openInput(stageModeColor, "Color: #RRGGBB or 0-255 (empty = default)", st.Color)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.WinProb):
+		if hasSel {
+			m.stages.openInput(stageModeWinProb, "Win probability 0-100%", strconv.Itoa(st.WinProb))
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Deal):
+		if !hasSel {
+			return m, nil
+		}
+		on := !st.DealPrompt
+		cmd := func() tea.Msg {
+			if err := m.repo.SetStageDealPrompt(m.ctx, st.ID, on); err != nil {
+				return errMsg{err}
+			}
+			if on {
+				return statusMsg("Moving a lead into " + st.Name + " will offer to create a deal.")
+			}
+			return statusMsg("Deal prompt off for " + st.Name + ".")
+		}
+		return m, tea.Batch(cmd, m.cmdLoadPipeline())
+
+	case key.Matches(msg, m.keys.MoveUp), key.Matches(msg, m.keys.MoveDown):
+		if !hasSel {
+			return m, nil
+		}
+		dir := -1
+		if key.Matches(msg, m.keys.MoveDown) {
+			dir = +1
+		}
+		pos := clamp(m.stages.index+dir, 0, len(m.pipe.Stages)-1)
+		m.stages.index = pos
+		cmd := func() tea.Msg {
+			if err := m.repo.MoveStage(m.ctx, st.ID, pos); err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Stage moved.")
+		}
+		return m, tea.Batch(cmd, m.cmdLoadPipeline())
+
+	case key.Matches(msg, m.keys.Purge):
+		if !hasSel {
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			n, err := m.repo.CountStageLeads(m.ctx, st.ID)
+			if err != nil {
+				return errMsg{err}
+			}
+			return stageDeleteCheckMsg{stage: st, count: n}
+		}
+	}
+
+	return m, nil
+}
+
+// handleStageDeleteCheck deletes an empty stage right away, or asks where
+// its leads should go.
+func (m Model) handleStageDeleteCheck(msg stageDeleteCheckMsg) (tea.Model, tea.Cmd) {
+	if len(m.pipe.Stages) < 2 {
+		m.err = errString("cannot delete the pipeline's only stage")
+		return m, nil
+	}
+	if msg.count == 0 {
+		return m, tea.Batch(m.deleteStageCmd(msg.stage, 0), m.cmdLoadPipeline())
+	}
+	m.stages.mode = stageModeDelete
+	m.stages.deleting = msg.stage
+	m.stages.leadCount = msg.count
+	m.stages.destIndex = -1
+	m.stages.destIndex = m.nextDestIndex(+1)
+	return m, nil
+}
+
+// nextDestIndex steps the destination cursor, skipping the stage being deleted.
+func (m Model) nextDestIndex(dir int) int {
+	n := len(m.pipe.Stages)
+	i := m.stages.destIndex
+	for step := 0; step < n; step++ {
+		i = clamp(i+dir, 0, n-1)
+		if m.pipe.Stages[i].ID != m.stages.deleting.ID {
+			return i
+		}
+	}
+	return m.stages.destIndex
+}
+
+func (m Model) deleteStageCmd(st db.Stage, moveTo int64) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.repo.DeleteStage(m.ctx, st.ID, moveTo); err != nil {
+			return errMsg{err}
+		}
+		return statusMsg(fmt.Sprintf("Deleted stage %s.", st.Name))
+	}
+}
+
+func (m Model) viewStages() string {
+	lines := []string{
+		m.s.Header.Render("Stages — " + m.pipe.Pipeline.Name + " pipeline"),
+		m.s.Subtle.Render("n: add • e: rename • C: color • %: win probability • $: deal prompt • K/J: move up/down • D: delete • esc: back"),
+		"",
+	}
+
+	for i, st := range m.pipe.Stages {
+		count := len(m.pipe.ByStage[st.ID])
+		row := fmt.Sprintf("%s %s", m.stageTitleStyle(st).UnsetMarginBottom().Render(st.Name),
+			m.s.Subtle.Render(fmt.Sprintf("(%d) %d%% %s", count, st.WinProb, st.Color)))
+		if st.DealPrompt {
+			row += m.s.Subtle.Render(" $")
+		}
+
+		style := m.s.Card
+		switch {
+		case m.stages.mode == stageModeDelete && i == m.stages.destIndex:
+			style = m.s.CardSel
+		case m.stages.mode != stageModeDelete && i == m.stages.index:
+			style = m.s.CardSel
+		}
+		lines = append(lines, style.Width(48).Render(row))
+	}
+	if len(m.pipe.Stages) == 0 {
+		lines = append(lines, m.s.Subtle.Render("(no stages)"))
+	}
+
+	switch m.stages.mode {
+	case stageModeAdd, stageModeRename, stageModeColor, stageModeWinProb:
+		lines = append(lines, "", m.s.BorderFocus.Render(m.stages.input.View()),
+			m.s.Subtle.Render("enter: save • esc: cancel"))
+	case stageModeDelete:
+		lines = append(lines, "", m.s.Error.Render(fmt.Sprintf(
+			"%s still has %d lead(s). Pick a stage to move them into (j/k, enter) or esc to cancel.",
+			m.stages.deleting.Name, m.stages.leadCount)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tagForm is the one-line prompt used both for the global tag filter and
+// for adding/removing a tag on the open lead.
+type tagForm struct {
+	active bool
+	mode   tagFormMode
+	input  textinput.Model
+}
+
+type tagFormMode int
+
+const (
+	tagModeFilter tagFormMode = iota
+	tagModeAdd
+	tagModeRemove
+)
+
+func newTagForm() tagForm {
+	ti := textinput.New()
+	ti.Width = 40
+	ti.CharLimit = 40
+	return tagForm{input: ti}
+}
+
+func (f *tagForm) open(mode tagFormMode, placeholder, value string) {
+	f.active = true
+	f.mode = mode
+	f.input.Placeholder = placeholder
+	f.input.SetValue(value)
+	f.input.CursorEnd()
+	f.input.Focus()
+}
+
+func (f *tagForm) close() {
+	f.active = false
+	f.input.Blur()
+}
+
+// updateTagForm handles keys while the tag prompt is open.
+func (m Model) updateTagForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.tagForm.close()
+		return m, nil
+	case "enter":
+		val := strings.TrimPrefix(strings.TrimSpace(m.tagForm.input.Value()), "#")
+		mode := m.tagForm.mode
+		m.tagForm.close()
+
+		if mode == tagModeFilter {
+			m.tagFilter = val
+			m.pipe.LeadIndex = 0
+			return m, tea.Batch(m.cmdLoadPipeline(), m.cmdLoadLeads(m.leads.search.Value()), m.cmdLoadTasks())
+		}
+		if val == "" {
+			return m, nil
+		}
+
+		leadID := m.dtl.LeadID
+		cmd := func() tea.Msg {
+			var err error
+			if mode == tagModeAdd {
+				err = m.repo.TagLead(m.ctx, leadID, val)
+			} else {
+				err = m.repo.UntagLead(m.ctx, leadID, val)
+			}
+			if err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Tags updated.")
+		}
+		return m, tea.Batch(cmd, m.cmdLoadLeadDetail(leadID), m.cmdLoadPipeline())
+	}
+
+	var c tea.Cmd
+	m.tagForm.input, c = m.tagForm.input.Update(msg)
+	return m, c
+}
+
+// viewTagChips renders tags as small chips, or "" when there are none.
+func (m Model) viewTagChips(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	chips := make([]string, 0, len(tags))
+	for _, t := range tags {
+		chips = append(chips, m.s.Chip.Render("#"+t))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, chips...)
+}
+
+// viewTagFilterBar shows the active filter and, while open, the prompt.
+func (m Model) viewTagFilterBar() string {
+	if m.tagForm.active && m.tagForm.mode == tagModeFilter {
+		return m.s.BorderFocus.Render(m.tagForm.input.View()) + "\n" +
+			m.s.Subtle.Render("enter: filter by tag (empty clears) • esc: cancel") + "\n"
+	}
+	if m.tagFilter == "" {
+		return ""
+	}
+	return m.s.Subtle.Render("Filtered to ") + m.s.Chip.Render("#"+m.tagFilter) + m.s.Subtle.Render("  #: change") + "\n\n"
+}
+
+// hasTag reports whether tags contains name, ignoring case.
+func hasTag(tags []string, name string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,9 +2,11 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mike-keough/pipelinepal/internal/db"
@@ -16,11 +18,14 @@ type taskItem struct {
 
 func (i taskItem) Title() string {
 	t := i.T
-	due := "No due date"
-	if t.DueDate != nil && !t.DueDate.IsZero() {
-		due = t.DueDate.Format("2006-01-02")
+	due := fmtTaskDue(t)
+	if due == "" {
+		due = "No due date"
 	}
-	return fmt.Sprintf("%s • %s", t.Title, due)
+	if t.Recurrence != "" {
+		due += " • ↻ " + db.DescribeRecurrence(t.Recurrence)
+	}
+	return fmt.Sprintf("%s • %s", taskSummary(t), due)
 }
 
 func (i taskItem) Description() string {
@@ -30,6 +35,34 @@ func (i taskItem) Description() string {
 
 func (i taskItem) FilterValue() string { return "" }
 
+// taskSummary is the title with the task's type and a mark for high (‼)
+// or low (↓) priority: "‼ Call: Send listings".
+func taskSummary(t db.Task) string {
+	s := t.Title
+	if t.Type != "" {
+		s = strings.ToUpper(t.Type[:1]) + t.Type[1:] + ": " + s
+	}
+	switch t.Priority {
+	case db.PriorityHigh:
+		s = "‼ " + s
+	case db.PriorityLow:
+		s = "↓ " + s
+	}
+	return s
+}
+
+// fmtTaskDue is "2006-01-02", "2006-01-02 15:04" for timed tasks, or ""
+// with no due date.
+func fmtTaskDue(t db.Task) string {
+	if t.DueDate == nil || t.DueDate.IsZero() {
+		return ""
+	}
+	if t.DueTime != "" {
+		return t.DueDate.Format("2006-01-02") + " " + t.DueTime
+	}
+	return t.DueDate.Format("2006-01-02")
+}
+
 type tasksState struct {
 	list   list.Model
 	items  []db.Task
@@ -46,6 +79,10 @@ func newTasksState() tasksState {
 }
 
 func (m Model) updateTasks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.snooze.active {
+		return m.updateSnoozeForm(msg)
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Back):
 		m.view = ViewPipeline
@@ -59,13 +96,13 @@ func (m Model) updateTasks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Complete):
 		if it, ok := m.tasks.list.SelectedItem().(taskItem); ok {
-			cmd := func() tea.Msg {
-				if err := m.repo.CompleteTask(m.ctx, it.T.ID); err != nil {
-					return errMsg{err}
-				}
-				return statusMsg("Task completed.")
-			}
-			return m, tea.Batch(cmd, m.cmdLoadTasks())
+			return m, tea.Batch(m.completeTaskCmd(it.T.ID), m.cmdLoadTasks())
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Snooze):
+		if it, ok := m.tasks.list.SelectedItem().(taskItem); ok {
+			m.snooze.open(it.T)
 		}
 		return m, nil
 	}
@@ -82,9 +119,95 @@ func (m Model) viewTasks() string {
 
 	header := lipgloss.JoinVertical(lipgloss.Left,
 		m.s.Header.Render("Open Tasks"),
-		m.s.Subtle.Render("enter: open lead • c: complete • esc: back"),
+		m.s.Subtle.Render("enter: open lead • c: complete • z: snooze • esc: back"),
 		"",
 	)
+	if m.snooze.active {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, m.viewSnoozeForm(), "")
+	}
 
 	return header + m.tasks.list.View()
 }
+
+// completeTaskCmd completes a task; a repeating one says when it is next
+// due. Callers reload the lists that show it.
+func (m Model) completeTaskCmd(id int64) tea.Cmd {
+	return func() tea.Msg {
+		next, err := m.repo.CompleteTask(m.ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		if next != nil {
+			return statusMsg("Task completed; next one due " + next.Format("2006-01-02") + ".")
+		}
+		return statusMsg("Task completed.")
+	}
+}
+
+// snoozeForm asks how far to push a task back.
+type snoozeForm struct {
+	active bool
+	task   db.Task
+	input  textinput.Model
+}
+
+func newSnoozeForm() snoozeForm {
+	ti := textinput.New()
+	ti.Placeholder = "Snooze for (2h, 1d, 3d, 1w)…"
+	ti.CharLimit = 20
+	ti.Width = 30
+	return snoozeForm{input: ti}
+}
+
+func (f *snoozeForm) open(t db.Task) {
+	f.active = true
+	f.task = t
+	f.input.SetValue("1d")
+	f.input.CursorEnd()
+	f.input.Focus()
+}
+
+func (f *snoozeForm) close() {
+	f.active = false
+	f.input.Blur()
+}
+
+func (m Model) updateSnoozeForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.snooze.close()
+		return m, nil
+	case "enter":
+		d, err := db.ParseSnooze(m.snooze.input.Value())
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		t := m.snooze.task
+		m.snooze.close()
+		cmd := func() tea.Msg {
+			t, err := m.repo.SnoozeTask(m.ctx, t.ID, d)
+			if err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Snoozed until " + fmtTaskDue(t) + ".")
+		}
+		reload := []tea.Cmd{m.cmdLoadTasks()}
+		if m.view == ViewLeadDetail {
+			reload = append(reload, m.cmdLoadLeadDetail(t.LeadID))
+		}
+		return m, tea.Sequence(cmd, tea.Batch(reload...))
+	}
+
+	var c tea.Cmd
+	m.snooze.input, c = m.snooze.input.Update(msg)
+	return m, c
+}
+
+func (m Model) viewSnoozeForm() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.s.Subtle.Render("Snooze "+taskSummary(m.snooze.task)+" ("+emptyDash(fmtTaskDue(m.snooze.task))+")"),
+		m.s.BorderFocus.Render(m.snooze.input.View()),
+		m.s.Subtle.Render("enter: snooze • esc: cancel"),
+	)
+}
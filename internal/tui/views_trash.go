@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+type trashItem struct {
+	L db.Lead
+}
+
+func (i trashItem) Title() string { return i.L.FullName }
+func (i trashItem) Description() string {
+	archived := "—"
+	if i.L.ArchivedAt != nil {
+		archived = i.L.ArchivedAt.Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("%s • %s • archived %s", i.L.LeadType, i.L.StageName, archived)
+}
+func (i trashItem) FilterValue() string { return "" }
+
+type trashState struct {
+	list   list.Model
+	items  []db.Lead
+	loaded bool
+
+	// confirmPurge is the lead waiting on a second D press.
+	confirmPurge int64
+}
+
+func newTrashState() trashState {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.Title = "Trash"
+	return trashState{list: l}
+}
+
+func (m Model) updateTrash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	it, hasSel := m.trash.list.SelectedItem().(trashItem)
+
+	if !key.Matches(msg, m.keys.Purge) {
+		m.trash.confirmPurge = 0
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.view = ViewPipeline
+		return m, m.cmdLoadPipeline()
+
+	case key.Matches(msg, m.keys.Restore):
+		if !hasSel {
+			return m, nil
+		}
+		cmd := func() tea.Msg {
+			if err := m.repo.RestoreLead(m.ctx, it.L.ID); err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Lead restored.")
+		}
+		return m, tea.Batch(cmd, m.cmdLoadTrash(), m.cmdLoadPipeline(), m.cmdLoadTasks())
+
+	case key.Matches(msg, m.keys.Purge):
+		if !hasSel {
+			return m, nil
+		}
+		if m.trash.confirmPurge != it.L.ID {
+			m.trash.confirmPurge = it.L.ID
+			m.status = fmt.Sprintf("Press D again to permanently delete %s (notes and tasks too).", it.L.FullName)
+			return m, nil
+		}
+		m.trash.confirmPurge = 0
+		cmd := func() tea.Msg {
+			if err := m.repo.PurgeLead(m.ctx, it.L.ID); err != nil {
+				return errMsg{err}
+			}
+			return statusMsg("Lead permanently deleted.")
+		}
+		return m, tea.Batch(cmd, m.cmdLoadTrash())
+	}
+
+	var cmd tea.Cmd
+	m.trash.list, cmd = m.trash.list.Update(msg)
+	return m, cmd
+}
+
+func (m Model) viewTrash() string {
+	if !m.trash.loaded {
+		return "Loading trash…"
+	}
+
+	header := lipgloss.JoinVertical(lipgloss.Left,
+		m.s.Header.Render("Trash"),
+		m.s.Subtle.Render("r: restore • D: delete forever • esc: back"),
+		"",
+	)
+
+	if len(m.trash.items) == 0 {
+		return header + m.s.Subtle.Render("(trash is empty)")
+	}
+	return header + m.trash.list.View()
+}
+
+// archiveLeadCmd trashes a lead and refreshes everything it was visible in.
+func (m Model) archiveLeadCmd(l db.Lead) tea.Cmd {
+	cmd := func() tea.Msg {
+		if err := m.repo.ArchiveLead(m.ctx, l.ID); err != nil {
+			return errMsg{err}
+		}
+		return statusMsg(fmt.Sprintf("Archived %s (T: trash).", l.FullName))
+	}
+	return tea.Batch(cmd, m.cmdLoadPipeline(), m.cmdLoadLeads(m.leads.search.Value()), m.cmdLoadTasks(), m.cmdLoadTrash())
+}
@@ -0,0 +1,89 @@
+package vcard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mike-keough/pipelinepal/internal/db"
+)
+
+// FromLead builds the card for a lead and its phones and emails. Tags
+// become CATEGORIES, the background text the NOTE, and lead type, source
+// and status travel as X-PIPELINEPAL-* properties so an export imports back
+// unchanged. The stage is left out: it means nothing to an address book.
+func FromLead(l db.Lead, contacts []db.ContactMethod) Card {
+	c := Card{
+		UID:        fmt.Sprintf("pipelinepal-lead-%d", l.ID),
+		Name:       l.FullName,
+		Note:       l.Notes,
+		Categories: l.Tags,
+		Rev:        l.UpdatedAt,
+		Ext: map[string]string{
+			"type":   l.LeadType,
+			"source": l.Source,
+			"status": l.Status,
+		},
+	}
+	c.Given, c.Family = splitName(l.FullName)
+
+	for _, cm := range contacts {
+		e := Entry{Value: cm.Value, Label: cm.Label, Pref: cm.Primary}
+		if cm.Kind == "email" {
+			c.Emails = append(c.Emails, e)
+		} else {
+			c.Phones = append(c.Phones, e)
+		}
+	}
+	// Leads from before contact methods existed only have the primary pair.
+	if len(c.Phones) == 0 && l.Phone != "" {
+		c.Phones = []Entry{{Value: l.Phone, Pref: true}}
+	}
+	if len(c.Emails) == 0 && l.Email != "" {
+		c.Emails = []Entry{{Value: l.Email, Pref: true}}
+	}
+	return c
+}
+
+// splitName guesses given and family names: the family name is the last
+// word.
+func splitName(full string) (given, family string) {
+	words := strings.Fields(full)
+	if len(words) < 2 {
+		return full, ""
+	}
+	return strings.Join(words[:len(words)-1], " "), words[len(words)-1]
+}
+
+// ImportRecord maps the card onto import targets; n numbers the card in its
+// file for the import plan. The preferred phone and email come first so
+// they become the lead's primary ones.
+func (c Card) ImportRecord(n int) db.ImportRecord {
+	rec := db.ImportRecord{Line: n, Values: map[string]string{}}
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		name = strings.TrimSpace(c.Given + " " + c.Family)
+	}
+	rec.Values["name"] = name
+	rec.Values["notes"] = c.Note
+	rec.Values["tags"] = strings.Join(c.Categories, ",")
+	for _, k := range []string{"type", "source", "status"} {
+		if v := c.Ext[k]; v != "" {
+			rec.Values[k] = v
+		}
+	}
+
+	add := func(kind string, entries []Entry) {
+		sorted := append([]Entry(nil), entries...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Pref && !sorted[j].Pref })
+		for _, e := range sorted {
+			if e.Value != "" {
+				rec.Contacts = append(rec.Contacts, db.ContactMethod{Kind: kind, Label: e.Label, Value: e.Value})
+			}
+		}
+	}
+	add("phone", c.Phones)
+	add("email", c.Emails)
+	return rec
+}
@@ -0,0 +1,365 @@
+// Package vcard reads and writes vCard 3.0 and 4.0 contacts (RFC 2426,
+// RFC 6350) and maps them to and from leads.
+package vcard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mike-keough/pipelinepal/internal/contentline"
+)
+
+// Card is the part of a vCard PipelinePal understands.
+type Card struct {
+	UID        string
+	Name       string // FN
+	Given      string // N, given name
+	Family     string // N, family name
+	Phones     []Entry
+	Emails     []Entry
+	Note       string
+	Categories []string
+	Rev        time.Time
+	Ext        map[string]string // X-PIPELINEPAL-* values keyed by the lowercased suffix
+}
+
+// Entry is one phone number or email address.
+type Entry struct {
+	Value string
+	Label string // cell, work, home… or a custom label
+	Pref  bool
+}
+
+// extPrefix marks the properties PipelinePal writes for its own fields.
+const extPrefix = "X-PIPELINEPAL-"
+
+// Parse reads every card in r. Lines outside BEGIN:VCARD / END:VCARD and
+// properties other than the ones in Card are ignored.
+func Parse(r io.Reader) ([]Card, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []Card
+	var c *Card
+	type ref struct {
+		phone bool
+		i     int
+	}
+	groups := map[string]ref{}    // item1 -> the TEL/EMAIL in that group
+	labels := map[string]string{} // item1 -> its X-ABLABEL
+	finish := func() {
+		for g, lbl := range labels {
+			if rf, ok := groups[g]; ok {
+				if rf.phone {
+					c.Phones[rf.i].Label = lbl
+				} else {
+					c.Emails[rf.i].Label = lbl
+				}
+			}
+		}
+		cards = append(cards, *c)
+		c = nil
+	}
+
+	for n, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		group, name, params, value, ok := splitLine(line)
+		if !ok {
+			return nil, fmt.Errorf("vcard line %d: %q is not a property", n+1, line)
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCARD"):
+			c = &Card{Ext: map[string]string{}}
+			groups, labels = map[string]ref{}, map[string]string{}
+			continue
+		case c == nil:
+			continue
+		case name == "END" && strings.EqualFold(value, "VCARD"):
+			finish()
+			continue
+		}
+
+		switch name {
+		case "UID":
+			c.UID = value
+		case "FN":
+			c.Name = unescape(value)
+		case "N":
+			parts := splitEscaped(value, ';')
+			if len(parts) > 0 {
+				c.Family = parts[0]
+			}
+			if len(parts) > 1 {
+				c.Given = parts[1]
+			}
+		case "TEL":
+			c.Phones = append(c.Phones, entry(strings.TrimPrefix(unescape(value), "tel:"), params))
+			if group != "" {
+				groups[group] = ref{phone: true, i: len(c.Phones) - 1}
+			}
+		case "EMAIL":
+			c.Emails = append(c.Emails, entry(strings.TrimPrefix(unescape(value), "mailto:"), params))
+			if group != "" {
+				groups[group] = ref{i: len(c.Emails) - 1}
+			}
+		case "X-ABLABEL":
+			labels[group] = appleLabel(unescape(value))
+		case "NOTE":
+			c.Note = unescape(value)
+		case "CATEGORIES":
+			for _, cat := range splitEscaped(value, ',') {
+				if cat = strings.TrimSpace(cat); cat != "" {
+					c.Categories = append(c.Categories, cat)
+				}
+			}
+		case "REV":
+			c.Rev = parseRev(value)
+		default:
+			if ext, ok := strings.CutPrefix(name, extPrefix); ok {
+				c.Ext[strings.ToLower(ext)] = unescape(value)
+			}
+		}
+	}
+	if c != nil {
+		return nil, fmt.Errorf("vcard: card %d has no END:VCARD", len(cards)+1)
+	}
+	return cards, nil
+}
+
+// unfold reads r into logical lines: a line starting with a space or tab
+// continues the one before it.
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024) // PHOTO lines can be large
+	var out []string
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(out) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(out) > 0 {
+			out[len(out)-1] += line[1:]
+			continue
+		}
+		out = append(out, line)
+	}
+	return out, sc.Err()
+}
+
+// splitLine splits "item1.TEL;TYPE=cell:+1555…" into its group, upper-case
+// name, parameters and raw value.
+func splitLine(line string) (group, name string, params map[string][]string, value string, ok bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", "", nil, "", false
+	}
+	head, value := line[:colon], line[colon+1:]
+
+	parts := strings.Split(head, ";")
+	name = strings.ToUpper(parts[0])
+	if g, n, found := strings.Cut(name, "."); found {
+		group, name = strings.ToLower(g), n
+	}
+	params = map[string][]string{}
+	for _, p := range parts[1:] {
+		k, v, found := strings.Cut(p, "=")
+		if !found {
+			// vCard 2.1 style bare type: TEL;CELL:…
+			k, v = "TYPE", p
+		}
+		k = strings.ToUpper(k)
+		for _, x := range strings.Split(strings.Trim(v, `"`), ",") {
+			params[k] = append(params[k], strings.ToLower(strings.TrimSpace(x)))
+		}
+	}
+	return group, name, params, value, name != ""
+}
+
+// plainTypes say how a number or address is delivered, not whose it is,
+// so they do not make a label.
+var plainTypes = map[string]bool{"voice": true, "internet": true, "pref": true, "x400": true, "msg": true, "uri": true}
+
+func entry(value string, params map[string][]string) Entry {
+	e := Entry{Value: strings.TrimSpace(value), Pref: len(params["PREF"]) > 0}
+	for _, t := range params["TYPE"] {
+		switch {
+		case t == "pref":
+			e.Pref = true
+		case e.Label == "" && !plainTypes[t]:
+			e.Label = t
+		}
+	}
+	if e.Label == "mobile" {
+		e.Label = "cell"
+	}
+	return e
+}
+
+// appleLabel turns Apple's built-in label spelling "_$!<Mobile>!$_" into
+// "mobile"; custom labels pass through.
+func appleLabel(s string) string {
+	if inner, ok := strings.CutPrefix(s, "_$!<"); ok {
+		return strings.ToLower(strings.TrimSuffix(inner, ">!$_"))
+	}
+	return s
+}
+
+func parseRev(s string) time.Time {
+	for _, layout := range []string{"20060102T150405Z", "2006-01-02T15:04:05Z", time.RFC3339, "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// unescape undoes vCard text escaping: \n, \, \; and \\.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitEscaped splits a structured value on sep, ignoring escaped
+// separators, and unescapes each part.
+func splitEscaped(s string, sep byte) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			out = append(out, unescape(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(out, unescape(s[start:]))
+}
+
+// Write writes c as one vCard of version "3.0" or "4.0".
+func Write(w io.Writer, c Card, version string) error {
+	if version != "3.0" && version != "4.0" {
+		return fmt.Errorf("unsupported vCard version %q (use 3.0 or 4.0)", version)
+	}
+	v4 := version == "4.0"
+	lw := contentline.NewWriter(w)
+
+	lw.Line("BEGIN:VCARD")
+	lw.Line("VERSION:" + version)
+	lw.Line("PRODID:-//PipelinePal//EN")
+	if c.UID != "" {
+		lw.Line("UID:" + c.UID)
+	}
+	lw.Line("FN:" + contentline.Escape(c.Name))
+	lw.Line("N:" + contentline.Escape(c.Family) + ";" + contentline.Escape(c.Given) + ";;;")
+
+	item := 0
+	write := func(prop, value string, e Entry, std map[string]bool) {
+		label := strings.ToLower(e.Label)
+		if label == "mobile" {
+			label = "cell"
+		}
+		var params []string
+		var types []string
+		if prop == "EMAIL" && !v4 {
+			types = append(types, "INTERNET")
+		}
+		custom := label != "" && !std[label]
+		if label != "" && !custom {
+			types = append(types, label)
+		}
+		if e.Pref && !v4 {
+			types = append(types, "pref")
+		}
+		if v4 {
+			if prop == "TEL" {
+				params = append(params, "VALUE=uri")
+				value = "tel:" + value
+			}
+			if len(types) > 0 {
+				params = append(params, "TYPE="+strings.Join(types, ","))
+			}
+			if e.Pref {
+				params = append(params, "PREF=1")
+			}
+		} else if len(types) > 0 {
+			params = append(params, "TYPE="+strings.ToUpper(strings.Join(types, ",")))
+		}
+
+		name := prop
+		if custom {
+			item++
+			name = fmt.Sprintf("item%d.%s", item, prop)
+		}
+		lw.Line(strings.Join(append([]string{name}, params...), ";") + ":" + value)
+		if custom {
+			lw.Line(fmt.Sprintf("item%d.X-ABLabel:%s", item, contentline.Escape(e.Label)))
+		}
+	}
+	for _, p := range c.Phones {
+		write("TEL", p.Value, p, telTypes)
+	}
+	for _, e := range c.Emails {
+		write("EMAIL", e.Value, e, emailTypes)
+	}
+
+	if len(c.Categories) > 0 {
+		cats := make([]string, len(c.Categories))
+		for i, cat := range c.Categories {
+			cats[i] = contentline.Escape(cat)
+		}
+		lw.Line("CATEGORIES:" + strings.Join(cats, ","))
+	}
+	if c.Note != "" {
+		lw.Line("NOTE:" + contentline.Escape(c.Note))
+	}
+	for _, k := range []string{"type", "status", "source"} {
+		if v := c.Ext[k]; v != "" {
+			lw.Line(extPrefix + strings.ToUpper(k) + ":" + contentline.Escape(v))
+		}
+	}
+	if !c.Rev.IsZero() {
+		lw.Line("REV:" + c.Rev.UTC().Format("20060102T150405Z"))
+	}
+	lw.Line("END:VCARD")
+	return lw.Err()
+}
+
+// telTypes and emailTypes are the labels vCard has TYPE values for; others
+// are written as an Apple-style X-ABLabel, which most clients read.
+var (
+	telTypes   = map[string]bool{"cell": true, "home": true, "work": true, "fax": true, "pager": true, "text": true, "main": true, "other": true}
+	emailTypes = map[string]bool{"home": true, "work": true, "other": true}
+)